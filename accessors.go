@@ -0,0 +1,118 @@
+package weightedrand
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Choices returns a copy of the choices held by the Chooser, in their original
+// order. Modifying it does not affect the Chooser.
+func (chs ChooserT[T]) Choices() []ChoiceT[T] {
+	return chs.original()
+}
+
+// Items returns the items held by the Chooser, in their original order.
+func (chs ChooserT[T]) Items() []T {
+	items := make([]T, len(chs.data))
+	for i, c := range chs.data {
+		items[chs.index(i)] = c.Item
+	}
+	return items
+}
+
+// Weights returns the weights of the choices held by the Chooser, in their
+// original order.
+func (chs ChooserT[T]) Weights() []uint {
+	weights := make([]uint, len(chs.data))
+	for i, c := range chs.data {
+		weights[chs.index(i)] = c.Weight
+	}
+	return weights
+}
+
+// TotalWeight returns the sum of the weights of all choices held by the
+// Chooser. If they overflowed the Chooser, the true sum is returned, or
+// math.MaxUint64 if even that overflows.
+func (chs ChooserT[T]) TotalWeight() uint64 {
+	if chs.err != ErrWeightOverflow {
+		return chs.max
+	}
+	var total uint64
+	for _, c := range chs.data {
+		var carry uint64
+		if total, carry = bits.Add64(total, uint64(c.Weight), 0); carry != 0 {
+			return math.MaxUint64
+		}
+	}
+	return total
+}
+
+// MaxWeight returns the largest weight of any choice held by the Chooser, or 0
+// if it holds none.
+func (chs ChooserT[T]) MaxWeight() uint {
+	if len(chs.data) == 0 {
+		return 0
+	}
+	if chs.indices != nil {
+		return chs.data[len(chs.data)-1].Weight
+	}
+	max := chs.data[0].Weight
+	for _, c := range chs.data[1:] {
+		if c.Weight > max {
+			max = c.Weight
+		}
+	}
+	return max
+}
+
+// MinWeight returns the smallest weight of any choice held by the Chooser,
+// including zero weights, or 0 if it holds none.
+func (chs ChooserT[T]) MinWeight() uint {
+	if len(chs.data) == 0 {
+		return 0
+	}
+	if chs.indices != nil {
+		return chs.data[0].Weight
+	}
+	min := chs.data[0].Weight
+	for _, c := range chs.data[1:] {
+		if c.Weight < min {
+			min = c.Weight
+		}
+	}
+	return min
+}
+
+// Probability returns the probability that Pick selects item, and whether the
+// Chooser holds it at all. If several choices hold equal items, their weights
+// are summed. Items are matched by ==, so this panics if T is an interface type
+// holding an item that is not comparable.
+func (chs ChooserT[T]) Probability(item T) (float64, bool) {
+	var weight uint64
+	found := false
+	for _, c := range chs.data {
+		if equalItems(c.Item, item) {
+			weight += uint64(c.Weight)
+			found = true
+		}
+	}
+	if !found || chs.max == 0 {
+		return 0, found
+	}
+	return float64(weight) / float64(chs.max), true
+}
+
+// Probabilities returns the probability of Pick selecting each distinct item
+// held by the Chooser, summing the weights of equal items. It panics if any
+// item is not comparable.
+func (chs ChooserT[T]) Probabilities() map[interface{}]float64 {
+	probs := make(map[interface{}]float64, len(chs.data))
+	for _, c := range chs.data {
+		p := 0.0
+		if chs.max > 0 {
+			p = float64(c.Weight) / float64(chs.max)
+		}
+		probs[c.Item] += p
+	}
+	return probs
+}
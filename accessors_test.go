@@ -0,0 +1,198 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestAccessors(t *testing.T) {
+	choices := []Choice{
+		{Item: "c", Weight: 3},
+		{Item: "a", Weight: 1},
+		{Item: "zero", Weight: 0},
+		{Item: "b", Weight: 2},
+	}
+	chooser, err := NewChooserOpts(choices, WithInputCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := chooser.Choices()
+	if !reflect.DeepEqual(got, choices) {
+		t.Errorf("Choices() = %v, want %v", got, choices)
+	}
+	if items, want := chooser.Items(), []interface{}{"c", "a", "zero", "b"}; !reflect.DeepEqual(items, want) {
+		t.Errorf("Items() = %v, want %v", items, want)
+	}
+	weights := chooser.Weights()
+	if want := []uint{3, 1, 0, 2}; !reflect.DeepEqual(weights, want) {
+		t.Errorf("Weights() = %v, want %v", weights, want)
+	}
+	if total := chooser.TotalWeight(); total != 6 {
+		t.Errorf("TotalWeight() = %d, want 6", total)
+	}
+
+	// The returned slices are detached from the Chooser.
+	got[2].Weight = 1000
+	weights[2] = 1000
+	chooser.Items()[2] = "mutated"
+	for i := 0; i < 1000; i++ {
+		if item := chooser.MustPick(); item == "zero" || item == "mutated" {
+			t.Fatalf("picked %v after mutating returned slices", item)
+		}
+	}
+	if total := chooser.TotalWeight(); total != 6 {
+		t.Errorf("TotalWeight() = %d after mutating returned slices, want 6", total)
+	}
+}
+
+func TestTotalWeightLarge(t *testing.T) {
+	big := uint(maxInt)
+	chooser := NewChooser(Choice{Weight: big}, Choice{Weight: big}, Choice{Weight: 1})
+	if want := 2*uint64(big) + 1; chooser.TotalWeight() != want {
+		t.Errorf("TotalWeight() = %d, want %d", chooser.TotalWeight(), want)
+	}
+	if got := NewChooser().TotalWeight(); got != 0 {
+		t.Errorf("TotalWeight() = %d for empty chooser, want 0", got)
+	}
+	if !is64Bit {
+		return
+	}
+	chooser = NewChooser(Choice{Weight: ^uint(0)}, Choice{Weight: ^uint(0)})
+	if got := chooser.TotalWeight(); got != math.MaxUint64 {
+		t.Errorf("TotalWeight() = %d, want saturated %d", got, uint64(math.MaxUint64))
+	}
+}
+
+func TestMinMaxWeight(t *testing.T) {
+	cases := []struct {
+		name     string
+		chooser  Chooser
+		min, max uint
+		total    uint64
+	}{
+		{"empty", NewChooser(), 0, 0, 0},
+		{"single", NewChooser(Choice{Item: "a", Weight: 7}), 7, 7, 7},
+		{"sorted", NewChooser(
+			Choice{Item: "a", Weight: 5},
+			Choice{Item: "b", Weight: 0},
+			Choice{Item: "c", Weight: 9},
+		), 0, 9, 14},
+		{"unsorted", mustChooser(NewChooserOpts([]Choice{
+			{Item: "a", Weight: 5},
+			{Item: "b", Weight: 2},
+			{Item: "c", Weight: 9},
+		}, WithoutSort())), 2, 9, 16},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.chooser.MinWeight(); got != tc.min {
+				t.Errorf("MinWeight() = %d, want %d", got, tc.min)
+			}
+			if got := tc.chooser.MaxWeight(); got != tc.max {
+				t.Errorf("MaxWeight() = %d, want %d", got, tc.max)
+			}
+			if got := tc.chooser.TotalWeight(); got != tc.total {
+				t.Errorf("TotalWeight() = %d, want %d", got, tc.total)
+			}
+		})
+	}
+}
+
+func TestTotalWeightAfterMutation(t *testing.T) {
+	chooser := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2})
+	chooser.Add(Choice{Item: "c", Weight: 10})
+	if got := chooser.TotalWeight(); got != 13 {
+		t.Errorf("TotalWeight() = %d after Add, want 13", got)
+	}
+	if !chooser.SetWeight("a", 4) {
+		t.Fatal("SetWeight() did not find a")
+	}
+	if got := chooser.TotalWeight(); got != 16 {
+		t.Errorf("TotalWeight() = %d after SetWeight, want 16", got)
+	}
+	if got := chooser.MaxWeight(); got != 10 {
+		t.Errorf("MaxWeight() = %d after SetWeight, want 10", got)
+	}
+	if !chooser.Remove("c") {
+		t.Fatal("Remove() did not find c")
+	}
+	if got := chooser.TotalWeight(); got != 6 {
+		t.Errorf("TotalWeight() = %d after Remove, want 6", got)
+	}
+	if got := chooser.MaxWeight(); got != 4 {
+		t.Errorf("MaxWeight() = %d after Remove, want 4", got)
+	}
+	if got := chooser.MinWeight(); got != 2 {
+		t.Errorf("MinWeight() = %d after Remove, want 2", got)
+	}
+}
+
+// mustChooser returns chs, panicking if err is not nil.
+func mustChooser(chs Chooser, err error) Chooser {
+	if err != nil {
+		panic(err)
+	}
+	return chs
+}
+
+// is64Bit reports whether uint is 64 bits wide on this platform.
+const is64Bit = ^uint(0)>>63 == 1
+
+func TestProbability(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 3},
+		Choice{Item: "a", Weight: 2}, // duplicate, summed
+		Choice{Item: "zero", Weight: 0},
+	)
+	cases := []struct {
+		item  interface{}
+		prob  float64
+		found bool
+	}{
+		{"a", 0.5, true},
+		{"b", 0.5, true},
+		{"zero", 0, true},
+		{"missing", 0, false},
+	}
+	for _, tc := range cases {
+		if p, ok := chooser.Probability(tc.item); p != tc.prob || ok != tc.found {
+			t.Errorf("Probability(%v) = %v, %v; want %v, %v", tc.item, p, ok, tc.prob, tc.found)
+		}
+	}
+
+	probs := chooser.Probabilities()
+	if want := map[interface{}]float64{"a": 0.5, "b": 0.5, "zero": 0}; !reflect.DeepEqual(probs, want) {
+		t.Errorf("Probabilities() = %v, want %v", probs, want)
+	}
+
+	// Empirical frequencies should match.
+	const n = 100000
+	counts := make(map[interface{}]int)
+	for i := 0; i < n; i++ {
+		counts[chooser.MustPick()]++
+	}
+	for item, p := range probs {
+		if got := float64(counts[item]) / n; math.Abs(got-p) > 0.01 {
+			t.Errorf("%v picked %.3f of the time, want %.3f", item, got, p)
+		}
+	}
+}
+
+func TestProbabilitiesSum(t *testing.T) {
+	choices := make([]Choice, 1000)
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(rand.Intn(1 << 20))}
+	}
+	choices[0].Weight = uint(maxInt) / 2 // make the total huge
+	sum := 0.0
+	for _, p := range NewChooser(choices...).Probabilities() {
+		sum += p
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("probabilities sum to %v, want 1", sum)
+	}
+}
@@ -0,0 +1,108 @@
+package weightedrand
+
+import (
+	"math/rand"
+)
+
+// An AliasChooserT holds many possible ChoiceT[T] in the probability and alias
+// tables of Walker's alias method, so that each pick takes constant time
+// regardless of the number of choices, at the cost of two random draws.
+//
+// Construction is slower and uses more memory than a ChooserT, so prefer it
+// only for very large choice sets where the binary search dominates Pick.
+type AliasChooserT[T any] struct {
+	data  []ChoiceT[T]
+	prob  []float64
+	alias []int
+	err   error // ErrNoChoices or ErrAllZeroWeights if nothing can be picked
+}
+
+// An AliasChooser is an AliasChooserT over untyped items.
+type AliasChooser = AliasChooserT[interface{}]
+
+// NewAliasChooserT initializes a new AliasChooserT consisting of the possible
+// ChoiceT[T], building its tables with Vose's algorithm in O(n) time. The
+// AliasChooserT holds its own copy of the choices, so later changes to cs do
+// not affect it.
+func NewAliasChooserT[T any](cs ...ChoiceT[T]) AliasChooserT[T] {
+	cs = append([]ChoiceT[T](nil), cs...)
+	n := len(cs)
+	total := 0.0
+	for _, c := range cs {
+		total += float64(c.Weight)
+	}
+	switch {
+	case n == 0:
+		return AliasChooserT[T]{data: cs, err: ErrNoChoices}
+	case total == 0:
+		return AliasChooserT[T]{data: cs, err: ErrAllZeroWeights}
+	}
+
+	prob := make([]float64, n)
+	alias := make([]int, n)
+	scaled := make([]float64, n)
+	small := make([]int, 0, n)
+	large := make([]int, 0, n)
+	for i, c := range cs {
+		scaled[i] = float64(c.Weight) * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		prob[s], alias[s] = scaled[s], l
+		scaled[l] = (scaled[l] + scaled[s]) - 1
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// Whatever remains is within floating point error of 1, unless rounding
+	// has left a choice of zero weight behind, which must always give way to
+	// its alias.
+	for _, i := range append(large, small...) {
+		if cs[i].Weight > 0 {
+			prob[i] = 1
+		} else {
+			prob[i], alias[i] = 0, heaviest(cs)
+		}
+	}
+
+	return AliasChooserT[T]{data: cs, prob: prob, alias: alias}
+}
+
+// NewAliasChooser initializes a new AliasChooser consisting of the possible
+// Choices.
+func NewAliasChooser(cs ...Choice) AliasChooser {
+	return NewAliasChooserT(cs...)
+}
+
+// Pick returns a single weighted random Choice.Item from the AliasChooser.
+func (chs AliasChooserT[T]) Pick() (T, error) {
+	return chs.PickSource(nil)
+}
+
+// PickSource returns a single weighted random Choice.Item from the
+// AliasChooser, drawing from rs. A nil rs falls back to the global source in
+// math/rand.
+func (chs AliasChooserT[T]) PickSource(rs *rand.Rand) (T, error) {
+	if chs.err != nil {
+		var zero T
+		return zero, chs.err
+	}
+	src := fromRand(rs)
+	i := intn(src, len(chs.data))
+	if float64n(src) >= chs.prob[i] {
+		i = chs.alias[i]
+	}
+	return chs.data[i].Item, nil
+}
+
+// Len returns the number of choices held by the AliasChooser.
+func (chs AliasChooserT[T]) Len() int {
+	return len(chs.data)
+}
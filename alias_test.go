@@ -0,0 +1,123 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestAliasChooser(t *testing.T) {
+	choices := make([]Choice, 0, 10)
+	total := 0
+	for _, w := range rand.Perm(10) {
+		choices = append(choices, Choice{Item: w, Weight: uint(w)})
+		total += w
+	}
+	chooser := NewAliasChooser(choices...)
+	if chooser.Len() != 10 {
+		t.Errorf("Len() = %d, want 10", chooser.Len())
+	}
+
+	const n = 200000
+	rs := rand.New(rand.NewSource(1))
+	counts := make([]int, 10)
+	for i := 0; i < n; i++ {
+		item, err := chooser.PickSource(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[item.(int)]++
+	}
+	if counts[0] != 0 {
+		t.Errorf("weight 0 picked %d times", counts[0])
+	}
+	for w, c := range counts {
+		want := float64(w) / float64(total)
+		if got := float64(c) / n; got < want-0.005 || got > want+0.005 {
+			t.Errorf("weight %d picked %.4f of the time, want %.4f", w, got, want)
+		}
+	}
+
+	if _, err := NewAliasChooser().Pick(); err == nil {
+		t.Error("expected error picking from empty chooser")
+	}
+	if _, err := NewAliasChooser(Choice{Item: 1, Weight: 0}).Pick(); err == nil {
+		t.Error("expected error picking from all zero weight chooser")
+	}
+	if item, err := NewAliasChooserT(ChoiceT[string]{Item: "only", Weight: 3}).Pick(); err != nil || item != "only" {
+		t.Errorf("Pick() = %v, %v; want only", item, err)
+	}
+}
+
+func TestAliasChooserZeroWeights(t *testing.T) {
+	// The weights sum to 7, which does not divide the 11 columns evenly.
+	weights := []uint{0, 1, 0, 2, 0, 0, 3, 0, 1, 0, 0}
+	choices := make([]Choice, len(weights))
+	for i, w := range weights {
+		choices[i] = Choice{Item: i, Weight: w}
+	}
+	chooser := NewAliasChooser(choices...)
+	for i, w := range weights {
+		if w > 0 {
+			continue
+		}
+		if p, a := chooser.prob[i], chooser.alias[i]; p != 0 || weights[a] == 0 {
+			t.Errorf("column %d of zero weight has prob %v and alias %d, want 0 and a choice of nonzero weight", i, p, a)
+		}
+	}
+	rs := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		item, err := chooser.PickSource(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if weights[item.(int)] == 0 {
+			t.Fatalf("picked %v of zero weight", item)
+		}
+	}
+}
+
+func TestAliasChooserCopiesInput(t *testing.T) {
+	choices := []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 0}}
+	chooser := NewAliasChooser(choices...)
+	choices[0] = Choice{Item: "changed", Weight: 0}
+	choices[1].Weight = 100
+	rs := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if item, err := chooser.PickSource(rs); err != nil || item != "a" {
+			t.Fatalf("PickSource() = %v, %v after the input changed; want a", item, err)
+		}
+	}
+}
+
+func BenchmarkAliasPick(b *testing.B) {
+	for n := 1000; n <= 10000000; n *= 100 {
+		choices := mockChoices(n)
+		b.Run("binary/"+strconv.Itoa(n), func(b *testing.B) {
+			chooser := NewChooser(choices...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				chooser.Pick()
+			}
+		})
+		b.Run("alias/"+strconv.Itoa(n), func(b *testing.B) {
+			chooser := NewAliasChooser(choices...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				chooser.Pick()
+			}
+		})
+	}
+}
+
+func BenchmarkNewAliasChooser(b *testing.B) {
+	for n := 1000; n <= 10000000; n *= 100 {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			choices := mockChoices(n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = NewAliasChooser(choices...)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package weightedrand
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// defaultAuditTolerance is the largest deviation of an observed frequency
+// from its expected probability that an AuditReport passes by default.
+const defaultAuditTolerance = 0.01
+
+// An AuditOption configures an AuditT built by NewAuditT.
+type AuditOption func(*auditOptions)
+
+// auditOptions holds the configuration assembled from a list of
+// AuditOptions.
+type auditOptions struct {
+	tolerance float64
+}
+
+// AuditTolerance sets the largest absolute deviation, as a fraction of all
+// picks, of any choice's observed frequency from its expected probability for
+// an AuditReport to pass. The default is 0.01, one percentage point.
+func AuditTolerance(t float64) AuditOption {
+	return func(o *auditOptions) {
+		o.tolerance = t
+	}
+}
+
+// An AuditT wraps a ChooserT, counting the outcome of every pick made through
+// it, so that Report can show how closely the picks have followed the
+// weights: for instance to prove to operations that a weighted traffic split
+// is behaving. Counting is a single atomic add per pick, so an AuditT is safe
+// for concurrent use whenever its ChooserT is.
+type AuditT[T any] struct {
+	chs       ChooserT[T]
+	counts    *pickStats
+	tolerance float64
+}
+
+// An Audit is an AuditT over untyped items.
+type Audit = AuditT[interface{}]
+
+// NewAuditT returns an AuditT picking from chs, configured by opts. Mutating
+// chs afterwards does not affect the AuditT, which holds its own copy.
+func NewAuditT[T any](chs ChooserT[T], opts ...AuditOption) *AuditT[T] {
+	o := auditOptions{tolerance: defaultAuditTolerance}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &AuditT[T]{chs: chs, counts: newPickStats(len(chs.data)), tolerance: o.tolerance}
+}
+
+// NewAudit returns an Audit picking from chs, configured by opts. See
+// NewAuditT.
+func NewAudit(chs Chooser, opts ...AuditOption) *Audit {
+	return NewAuditT(chs, opts...)
+}
+
+// Pick returns a single weighted random Choice.Item from the wrapped Chooser,
+// counting it for Report. An item returned by WithFallback is not counted.
+func (a *AuditT[T]) Pick() (T, error) {
+	if err := a.chs.Err(); err != nil {
+		return a.chs.pickFallback(err)
+	}
+	i := a.chs.pick(a.chs.rng)
+	a.chs.observe(i)
+	a.counts.record(i)
+	return a.chs.data[i].Item, nil
+}
+
+// Reset sets every count back to zero, starting a new audit period. Picks
+// made concurrently may or may not be counted.
+func (a *AuditT[T]) Reset() {
+	a.counts.reset()
+}
+
+// An AuditReport compares the picks counted by an Audit with the weights of
+// its Chooser.
+type AuditReport struct {
+	Samples   uint64      // picks counted
+	Tolerance float64     // largest absolute deviation that passes
+	Pass      bool        // whether there were picks and every choice passed
+	Items     []AuditItem // one per choice, in original order
+}
+
+// An AuditItem is one choice's line in an AuditReport.
+type AuditItem struct {
+	Index    int         // original position of the choice
+	Item     interface{} // the choice's item
+	Weight   uint
+	Count    uint64  // picks of the choice
+	Expected float64 // probability of the choice, from its weight
+	Observed float64 // fraction of the picks that were of the choice
+
+	// AbsDeviation is the absolute difference between Observed and
+	// Expected, and RelDeviation that difference as a fraction of Expected:
+	// zero if both are zero, and +Inf if only Expected is.
+	AbsDeviation float64
+	RelDeviation float64
+
+	Pass bool // whether AbsDeviation is within the tolerance
+}
+
+// Report returns an AuditReport of the picks counted so far. Each choice
+// passes if its observed frequency deviates from its expected probability by
+// no more than the tolerance; the report passes if there has been at least
+// one pick and every choice passes. How large a sample that needs depends on
+// the tolerance: for a probability p, the observed frequency over n picks has
+// a standard deviation of sqrt(p(1-p)/n).
+func (a *AuditT[T]) Report() AuditReport {
+	counts := make([]uint64, len(a.chs.data))
+	var samples uint64
+	for i := range counts {
+		counts[i] = a.counts.load(i)
+		samples += counts[i]
+	}
+	r := AuditReport{
+		Samples:   samples,
+		Tolerance: a.tolerance,
+		Pass:      samples > 0,
+		Items:     make([]AuditItem, len(a.chs.data)),
+	}
+	total := a.chs.TotalWeight()
+	for i, c := range a.chs.data {
+		item := AuditItem{Index: a.chs.index(i), Item: c.Item, Weight: c.Weight, Count: counts[i]}
+		if total > 0 {
+			item.Expected = float64(c.Weight) / float64(total)
+		}
+		if samples > 0 {
+			item.Observed = float64(counts[i]) / float64(samples)
+		}
+		item.AbsDeviation = math.Abs(item.Observed - item.Expected)
+		switch {
+		case item.Expected > 0:
+			item.RelDeviation = item.AbsDeviation / item.Expected
+		case item.Observed > 0:
+			item.RelDeviation = math.Inf(1)
+		}
+		item.Pass = item.AbsDeviation <= a.tolerance
+		r.Pass = r.Pass && item.Pass
+		r.Items[item.Index] = item
+	}
+	return r
+}
+
+// jsonAuditItem is the JSON representation of an AuditItem, with an
+// undefined relative deviation encoded as null.
+type jsonAuditItem struct {
+	Index        int         `json:"index"`
+	Item         interface{} `json:"item"`
+	Weight       uint        `json:"weight"`
+	Count        uint64      `json:"count"`
+	Expected     float64     `json:"expected"`
+	Observed     float64     `json:"observed"`
+	AbsDeviation float64     `json:"abs_deviation"`
+	RelDeviation *float64    `json:"rel_deviation"`
+	Pass         bool        `json:"pass"`
+}
+
+// MarshalJSON encodes r as a JSON object with "samples", "tolerance", "pass"
+// and "items" fields, each item an object with fields named for those of
+// AuditItem in snake case. An infinite relative deviation is encoded as null,
+// which JSON can represent.
+func (r AuditReport) MarshalJSON() ([]byte, error) {
+	items := make([]jsonAuditItem, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = jsonAuditItem{
+			Index:        item.Index,
+			Item:         item.Item,
+			Weight:       item.Weight,
+			Count:        item.Count,
+			Expected:     item.Expected,
+			Observed:     item.Observed,
+			AbsDeviation: item.AbsDeviation,
+			Pass:         item.Pass,
+		}
+		if !math.IsInf(item.RelDeviation, 0) {
+			rel := item.RelDeviation
+			items[i].RelDeviation = &rel
+		}
+	}
+	return json.Marshal(struct {
+		Samples   uint64          `json:"samples"`
+		Tolerance float64         `json:"tolerance"`
+		Pass      bool            `json:"pass"`
+		Items     []jsonAuditItem `json:"items"`
+	}{r.Samples, r.Tolerance, r.Pass, items})
+}
@@ -0,0 +1,171 @@
+package weightedrand
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestAuditReport(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 3},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "c", Weight: 6},
+	)
+	audit := NewAudit(chooser)
+	if r := audit.Report(); r.Samples != 0 || r.Pass {
+		t.Errorf("Report() before any picks = %+v, want no samples and a failure", r)
+	}
+
+	picked := 0
+	for _, n := range []int{10, 1000, 100000} {
+		for ; picked < n; picked++ {
+			if _, err := audit.Pick(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r := audit.Report()
+		if r.Samples != uint64(n) || r.Tolerance != 0.01 || len(r.Items) != 4 {
+			t.Fatalf("Report() after %d picks = %+v", n, r)
+		}
+		var count uint64
+		for i, item := range r.Items {
+			if item.Index != i || item.Item != chooser.Choices()[i].Item {
+				t.Errorf("Items[%d] = %+v, out of original order", i, item)
+			}
+			if want := float64(item.Weight) / 10; math.Abs(item.Expected-want) > 1e-12 {
+				t.Errorf("Items[%d].Expected = %v, want %v", i, item.Expected, want)
+			}
+			if want := float64(item.Count) / float64(n); item.Observed != want {
+				t.Errorf("Items[%d].Observed = %v, want %v", i, item.Observed, want)
+			}
+			if want := math.Abs(item.Observed - item.Expected); item.AbsDeviation != want {
+				t.Errorf("Items[%d].AbsDeviation = %v, want %v", i, item.AbsDeviation, want)
+			}
+			if item.Pass != (item.AbsDeviation <= r.Tolerance) {
+				t.Errorf("Items[%d].Pass = %v with deviation %v", i, item.Pass, item.AbsDeviation)
+			}
+			count += item.Count
+		}
+		if count != r.Samples {
+			t.Errorf("counts sum to %d, want %d", count, r.Samples)
+		}
+		if zero := r.Items[2]; zero.Count != 0 || zero.RelDeviation != 0 || !zero.Pass {
+			t.Errorf("zero weight item = %+v, want never picked", zero)
+		}
+		if n == 100000 && !r.Pass {
+			t.Errorf("Report() after %d picks failed: %+v", n, r)
+		}
+	}
+
+	audit.Reset()
+	if r := audit.Report(); r.Samples != 0 || r.Pass {
+		t.Errorf("Report() after Reset = %+v", r)
+	}
+
+	typed := NewAuditT(NewChooserT(ChoiceT[int]{Item: 7, Weight: 1}))
+	if item, err := typed.Pick(); item != 7 || err != nil {
+		t.Errorf("Pick() = %v, %v; want 7", item, err)
+	}
+	if _, err := NewAudit(NewChooser()).Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func TestAuditTolerance(t *testing.T) {
+	// A scripted source makes exact counts: 3 picks of a and 1 of b against
+	// weights of 1 each, a deviation of 0.25 for both.
+	a, b := "a", "b"
+	choices := []Choice{{Item: a, Weight: 1}, {Item: b, Weight: 1}}
+	chooser := NewChooser(choices...)
+	va, _ := ValueForItem(chooser, a)
+	vb, _ := ValueForItem(chooser, b)
+	cases := []struct {
+		tolerance float64
+		pass      bool
+	}{{0.01, false}, {0.2499, false}, {0.25, true}, {0.5, true}}
+	for _, tc := range cases {
+		scripted, err := NewChooserOpts(choices, WithSource(NewScriptedSource(va, va, va, vb)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		audit := NewAudit(scripted, AuditTolerance(tc.tolerance))
+		for i := 0; i < 4; i++ {
+			audit.Pick()
+		}
+		r := audit.Report()
+		if r.Pass != tc.pass || r.Items[0].Pass != tc.pass || r.Items[1].Pass != tc.pass {
+			t.Errorf("tolerance %v: Report() = %+v, want pass %v", tc.tolerance, r, tc.pass)
+		}
+		if r.Items[0].AbsDeviation != 0.25 || r.Items[0].RelDeviation != 0.5 {
+			t.Errorf("tolerance %v: Items[0] = %+v, want deviations 0.25 and 0.5", tc.tolerance, r.Items[0])
+		}
+	}
+}
+
+func TestAuditReportJSON(t *testing.T) {
+	r := AuditReport{
+		Samples:   4,
+		Tolerance: 0.01,
+		Items: []AuditItem{
+			{Index: 0, Item: "a", Weight: 1, Count: 4, Expected: 0.5, Observed: 1, AbsDeviation: 0.5, RelDeviation: 1},
+			{Index: 1, Item: "b", Weight: 1, Expected: 0.5, AbsDeviation: 0.5, RelDeviation: 1},
+			{Index: 2, Item: "off", Count: 0, Pass: true},
+			{Index: 3, Item: "inf", RelDeviation: math.Inf(1)},
+		},
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"samples":   4.0,
+		"tolerance": 0.01,
+		"pass":      false,
+		"items": []interface{}{
+			map[string]interface{}{"index": 0.0, "item": "a", "weight": 1.0, "count": 4.0, "expected": 0.5, "observed": 1.0, "abs_deviation": 0.5, "rel_deviation": 1.0, "pass": false},
+			map[string]interface{}{"index": 1.0, "item": "b", "weight": 1.0, "count": 0.0, "expected": 0.5, "observed": 0.0, "abs_deviation": 0.5, "rel_deviation": 1.0, "pass": false},
+			map[string]interface{}{"index": 2.0, "item": "off", "weight": 0.0, "count": 0.0, "expected": 0.0, "observed": 0.0, "abs_deviation": 0.0, "rel_deviation": 0.0, "pass": true},
+			map[string]interface{}{"index": 3.0, "item": "inf", "weight": 0.0, "count": 0.0, "expected": 0.0, "observed": 0.0, "abs_deviation": 0.0, "rel_deviation": nil, "pass": false},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+}
+
+func TestAuditConcurrent(t *testing.T) {
+	audit := NewAudit(NewChooser(mockFrequencies(10)...))
+	const goroutines, picks = 8, 1000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < picks; i++ {
+				audit.Pick()
+			}
+		}()
+	}
+	wg.Wait()
+	if r := audit.Report(); r.Samples != goroutines*picks {
+		t.Errorf("Report().Samples = %d, want %d", r.Samples, goroutines*picks)
+	}
+}
+
+func BenchmarkAuditPick(b *testing.B) {
+	audit := NewAudit(NewChooser(mockFrequencies(100)...))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			audit.Pick()
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package weightedrand
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// binaryMagic prefixes every binary encoded chooser, followed by a single
+// format version byte.
+const binaryMagic = "WRC"
+
+// binaryVersion is the current binary format version. It must be incremented
+// whenever the layout of binaryChooser changes.
+const binaryVersion = 1
+
+// binaryChooser is the gob encoded body of a binary encoded chooser. Weights
+// are not stored, since they are exactly the differences between consecutive
+// totals.
+type binaryChooser[T any] struct {
+	Items   []T
+	Indices []int
+	Totals  []uint64
+}
+
+// MarshalBinary encodes the Chooser's precomputed sorted choices and totals,
+// so that UnmarshalBinary can restore it without sorting or summing again.
+// Items are encoded with encoding/gob, so interface items must have their
+// concrete types registered with gob.Register. The rand source is not encoded.
+func (chs ChooserT[T]) MarshalBinary() ([]byte, error) {
+	if chs.err != nil {
+		return nil, chs.err
+	}
+	body := binaryChooser[T]{
+		Items:   make([]T, len(chs.data)),
+		Indices: chs.indices,
+		Totals:  make([]uint64, chs.numTotals()),
+	}
+	for i, c := range chs.data {
+		body.Items[i] = c.Item
+		body.Totals[i] = chs.total(i)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(binaryMagic)
+	buf.WriteByte(binaryVersion)
+	if err := gob.NewEncoder(&buf).Encode(body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores a Chooser encoded by MarshalBinary, replacing its
+// choices but keeping its rand source. An error is returned for input that is
+// truncated, corrupted or of an unknown format version.
+func (chs *ChooserT[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return errors.New("error: not a binary encoded chooser")
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return fmt.Errorf("error: unsupported binary chooser version %d", v)
+	}
+	var body binaryChooser[T]
+	if err := gob.NewDecoder(bytes.NewReader(data[len(binaryMagic)+1:])).Decode(&body); err != nil {
+		return fmt.Errorf("error: decoding binary chooser: %w", err)
+	}
+	if len(body.Items) != len(body.Totals) || (body.Indices != nil && len(body.Indices) != len(body.Items)) {
+		return errors.New("error: corrupt binary chooser: mismatched lengths")
+	}
+	if body.Indices != nil {
+		seen := make([]bool, len(body.Indices))
+		for _, i := range body.Indices {
+			if i < 0 || i >= len(seen) || seen[i] {
+				return errors.New("error: corrupt binary chooser: invalid indices")
+			}
+			seen[i] = true
+		}
+	}
+
+	decoded := ChooserT[T]{
+		data:    make([]ChoiceT[T], len(body.Items)),
+		indices: body.Indices,
+		totals:  make([]uint64, len(body.Totals)),
+	}
+	prev := uint64(0)
+	for i, total := range body.Totals {
+		if total < prev || total > maxTotal || total-prev > uint64(^uint(0)) {
+			return errors.New("error: corrupt binary chooser: invalid totals")
+		}
+		decoded.data[i] = ChoiceT[T]{Item: body.Items[i], Weight: uint(total - prev)}
+		decoded.totals[i] = total
+		prev = total
+	}
+	decoded.max = prev
+	decoded.valid = decoded.max > 0
+	if len(decoded.data) > 0 && !decoded.valid {
+		decoded.err = ErrAllZeroWeights
+	}
+	chs.replace(decoded)
+	return nil
+}
@@ -0,0 +1,121 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestChooserBinaryRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 100} {
+		choices := make([]ChoiceT[string], n)
+		for i := range choices {
+			choices[i] = ChoiceT[string]{Item: strconv.Itoa(i), Weight: uint(rand.Intn(10) + 1)}
+		}
+		chooser := NewChooserT(choices...)
+		b, err := chooser.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded ChooserT[string]
+		if err := decoded.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded.Choices(), chooser.Choices()) ||
+			!reflect.DeepEqual(decoded.totals, chooser.totals) ||
+			decoded.max != chooser.max || decoded.Err() != chooser.Err() {
+			t.Errorf("n=%d: round trip gave %+v, want %+v", n, decoded, chooser)
+		}
+	}
+
+	// Interface items of basic types need no registration.
+	chooser := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: 2, Weight: 0})
+	b, err := chooser.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Chooser
+	if err := decoded.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if item, err := decoded.Pick(); err != nil || item != "a" {
+		t.Errorf("Pick() = %v, %v; want a", item, err)
+	}
+}
+
+func TestChooserUnmarshalBinaryErrors(t *testing.T) {
+	good, err := NewChooser(
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 2},
+	).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]byte{
+		"empty":       nil,
+		"bad magic":   append([]byte("XYZ"), good[3:]...),
+		"bad version": append(append([]byte(binaryMagic), 99), good[4:]...),
+		"truncated":   good[:len(good)-3],
+		"header only": good[:4],
+	}
+	for name, data := range cases {
+		var chooser Chooser
+		if err := chooser.UnmarshalBinary(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// Totals that decrease cannot have come from MarshalBinary.
+	bad := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2})
+	bad.totals[0] = 5
+	data, _ := bad.MarshalBinary()
+	var chooser Chooser
+	if err := chooser.UnmarshalBinary(data); err == nil {
+		t.Error("decreasing totals: expected error")
+	}
+
+	if !is64Bit {
+		return // weights cannot overflow int64 with a 32-bit uint
+	}
+	if _, err := NewChooser(Choice{Weight: ^uint(0)}, Choice{Weight: 1}).MarshalBinary(); err != ErrWeightOverflow {
+		t.Errorf("MarshalBinary() error = %v, want %v", err, ErrWeightOverflow)
+	}
+}
+
+// BenchmarkChooserUnmarshalBinary compares restoring a large chooser against
+// building it from scratch. Items are concrete, as gob encoding of interface
+// items is dominated by the per-value type information.
+func BenchmarkChooserUnmarshalBinary(b *testing.B) {
+	const n = 1000000
+	choices := make([]ChoiceT[int], n)
+	for i := range choices {
+		choices[i] = ChoiceT[int]{Item: i, Weight: uint(rand.Intn(1000))}
+	}
+
+	b.Run("rebuild", func(b *testing.B) {
+		cs := make([]ChoiceT[int], n)
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			copy(cs, choices)
+			b.StartTimer()
+			_ = NewChooserT(cs...)
+		}
+	})
+	b.Run("unmarshal", func(b *testing.B) {
+		cs := make([]ChoiceT[int], n)
+		copy(cs, choices)
+		data, err := NewChooserT(cs...).MarshalBinary()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var chooser ChooserT[int]
+			if err := chooser.UnmarshalBinary(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
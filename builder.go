@@ -0,0 +1,61 @@
+package weightedrand
+
+import "errors"
+
+// errFinalized is returned by a BuilderT used after Finalize.
+var errFinalized = errors.New("error: builder already finalized")
+
+// A BuilderT accumulates choices one at a time, as when streaming them from a
+// database cursor whose length is not known up front, and then hands them to a
+// ChooserT without copying, so that peak memory holds the choices only once.
+// Weights are summed as they are added, so an overflow is reported by the Add
+// that causes it rather than at the end.
+//
+// The zero value is an empty builder ready to use. A BuilderT is not safe for
+// concurrent use.
+type BuilderT[T any] struct {
+	choices   []ChoiceT[T]
+	total     uint64
+	finalized bool
+}
+
+// A Builder is a BuilderT over untyped items.
+type Builder = BuilderT[interface{}]
+
+// Add appends a choice of item with weight. ErrWeightOverflow is returned,
+// and the choice left out, if it would take the sum of the weights past
+// math.MaxUint64; an error is also returned after Finalize.
+func (b *BuilderT[T]) Add(item T, weight uint) error {
+	if b.finalized {
+		return errFinalized
+	}
+	if uint64(weight) > maxTotal-b.total {
+		return ErrWeightOverflow
+	}
+	b.total += uint64(weight)
+	b.choices = append(b.choices, ChoiceT[T]{Item: item, Weight: weight})
+	return nil
+}
+
+// Len returns the number of choices added so far.
+func (b *BuilderT[T]) Len() int {
+	return len(b.choices)
+}
+
+// Finalize returns a ChooserT of the choices added, configured by opts as for
+// NewChooserTOpts, which takes over the builder's storage rather than copying
+// it, as with WithBorrowedInput. The builder cannot be used afterwards.
+// ErrNoChoices is returned if no choices were added, and ErrAllZeroWeights if
+// all of them have a weight of zero.
+func (b *BuilderT[T]) Finalize(opts ...Option) (ChooserT[T], error) {
+	if b.finalized {
+		return ChooserT[T]{}, errFinalized
+	}
+	if len(b.choices) == 0 {
+		return ChooserT[T]{}, ErrNoChoices
+	}
+	b.finalized = true
+	cs := b.choices
+	b.choices = nil
+	return NewChooserTOpts(cs, append([]Option{WithBorrowedInput()}, opts...)...)
+}
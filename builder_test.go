@@ -0,0 +1,100 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	var b Builder
+	var choices []Choice
+	for i := 0; i < 100; i++ {
+		w := uint(i % 7) // every seventh weight is zero
+		if err := b.Add(i, w); err != nil {
+			t.Fatal(err)
+		}
+		choices = append(choices, Choice{Item: i, Weight: w})
+		if b.Len() != i+1 {
+			t.Fatalf("Len() = %d, want %d", b.Len(), i+1)
+		}
+	}
+	storage := &b.choices[0]
+	built, err := b.Finalize(WithRand(rand.New(rand.NewSource(1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &built.data[0] != storage {
+		t.Error("Finalize() copied the builder's choices")
+	}
+	conventional := NewChooserWithRand(rand.New(rand.NewSource(1)), choices...)
+	if !reflect.DeepEqual(built.Choices(), conventional.Choices()) {
+		t.Errorf("Choices() = %v, want %v", built.Choices(), conventional.Choices())
+	}
+	for i := 0; i < 10000; i++ {
+		if x, y := built.MustPick(), conventional.MustPick(); x != y {
+			t.Fatalf("pick %d: built picked %v, conventional %v", i, x, y)
+		}
+	}
+
+	if err := b.Add(100, 1); err == nil {
+		t.Error("Add() after Finalize succeeded")
+	}
+	if _, err := b.Finalize(); err == nil {
+		t.Error("Finalize() twice succeeded")
+	}
+}
+
+func TestBuilderTyped(t *testing.T) {
+	var b BuilderT[string]
+	b.Add("zero", 0)
+	b.Add("a", 1)
+	b.Add("b", 3)
+	chooser, err := b.Finalize(WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 20000; i++ {
+		counts[chooser.MustPick()]++
+	}
+	if counts["zero"] != 0 || counts["b"] < 2*counts["a"] || counts["b"] > 4*counts["a"] {
+		t.Errorf("picked %v, want about 1:3 and no zero", counts)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	if _, err := new(Builder).Finalize(); err != ErrNoChoices {
+		t.Errorf("Finalize() error = %v, want %v", err, ErrNoChoices)
+	}
+	var zeros Builder
+	zeros.Add("a", 0)
+	if _, err := zeros.Finalize(); err != ErrAllZeroWeights {
+		t.Errorf("Finalize() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+
+	if !is64Bit {
+		return
+	}
+	var b Builder
+	if err := b.Add("a", ^uint(0)-1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Add("b", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Add("c", 1); err != ErrWeightOverflow {
+		t.Errorf("Add() error = %v, want %v", err, ErrWeightOverflow)
+	}
+	if b.Len() != 2 {
+		t.Errorf("Len() = %d after the overflowing Add, want 2", b.Len())
+	}
+	// The builder stays usable after an overflow was turned away.
+	if err := b.Add("zero", 0); err != nil {
+		t.Errorf("Add() error = %v for a zero weight after an overflow", err)
+	}
+	chooser, err := b.Finalize()
+	if err != nil || chooser.Len() != 3 || chooser.TotalWeight() != uint64(^uint(0)) {
+		t.Errorf("Finalize() = %d choices of %d, %v", chooser.Len(), chooser.TotalWeight(), err)
+	}
+}
@@ -0,0 +1,93 @@
+package weightedrand
+
+import "math/rand"
+
+// A CallbackChooserT picks among a fixed set of items whose weights are live
+// values, such as a queue depth or a remaining budget, that would be stale if
+// read once at construction. Each Pick calls the weight function for every
+// item and makes a single weighted selection over the values it returns, so
+// it takes O(n) time per pick, where a ChooserT takes O(log n); prefer a
+// ChooserT, rebuilt on change, where the weights change less often than they
+// are picked from.
+//
+// A CallbackChooserT is safe for concurrent use if its weight function and
+// its source are, as the global source in math/rand is.
+type CallbackChooserT[T any] struct {
+	items    []T
+	weightFn func(item T) uint
+	rng      source
+}
+
+// A CallbackChooser is a CallbackChooserT over untyped items.
+type CallbackChooser = CallbackChooserT[interface{}]
+
+// NewCallbackChooserT initializes a new CallbackChooserT which picks among
+// items, weighted by weightFn at the time of each pick, drawing from the
+// global source in math/rand.
+func NewCallbackChooserT[T any](items []T, weightFn func(item T) uint) CallbackChooserT[T] {
+	return NewCallbackChooserTWithRand(nil, items, weightFn)
+}
+
+// NewCallbackChooserTWithRand initializes a new CallbackChooserT which picks
+// among items, weighted by weightFn at the time of each pick, drawing its
+// random numbers from r. A nil r falls back to the global source in
+// math/rand.
+func NewCallbackChooserTWithRand[T any](r *rand.Rand, items []T, weightFn func(item T) uint) CallbackChooserT[T] {
+	return CallbackChooserT[T]{
+		items:    append([]T(nil), items...),
+		weightFn: weightFn,
+		rng:      fromRand(r),
+	}
+}
+
+// NewCallbackChooser initializes a new CallbackChooser which picks among
+// items, weighted by weightFn at the time of each pick. See
+// NewCallbackChooserT.
+func NewCallbackChooser(items []interface{}, weightFn func(item interface{}) uint) CallbackChooser {
+	return NewCallbackChooserT(items, weightFn)
+}
+
+// NewCallbackChooserWithRand initializes a new CallbackChooser which picks
+// among items, weighted by weightFn at the time of each pick, drawing its
+// random numbers from r. See NewCallbackChooserTWithRand.
+func NewCallbackChooserWithRand(r *rand.Rand, items []interface{}, weightFn func(item interface{}) uint) CallbackChooser {
+	return NewCallbackChooserTWithRand(r, items, weightFn)
+}
+
+// Pick calls the weight function once for each item, then returns a single
+// item picked at random in proportion to the weights just returned.
+// ErrNoChoices is returned if there are no items, ErrAllZeroWeights if every
+// weight is zero, and ErrWeightOverflow if the weights sum to more than the
+// largest uint64.
+func (cc CallbackChooserT[T]) Pick() (T, error) {
+	var zero T
+	if len(cc.items) == 0 {
+		return zero, ErrNoChoices
+	}
+	weights := make([]uint64, len(cc.items))
+	var total uint64
+	for i, item := range cc.items {
+		w := uint64(cc.weightFn(item))
+		if w > maxTotal-total {
+			return zero, ErrWeightOverflow
+		}
+		weights[i] = w
+		total += w
+	}
+	if total == 0 {
+		return zero, ErrAllZeroWeights
+	}
+	r := uint64n(cc.rng, total)
+	for i, w := range weights {
+		if r < w {
+			return cc.items[i], nil
+		}
+		r -= w
+	}
+	return cc.items[len(cc.items)-1], nil
+}
+
+// Len returns the number of items in the CallbackChooser.
+func (cc CallbackChooserT[T]) Len() int {
+	return len(cc.items)
+}
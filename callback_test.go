@@ -0,0 +1,85 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCallbackChooser(t *testing.T) {
+	// The weights are read from live, which changes between rounds of picks.
+	live := map[interface{}]uint{"a": 1, "b": 3, "c": 0}
+	chooser := NewCallbackChooserWithRand(rand.New(rand.NewSource(1)),
+		[]interface{}{"a", "b", "c"},
+		func(item interface{}) uint { return live[item] },
+	)
+	if chooser.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", chooser.Len())
+	}
+
+	rounds := []map[interface{}]uint{
+		{"a": 1, "b": 3, "c": 0},
+		{"a": 0, "b": 1, "c": 1},
+		{"a": 8, "b": 1, "c": 1},
+	}
+	const n = 20000
+	for _, weights := range rounds {
+		live = weights
+		var total float64
+		for _, w := range weights {
+			total += float64(w)
+		}
+		counts := make(map[interface{}]int)
+		for i := 0; i < n; i++ {
+			item, err := chooser.Pick()
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[item]++
+		}
+		for item, w := range weights {
+			want := float64(w) / total
+			if got := float64(counts[item]) / n; math.Abs(got-want) > 0.02 {
+				t.Errorf("weights %v: picked %v %.3f of the time, want %.3f", weights, item, got, want)
+			}
+		}
+	}
+}
+
+// TestCallbackChooserEveryPick changes the weights on every pick, so that
+// only one item has any weight at a time.
+func TestCallbackChooserEveryPick(t *testing.T) {
+	turn := 0
+	chooser := NewCallbackChooserT([]int{0, 1, 2}, func(item int) uint {
+		if item == turn%3 {
+			return 1
+		}
+		return 0
+	})
+	for turn = 0; turn < 30; turn++ {
+		if item, err := chooser.Pick(); err != nil || item != turn%3 {
+			t.Fatalf("Pick() = %v, %v on turn %d", item, err, turn)
+		}
+	}
+}
+
+func TestCallbackChooserErrors(t *testing.T) {
+	constant := func(w uint) func(interface{}) uint {
+		return func(interface{}) uint { return w }
+	}
+	if _, err := NewCallbackChooser(nil, constant(1)).Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewCallbackChooser([]interface{}{"a", "b"}, constant(0)).Pick(); err != ErrAllZeroWeights {
+		t.Errorf("Pick() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if !is64Bit {
+		return
+	}
+	if _, err := NewCallbackChooser([]interface{}{"a"}, constant(^uint(0))).Pick(); err != nil {
+		t.Errorf("Pick() error = %v for a single MaxUint64 weight", err)
+	}
+	if _, err := NewCallbackChooser([]interface{}{"a", "b"}, constant(^uint(0))).Pick(); err != ErrWeightOverflow {
+		t.Errorf("Pick() error = %v, want %v", err, ErrWeightOverflow)
+	}
+}
@@ -0,0 +1,35 @@
+package weightedrand
+
+// CDF returns a copy of the cumulative totals of the Chooser, in the order it
+// picks in: the i'th is the sum of the weights of the choices up to and
+// including ChoiceAt(i). A Pick draws a raw value r uniformly from
+// [0, TotalWeight()) and returns the item of ChoiceAt(SearchCDF(cdf, r)), so
+// another system holding the CDF, and the same raw values, selects exactly as
+// the Chooser does. It returns nil if Err would return an error.
+func (chs ChooserT[T]) CDF() []uint64 {
+	if chs.Err() != nil {
+		return nil
+	}
+	cdf := make([]uint64, chs.numTotals())
+	for i := range cdf {
+		cdf[i] = chs.total(i)
+	}
+	return cdf
+}
+
+// ChoiceAt returns the i'th choice of the Chooser in the order it picks in,
+// which is that of CDF rather than the original order of Choices. It panics if
+// i is out of range.
+func (chs ChooserT[T]) ChoiceAt(i int) ChoiceT[T] {
+	return chs.data[i]
+}
+
+// SearchCDF returns the index of the choice selected by the raw value r from
+// a CDF, as returned by ChooserT.CDF: the smallest i for which cdf[i] is above
+// r, or len(cdf) if r is not below the last total.
+func SearchCDF(cdf []uint64, r uint64) int {
+	if len(cdf) == 0 || r >= cdf[len(cdf)-1] {
+		return len(cdf)
+	}
+	return searchTotals(cdf, r+1)
+}
@@ -0,0 +1,80 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCDF(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "b", Weight: 5},
+		Choice{Item: "c", Weight: 2},
+	)
+	cdf := chooser.CDF()
+	if want := []uint64{0, 1, 3, 8}; !reflect.DeepEqual(cdf, want) {
+		t.Errorf("CDF() = %v, want %v", cdf, want)
+	}
+	var items []interface{}
+	for i := range cdf {
+		items = append(items, chooser.ChoiceAt(i).Item)
+	}
+	if want := []interface{}{"zero", "a", "c", "b"}; !reflect.DeepEqual(items, want) {
+		t.Errorf("ChoiceAt() items = %v, want %v", items, want)
+	}
+
+	cdf[3] = 0
+	if chooser.CDF()[3] != 8 {
+		t.Error("modifying the CDF modified the Chooser")
+	}
+
+	if cdf := NewChooser().CDF(); cdf != nil {
+		t.Errorf("CDF() = %v for an empty Chooser, want nil", cdf)
+	}
+	if cdf := NewChooser(Choice{Item: "a", Weight: 0}).CDF(); cdf != nil {
+		t.Errorf("CDF() = %v with all zero weights, want nil", cdf)
+	}
+}
+
+func TestSearchCDF(t *testing.T) {
+	cdf := []uint64{5, 7, 8, 8}
+	for r, want := range []int{0, 0, 0, 0, 0, 1, 1, 2, 4, 4} {
+		if got := SearchCDF(cdf, uint64(r)); got != want {
+			t.Errorf("SearchCDF(%d) = %d, want %d", r, got, want)
+		}
+	}
+	if got := SearchCDF(nil, 0); got != 0 {
+		t.Errorf("SearchCDF(nil) = %d, want 0", got)
+	}
+	if got := SearchCDF([]uint64{1, ^uint64(0)}, ^uint64(0)); got != 2 {
+		t.Errorf("SearchCDF(MaxUint64) = %d, want 2", got)
+	}
+}
+
+// TestCDFMatchesPick checks that the exported CDF and SearchCDF select the
+// same item as Pick for the same raw random values, with and without a lookup
+// table.
+func TestCDFMatchesPick(t *testing.T) {
+	rs := rand.New(rand.NewSource(1))
+	choices := make([]Choice, 200)
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(rs.Intn(50))}
+	}
+	for _, opts := range [][]Option{nil, {WithLookupTable(1 << 16)}} {
+		chooser, err := NewChooserOpts(choices, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cdf := chooser.CDF()
+		total := chooser.TotalWeight()
+		for r := uint64(0); r < total; r++ {
+			src := NewScriptedSource(int(r))
+			want := chooser.ChoiceAt(SearchCDF(cdf, r)).Item
+			if got, _ := chooser.PickWith(src); got != want {
+				t.Fatalf("raw value %d: Pick() = %v, CDF gives %v", r, got, want)
+			}
+		}
+	}
+}
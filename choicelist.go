@@ -0,0 +1,104 @@
+package weightedrand
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewChoiceT returns a ChoiceT[T] of item with weight, for use where the
+// field names of a literal would be noise.
+func NewChoiceT[T any](item T, weight uint) ChoiceT[T] {
+	return ChoiceT[T]{Item: item, Weight: weight}
+}
+
+// NewChoice returns a Choice of item with weight. See NewChoiceT.
+func NewChoice(item interface{}, weight uint) Choice {
+	return Choice{Item: item, Weight: weight}
+}
+
+// A ChoiceListT builds up the choices of a ChooserT one call at a time:
+//
+//	chs, err := new(weightedrand.ChoiceList).
+//		Add("a", 3).
+//		Add("b", 1).
+//		AddN([]interface{}{"c", "d"}, 2).
+//		Chooser()
+//
+// Choices are checked only when Chooser is called, which then reports every
+// zero weight and any overflow at once. Chooser leaves the list untouched, so
+// it may be added to and built from again, each ChooserT being independent of
+// the others. The zero value is an empty list ready to use.
+type ChoiceListT[T any] struct {
+	choices []ChoiceT[T]
+}
+
+// A ChoiceList is a ChoiceListT over untyped items.
+type ChoiceList = ChoiceListT[interface{}]
+
+// Add appends a choice of item with weight to the list, and returns the list.
+func (cl *ChoiceListT[T]) Add(item T, weight uint) *ChoiceListT[T] {
+	cl.choices = append(cl.choices, ChoiceT[T]{Item: item, Weight: weight})
+	return cl
+}
+
+// AddN appends a choice of each of items, all with the same weight, to the
+// list, and returns the list.
+func (cl *ChoiceListT[T]) AddN(items []T, weight uint) *ChoiceListT[T] {
+	for _, item := range items {
+		cl.choices = append(cl.choices, ChoiceT[T]{Item: item, Weight: weight})
+	}
+	return cl
+}
+
+// Len returns the number of choices added to the list.
+func (cl *ChoiceListT[T]) Len() int {
+	return len(cl.choices)
+}
+
+// Chooser builds a ChooserT of the choices added so far, configured by opts as
+// for NewChooserTOpts. ErrNoChoices is returned for an empty list. Otherwise,
+// if any choice has a weight of zero or the weights overflow, a
+// *ValidationError listing each of those problems is returned.
+func (cl *ChoiceListT[T]) Chooser(opts ...Option) (ChooserT[T], error) {
+	if len(cl.choices) == 0 {
+		return ChooserT[T]{}, ErrNoChoices
+	}
+	var problems []Problem
+	for _, p := range ValidateChoicesT(cl.choices) {
+		if p.Kind != DuplicateItem {
+			problems = append(problems, p)
+		}
+	}
+	if problems != nil {
+		return ChooserT[T]{}, &ValidationError{Problems: problems}
+	}
+	// The list must survive the build, even with WithBorrowedInput.
+	return NewChooserTOpts(cl.choices, append(opts[:len(opts):len(opts)], WithInputCopy())...)
+}
+
+// A ValidationError lists every Problem found with a set of choices.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
+	}
+	return fmt.Sprintf("error: %d invalid choices: %s", len(e.Problems), strings.Join(msgs, "; "))
+}
+
+// Is reports whether target is ErrWeightOverflow and the weights overflow, so
+// that errors.Is finds it among the problems.
+func (e *ValidationError) Is(target error) bool {
+	if target != ErrWeightOverflow {
+		return false
+	}
+	for _, p := range e.Problems {
+		if p.Kind == WouldOverflow {
+			return true
+		}
+	}
+	return false
+}
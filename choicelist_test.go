@@ -0,0 +1,104 @@
+package weightedrand
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewChoice(t *testing.T) {
+	if got, want := NewChoice("a", 3), (Choice{Item: "a", Weight: 3}); got != want {
+		t.Errorf("NewChoice() = %v, want %v", got, want)
+	}
+	if got, want := NewChoiceT(7, 2), (ChoiceT[int]{Item: 7, Weight: 2}); got != want {
+		t.Errorf("NewChoiceT() = %v, want %v", got, want)
+	}
+}
+
+func TestChoiceList(t *testing.T) {
+	chooser, err := new(ChoiceList).
+		Add("a", 3).
+		Add("b", 1).
+		AddN([]interface{}{"c", "d"}, 2).
+		Chooser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Choice{{Item: "a", Weight: 3}, {Item: "b", Weight: 1}, {Item: "c", Weight: 2}, {Item: "d", Weight: 2}}
+	if got := chooser.Choices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Choices() = %v, want %v", got, want)
+	}
+
+	var typed ChoiceListT[int]
+	typed.AddN([]int{1, 2, 3}, 1)
+	if typed.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", typed.Len())
+	}
+	chs, err := typed.Chooser(WithSeed(1))
+	if err != nil || chs.Len() != 3 {
+		t.Errorf("Chooser() = %v, %v", chs.Choices(), err)
+	}
+}
+
+func TestChoiceListReuse(t *testing.T) {
+	var cl ChoiceList
+	cl.Add("heavy", 100).Add("light", 1)
+	first, err := cl.Chooser(WithBorrowedInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl.Add("extra", 5)
+	second, err := cl.Chooser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Len() != 2 || second.Len() != 3 {
+		t.Errorf("Len() = %d and %d, want 2 and 3", first.Len(), second.Len())
+	}
+	// Neither build reordered the list, and the choosers share nothing.
+	want := []Choice{{Item: "heavy", Weight: 100}, {Item: "light", Weight: 1}, {Item: "extra", Weight: 5}}
+	if !reflect.DeepEqual(cl.choices, want) {
+		t.Errorf("list changed to %v, want %v", cl.choices, want)
+	}
+	second.SetWeight("heavy", 0)
+	if p, _ := first.Probability("heavy"); p < 0.99 {
+		t.Errorf("Probability(heavy) = %v in first chooser after mutating the second", p)
+	}
+}
+
+func TestChoiceListErrors(t *testing.T) {
+	if _, err := new(ChoiceList).Chooser(); err != ErrNoChoices {
+		t.Errorf("Chooser() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	_, err := new(ChoiceList).Add("a", 0).Add("b", 1).Add("a", 2).AddN([]interface{}{"c", "d"}, 0).Chooser()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Chooser() error = %v, want a *ValidationError", err)
+	}
+	want := []Problem{
+		{Index: 0, Kind: ZeroWeight},
+		{Index: 3, Kind: ZeroWeight},
+		{Index: 4, Kind: ZeroWeight},
+	}
+	if !reflect.DeepEqual(verr.Problems, want) {
+		t.Errorf("Problems = %v, want %v", verr.Problems, want)
+	}
+	if want := "error: 3 invalid choices: choice 0 (weight 0): zero weight; choice 3 (weight 0): zero weight; choice 4 (weight 0): zero weight"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err, want)
+	}
+	if errors.Is(err, ErrWeightOverflow) {
+		t.Error("errors.Is(ErrWeightOverflow) = true without overflow")
+	}
+
+	if !is64Bit {
+		return
+	}
+	_, err = new(ChoiceList).Add("a", 0).Add("b", ^uint(0)).Add("c", 1).Chooser()
+	if !errors.As(err, &verr) || len(verr.Problems) != 2 || verr.Problems[1] != (Problem{Index: 2, Weight: 1, Kind: WouldOverflow}) {
+		t.Errorf("Chooser() error = %v, want the zero weight and the overflow", err)
+	}
+	if !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("errors.Is(%v, ErrWeightOverflow) = false", err)
+	}
+}
@@ -0,0 +1,35 @@
+package weightedrand
+
+import "sync/atomic"
+
+// Clone returns a deep copy of the Chooser, sharing no storage with it, so
+// that mutating either leaves the other untouched. Pick statistics are copied
+// as they stand and counted separately from then on.
+//
+// The clone keeps the same rand source rather than a copy of it, since a
+// *rand.Rand cannot be duplicated; their picks then interleave on one
+// sequence, and a source that is not safe for concurrent use must not be
+// picked from by both at once. Use WithRand, or PickSource, to give the clone
+// a source of its own.
+func (chs ChooserT[T]) Clone() ChooserT[T] {
+	c := chs
+	c.data = append([]ChoiceT[T](nil), chs.data...)
+	if chs.indices != nil {
+		c.indices = append([]int(nil), chs.indices...)
+	}
+	if chs.totals != nil {
+		c.totals = append([]uint64(nil), chs.totals...)
+	}
+	if chs.totals32 != nil {
+		c.totals32 = append([]uint32(nil), chs.totals32...)
+	}
+	if chs.stats != nil {
+		c.stats = &pickStats{counts: make([]uint64, len(chs.stats.counts))}
+		for i := range c.stats.counts {
+			c.stats.counts[i] = atomic.LoadUint64(&chs.stats.counts[i])
+		}
+	}
+	c.lookup.idx16 = append([]uint16(nil), chs.lookup.idx16...)
+	c.lookup.idx32 = append([]uint32(nil), chs.lookup.idx32...)
+	return c
+}
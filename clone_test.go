@@ -0,0 +1,67 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	original := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 3},
+	)
+	clone := original.Clone()
+	if clone.rng != original.rng {
+		t.Error("Clone() did not keep the rand source")
+	}
+
+	clone.Add(Choice{Item: "c", Weight: 100})
+	clone.SetWeight("a", 50)
+	clone.Remove("b")
+	clone.data[0].Item = "mutated"
+
+	if original.Len() != 2 {
+		t.Errorf("original Len() = %d after mutating the clone, want 2", original.Len())
+	}
+	if want := []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 3}}; !reflect.DeepEqual(original.Choices(), want) {
+		t.Errorf("original Choices() = %v, want %v", original.Choices(), want)
+	}
+	assertShares(t, pickShares(t, original, 20000), map[interface{}]float64{"a": 0.25, "b": 0.75})
+
+	// A clone left alone picks exactly as its original would.
+	a := NewChooserWithRand(rand.New(rand.NewSource(7)), mockFrequencies(50)...)
+	b := NewChooserWithRand(rand.New(rand.NewSource(7)), mockFrequencies(50)...).Clone()
+	for i := 0; i < 1000; i++ {
+		if x, y := a.MustPick(), b.MustPick(); x != y {
+			t.Fatalf("pick %d: clone picked %v, original %v", i, y, x)
+		}
+	}
+}
+
+func TestCloneDeepCopy(t *testing.T) {
+	original, err := NewChooserOpts(mockFrequencies(10), WithStats(), WithLookupTable(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original.PickN(100)
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone.Stats(), original.Stats()) {
+		t.Errorf("clone Stats() = %v, want %v", clone.Stats(), original.Stats())
+	}
+
+	clone.PickN(100)
+	clone.totals[0] = 1000
+	clone.indices[0] = 1000
+	clone.lookup.idx16[0] = 1000
+	if original.totals[0] == 1000 || original.indices[0] == 1000 || original.lookup.idx16[0] == 1000 {
+		t.Error("Clone() shares storage with the original")
+	}
+	if sumStats(original.Stats()) != 100 || sumStats(clone.Stats()) != 200 {
+		t.Errorf("Stats() totals = %d, %d; want 100, 200", sumStats(original.Stats()), sumStats(clone.Stats()))
+	}
+
+	if empty := NewChooser().Clone(); empty.Err() != ErrNoChoices || empty.Len() != 0 {
+		t.Errorf("empty Clone() = %v, %d", empty.Err(), empty.Len())
+	}
+}
@@ -0,0 +1,54 @@
+package weightedrand
+
+import "math/rand"
+
+// A Coin is a weighted boolean: Flip returns true with probability
+// trueWeight/(trueWeight+falseWeight). It is a Chooser of two choices, and so
+// validates its weights, draws its random numbers and may be used
+// concurrently exactly as one.
+type Coin struct {
+	chs ChooserT[bool]
+}
+
+// NewCoin initializes a new Coin landing true or false in proportion to
+// trueWeight and falseWeight, configured by opts as for NewChooserTOpts. An
+// error is returned if both weights are zero, or for an invalid combination
+// of options.
+func NewCoin(trueWeight, falseWeight uint, opts ...Option) (Coin, error) {
+	chs, err := NewChooserTOpts([]ChoiceT[bool]{
+		{Item: true, Weight: trueWeight},
+		{Item: false, Weight: falseWeight},
+	}, opts...)
+	if err != nil {
+		return Coin{}, err
+	}
+	return Coin{chs: chs}, nil
+}
+
+// Flip returns a weighted random boolean drawn from the Coin's own source.
+func (c Coin) Flip() bool {
+	return c.chs.MustPick()
+}
+
+// FlipSource returns a weighted random boolean drawn from r rather than the
+// Coin's own source. A nil r falls back to the global source in math/rand.
+func (c Coin) FlipSource(r *rand.Rand) bool {
+	v, err := c.chs.PickSource(r)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FlipSecure returns a weighted random boolean drawn from crypto/rand, as by
+// PickSecure. An error is returned only if reading from the secure source
+// fails.
+func (c Coin) FlipSecure() (bool, error) {
+	return c.chs.PickSecure()
+}
+
+// Probability returns the probability that Flip returns true.
+func (c Coin) Probability() float64 {
+	p, _ := c.chs.Probability(true)
+	return p
+}
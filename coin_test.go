@@ -0,0 +1,77 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCoin(t *testing.T) {
+	cases := []struct {
+		trueWeight, falseWeight uint
+	}{
+		{1, 1},
+		{1, 3},
+		{9, 1},
+		{1, 0},
+		{0, 1},
+		{1, math.MaxUint32},
+		{math.MaxUint32, 1},
+	}
+	for _, tc := range cases {
+		coin, err := NewCoin(tc.trueWeight, tc.falseWeight, WithSeed(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := float64(tc.trueWeight) / (float64(tc.trueWeight) + float64(tc.falseWeight))
+		if got := coin.Probability(); math.Abs(got-p) > 1e-12 {
+			t.Errorf("%d:%d: Probability() = %v, want %v", tc.trueWeight, tc.falseWeight, got, p)
+		}
+		const n = 100000
+		heads := 0
+		for i := 0; i < n; i++ {
+			if coin.Flip() {
+				heads++
+			}
+		}
+		if sd := math.Sqrt(n * p * (1 - p)); math.Abs(float64(heads)-n*p) > 4*sd+1 {
+			t.Errorf("%d:%d: %d of %d flips true, want %.0f ± %.0f", tc.trueWeight, tc.falseWeight, heads, n, n*p, 4*sd)
+		}
+	}
+
+	// With odds of 1 in 2^32, a million flips should essentially never land
+	// on the rare side.
+	rare, _ := NewCoin(1, math.MaxUint32, WithSeed(2))
+	for i := 0; i < 1000000; i++ {
+		if rare.Flip() {
+			t.Fatalf("1:MaxUint32 coin landed true after %d flips", i)
+		}
+	}
+}
+
+func TestCoinSources(t *testing.T) {
+	coin, err := NewCoin(3, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+	for i := 0; i < 1000; i++ {
+		if coin.FlipSource(a) != coin.FlipSource(b) {
+			t.Fatalf("flip %d differs between equally seeded sources", i)
+		}
+	}
+	seeded, _ := NewCoin(3, 7, WithSeed(42))
+	again, _ := NewCoin(3, 7, WithSeed(42))
+	for i := 0; i < 1000; i++ {
+		if seeded.Flip() != again.Flip() {
+			t.Fatalf("flip %d differs between equally seeded coins", i)
+		}
+	}
+	if _, err := coin.FlipSecure(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewCoin(0, 0); err != ErrAllZeroWeights {
+		t.Errorf("NewCoin(0, 0) error = %v, want %v", err, ErrAllZeroWeights)
+	}
+}
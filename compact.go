@@ -0,0 +1,31 @@
+package weightedrand
+
+import "math"
+
+// compactTotals replaces the cumulative totals of chs with 32-bit ones, if it
+// is in compact mode and its total weight fits. Otherwise the wide totals are
+// kept, so that compact mode never limits the weights a chooser can hold.
+func (chs *ChooserT[T]) compactTotals() {
+	if !chs.compact || !chs.valid || chs.max > math.MaxUint32 {
+		return
+	}
+	chs.totals32 = make([]uint32, len(chs.totals))
+	for i, total := range chs.totals {
+		chs.totals32[i] = uint32(total)
+	}
+	chs.totals = nil
+}
+
+// numTotals returns the number of cumulative totals held by chs, whether wide
+// or compact.
+func (chs ChooserT[T]) numTotals() int {
+	return len(chs.totals) + len(chs.totals32)
+}
+
+// total returns the cumulative total of the i'th choice of chs.data.
+func (chs ChooserT[T]) total(i int) uint64 {
+	if chs.totals32 != nil {
+		return uint64(chs.totals32[i])
+	}
+	return chs.totals[i]
+}
@@ -0,0 +1,126 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestWithCompactTotals(t *testing.T) {
+	choices := mockFrequencies(1000)
+	compact, err := NewChooserOpts(choices, WithCompactTotals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact.totals != nil || len(compact.totals32) != len(choices) {
+		t.Fatalf("held %d wide and %d compact totals, want only compact", len(compact.totals), len(compact.totals32))
+	}
+	if err := compact.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	// Both representations pick identically for the same raw random values.
+	wide := NewChooser(choices...)
+	for r := uint64(0); r < wide.TotalWeight(); r++ {
+		x, _ := wide.PickFromInt(r)
+		y, _ := compact.PickFromInt(r)
+		if x != y {
+			t.Fatalf("raw value %d: wide picked %v, compact %v", r, x, y)
+		}
+	}
+	a, _ := NewChooserOpts(choices, WithSeed(3))
+	b, _ := NewChooserOpts(choices, WithSeed(3), WithCompactTotals())
+	for i := 0; i < 10000; i++ {
+		if x, y := a.MustPick(), b.MustPick(); x != y {
+			t.Fatalf("pick %d: wide picked %v, compact %v", i, x, y)
+		}
+	}
+	shares := pickShares(t, b, 20000)
+	if got := shares[999]; math.Abs(got-1000.0/500500) > 0.002 {
+		t.Errorf("picked heaviest choice %.4f of the time", got)
+	}
+
+	cdf := compact.CDF()
+	if len(cdf) != len(choices) || cdf[len(cdf)-1] != wide.TotalWeight() {
+		t.Errorf("CDF() has %d totals ending in %d", len(cdf), cdf[len(cdf)-1])
+	}
+	if c := compact.Clone(); len(c.totals32) != len(choices) || &c.totals32[0] == &compact.totals32[0] {
+		t.Error("Clone() did not copy the compact totals")
+	}
+}
+
+func TestWithCompactTotalsFallback(t *testing.T) {
+	big := uint(math.MaxUint32)
+	chooser, err := NewChooserOpts([]Choice{{Item: "a", Weight: big}}, WithCompactTotals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.totals32 == nil {
+		t.Fatal("total of MaxUint32 was not compacted")
+	}
+
+	// Growing the total past 32 bits falls back to wide totals, and shrinking
+	// it again compacts them once more.
+	chooser.Add(Choice{Item: "b", Weight: 1})
+	if chooser.totals32 != nil || len(chooser.totals) != 2 {
+		t.Fatalf("held %d wide and %d compact totals after growing, want only wide", len(chooser.totals), len(chooser.totals32))
+	}
+	if err := chooser.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if item, _ := chooser.PickFromInt(uint64(big)); item != "a" {
+		t.Errorf("PickFromInt(MaxUint32) = %v, want a", item)
+	}
+	chooser.Remove("a")
+	if chooser.totals32 == nil {
+		t.Error("totals were not compacted again after shrinking")
+	}
+
+	if !is64Bit {
+		return
+	}
+	wide, err := NewChooserOpts([]Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: big + 1}}, WithCompactTotals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wide.totals32 != nil {
+		t.Error("total above MaxUint32 was compacted")
+	}
+	if item, _ := wide.PickFromInt(1); item != "b" {
+		t.Errorf("PickFromInt(1) = %v, want b", item)
+	}
+}
+
+// BenchmarkCompactTotals reports the memory held per choice by a chooser
+// with wide and with compact totals, and the cost of a pick from each.
+func BenchmarkCompactTotals(b *testing.B) {
+	for _, n := range []int{1000, 1000000} {
+		choices := make([]ChoiceT[int32], n)
+		for i := range choices {
+			choices[i] = ChoiceT[int32]{Item: int32(i), Weight: uint(i%100 + 1)}
+		}
+		for _, compact := range []bool{false, true} {
+			name := "wide/" + strconv.Itoa(n)
+			opts := []Option{WithRand(rand.New(rand.NewSource(1)))}
+			if compact {
+				name = "compact/" + strconv.Itoa(n)
+				opts = append(opts, WithCompactTotals())
+			}
+			b.Run(name, func(b *testing.B) {
+				var c ChooserT[int32]
+				held := heapRetained(func() {
+					c, _ = NewChooserTOpts(choices, opts...)
+				})
+				runtime.KeepAlive(c)
+				chooser, _ := NewChooserTOpts(choices, opts...)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					chooser.Pick()
+				}
+				b.ReportMetric(float64(held)/float64(n), "B/choice")
+			})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package weightedrand
+
+import (
+	"errors"
+	"math"
+)
+
+// pickCountsDirect is how many picks per choice PickCounts makes one at a
+// time; beyond it, it samples the counts from the multinomial distribution.
+const pickCountsDirect = 8
+
+// PickCounts makes n weighted random picks from the Chooser, with
+// replacement, and returns how many times each item was picked, without
+// building a slice of the items. Choices holding equal items have their counts
+// summed, and items that were never picked are included with a count of zero.
+//
+// When n is large compared to the number of choices, the counts are sampled
+// directly from the multinomial distribution, as a binomial draw for each
+// choice in turn given the picks left over from those before it. That takes
+// O(k) time for k choices rather than O(n), and the counts are distributed
+// exactly as those of n separate picks, but the random numbers drawn differ.
+// The picks are not counted by WithStats nor reported to WithOnPick.
+//
+// Items are used as map keys, so this panics if T is an interface type holding
+// an item that is not comparable.
+func (chs ChooserT[T]) PickCounts(n int) (map[interface{}]int, error) {
+	if n < 0 {
+		return nil, errors.New("error: negative n")
+	}
+	if err := chs.Err(); err != nil {
+		return nil, err
+	}
+	var counts []int
+	if n <= pickCountsDirect*len(chs.data) {
+		counts = chs.directCounts(n)
+	} else {
+		counts = chs.multinomialCounts(n)
+	}
+	result := make(map[interface{}]int, len(chs.data))
+	for i, c := range chs.data {
+		result[c.Item] += counts[i]
+	}
+	return result, nil
+}
+
+// directCounts makes n picks one at a time, returning how many times each
+// choice, indexed like chs.data, was picked.
+func (chs ChooserT[T]) directCounts(n int) []int {
+	counts := make([]int, len(chs.data))
+	for i := 0; i < n; i++ {
+		counts[chs.pick(chs.rng)]++
+	}
+	return counts
+}
+
+// multinomialCounts samples how many of n picks go to each choice, indexed
+// like chs.data, with one binomial draw per choice.
+func (chs ChooserT[T]) multinomialCounts(n int) []int {
+	counts := make([]int, len(chs.data))
+	left := chs.max
+	for i, c := range chs.data {
+		if n == 0 {
+			break
+		}
+		w := uint64(c.Weight)
+		if w == left {
+			counts[i] = n
+			break
+		}
+		k := binomial(chs.rng, n, float64(w)/float64(left))
+		counts[i] = k
+		n -= k
+		left -= w
+	}
+	return counts
+}
+
+// binomial returns the number of successes in n trials of probability p, drawn
+// from rs: by inversion when the mean is small, and otherwise by the BTRD
+// algorithm of Hörmann, "The generation of binomial random variates" (1993),
+// which takes constant expected time.
+func binomial(rs source, n int, p float64) int {
+	switch {
+	case p <= 0 || n == 0:
+		return 0
+	case p >= 1:
+		return n
+	case p > 0.5:
+		return n - binomial(rs, n, 1-p)
+	case float64(n)*p < 10:
+		return binomialInversion(rs, n, p)
+	default:
+		return binomialBTRD(rs, n, p)
+	}
+}
+
+// binomialInversion draws a binomial variate by sequential search of its
+// cumulative distribution, taking O(np) time.
+func binomialInversion(rs source, n int, p float64) int {
+	q := 1 - p
+	s := p / q
+	a := float64(n+1) * s
+	for {
+		r := math.Pow(q, float64(n))
+		u := float64n(rs)
+		x := 0
+		for u > r {
+			u -= r
+			x++
+			if x > n {
+				break
+			}
+			r *= a/float64(x) - s
+		}
+		if x <= n {
+			return x
+		}
+	}
+}
+
+// binomialBTRD draws a binomial variate by transformed rejection with
+// decomposition, for p at most 0.5 and np at least 10. Draws falling in the
+// region where the hat is tight are accepted at once; the rest are compared
+// with the log of the density, using the Stirling tails of the factorials.
+func binomialBTRD(rs source, n int, p float64) int {
+	nf := float64(n)
+	sd := math.Sqrt(nf * p * (1 - p))
+	b := 1.15 + 2.53*sd
+	a := -0.0873 + 0.0248*b + 0.01*p
+	c := nf*p + 0.5
+	vr := 0.92 - 4.2/b
+	r := p / (1 - p)
+	alpha := (2.83 + 5.1/b) * sd
+	m := math.Floor((nf + 1) * p)
+
+	for {
+		u := float64n(rs) - 0.5
+		v := float64n(rs)
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + c)
+		if us >= 0.07 && v <= vr {
+			return int(k)
+		}
+		if k < 0 || k > nf {
+			continue
+		}
+		v = math.Log(v * alpha / (a/(us*us) + b))
+		bound := (m+0.5)*math.Log((m+1)/(r*(nf-m+1))) +
+			(nf+1)*math.Log((nf-m+1)/(nf-k+1)) +
+			(k+0.5)*math.Log(r*(nf-k+1)/(k+1)) +
+			stirlingTail(m) + stirlingTail(nf-m) - stirlingTail(k) - stirlingTail(nf-k)
+		if v <= bound {
+			return int(k)
+		}
+	}
+}
+
+// stirlingTails holds stirlingTail(k) for k below 10.
+var stirlingTails = [10]float64{
+	0.08106146679532726, 0.04134069595540929, 0.02767792568499834,
+	0.02079067210376509, 0.01664469118982119, 0.01387612882307075,
+	0.01189670994589177, 0.01041126526197209, 0.009255462182712733,
+	0.008330563433362871,
+}
+
+// stirlingTail returns log(k!) less its Stirling approximation,
+// log(sqrt(2π)) + (k+0.5)log(k+1) - (k+1).
+func stirlingTail(k float64) float64 {
+	if k < 10 {
+		return stirlingTails[int(k)]
+	}
+	k1 := 1 / (k + 1)
+	k2 := k1 * k1
+	return (1.0/12 - (1.0/360-k2/1260)*k2) * k1
+}
@@ -0,0 +1,195 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestPickCounts(t *testing.T) {
+	choices := []Choice{
+		{Item: "a", Weight: 1},
+		{Item: "b", Weight: 2},
+		{Item: "zero", Weight: 0},
+		{Item: "c", Weight: 3},
+		{Item: "a", Weight: 4}, // duplicate, summed
+	}
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)), choices...)
+	for _, n := range []int{0, 1, 40, 100000, 1e7} {
+		counts, err := chooser.PickCounts(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := 0
+		for _, c := range counts {
+			total += c
+		}
+		if total != n {
+			t.Errorf("PickCounts(%d) counts sum to %d", n, total)
+		}
+		if len(counts) != 4 || counts["zero"] != 0 {
+			t.Errorf("PickCounts(%d) = %v, want every item and no picks of zero", n, counts)
+		}
+		if n < 100000 {
+			continue
+		}
+		for item, p := range map[interface{}]float64{"a": 0.5, "b": 0.2, "c": 0.3} {
+			if got := float64(counts[item]) / float64(n); math.Abs(got-p) > 0.01 {
+				t.Errorf("PickCounts(%d) gave %v %.4f of the picks, want %.4f", n, item, got, p)
+			}
+		}
+	}
+
+	if _, err := chooser.PickCounts(-1); err == nil {
+		t.Error("expected error for negative n")
+	}
+	if _, err := NewChooser().PickCounts(10); err != ErrNoChoices {
+		t.Errorf("PickCounts() error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+// TestPickCountsPaths checks that the direct and multinomial paths of
+// PickCounts agree with each other and with the multinomial mean and variance
+// of each count.
+func TestPickCountsPaths(t *testing.T) {
+	chooser := NewChooserTWithRand(rand.New(rand.NewSource(2)),
+		ChoiceT[int]{Item: 0, Weight: 1},
+		ChoiceT[int]{Item: 1, Weight: 10},
+		ChoiceT[int]{Item: 2, Weight: 89},
+	)
+	const n, trials = 1000, 4000
+	paths := map[string]func() []int{
+		"direct":      func() []int { return chooser.directCounts(n) },
+		"multinomial": func() []int { return chooser.multinomialCounts(n) },
+	}
+	means := make(map[string][]float64)
+	for name, counts := range paths {
+		sum := make([]float64, 3)
+		sumSq := make([]float64, 3)
+		for i := 0; i < trials; i++ {
+			for j, c := range counts() {
+				sum[j] += float64(c)
+				sumSq[j] += float64(c) * float64(c)
+			}
+		}
+		for j, c := range chooser.data {
+			p := float64(c.Weight) / 100
+			mean := sum[j] / trials
+			variance := sumSq[j]/trials - mean*mean
+			wantMean, wantVar := n*p, n*p*(1-p)
+			// The mean of trials counts has a standard error of
+			// sqrt(wantVar/trials); allow five of them.
+			if math.Abs(mean-wantMean) > 5*math.Sqrt(wantVar/trials) {
+				t.Errorf("%s: choice %d mean %.3f, want %.3f", name, j, mean, wantMean)
+			}
+			if math.Abs(variance-wantVar) > 0.1*wantVar {
+				t.Errorf("%s: choice %d variance %.3f, want %.3f", name, j, variance, wantVar)
+			}
+			means[name] = append(means[name], mean)
+		}
+	}
+	for j := range chooser.data {
+		if d, m := means["direct"][j], means["multinomial"][j]; math.Abs(d-m) > 0.01*n {
+			t.Errorf("choice %d: direct mean %.3f, multinomial mean %.3f", j, d, m)
+		}
+	}
+}
+
+// TestBinomial compares binomial draws with the exact distribution by a
+// chi-square test, pooling bins expected to hold fewer than 20 draws.
+func TestBinomial(t *testing.T) {
+	rs := rand.New(rand.NewSource(3))
+	cases := []struct {
+		n int
+		p float64
+	}{
+		{1, 0.3},
+		{20, 0.3},  // inversion
+		{100, 0.1}, // BTRD at its threshold
+		{1000, 0.3},
+		{1000, 0.7}, // by symmetry
+		{100000, 0.02},
+		{1 << 30, 1e-6},
+		{1 << 30, 0.5},
+	}
+	const draws = 100000
+	for _, tc := range cases {
+		counts := make(map[int]int)
+		for i := 0; i < draws; i++ {
+			k := binomial(rs, tc.n, tc.p)
+			if k < 0 || k > tc.n {
+				t.Fatalf("binomial(%d, %v) = %d, out of range", tc.n, tc.p, k)
+			}
+			counts[k]++
+		}
+
+		mean := float64(tc.n) * tc.p
+		sd := math.Sqrt(mean * (1 - tc.p))
+		lo := int(math.Max(0, math.Floor(mean-8*sd-1)))
+		hi := int(math.Min(float64(tc.n), math.Ceil(mean+8*sd+1)))
+		var chi2, pooledObs, pooledExp float64
+		bins := 0
+		for k := lo; k <= hi; k++ {
+			pooledObs += float64(counts[k])
+			pooledExp += draws * binomialPMF(tc.n, tc.p, k)
+			if pooledExp >= 20 {
+				chi2 += (pooledObs - pooledExp) * (pooledObs - pooledExp) / pooledExp
+				pooledObs, pooledExp = 0, 0
+				bins++
+			}
+		}
+		// With bins degrees of freedom, the chi-square statistic has mean
+		// bins and standard deviation sqrt(2 bins); allow five of them.
+		if limit := float64(bins) + 5*math.Sqrt(2*float64(bins)) + 5; chi2 > limit {
+			t.Errorf("binomial(%d, %v): chi-square %.1f over %d bins, want below %.1f", tc.n, tc.p, chi2, bins, limit)
+		}
+	}
+
+	for _, tc := range []struct {
+		n    int
+		p    float64
+		want int
+	}{{0, 0.5, 0}, {10, 0, 0}, {10, 1, 10}} {
+		if got := binomial(rs, tc.n, tc.p); got != tc.want {
+			t.Errorf("binomial(%d, %v) = %d, want %d", tc.n, tc.p, got, tc.want)
+		}
+	}
+}
+
+// binomialPMF returns the probability of k successes in n trials of
+// probability p.
+func binomialPMF(n int, p float64, k int) float64 {
+	lg := func(x float64) float64 {
+		v, _ := math.Lgamma(x)
+		return v
+	}
+	nf, kf := float64(n), float64(k)
+	return math.Exp(lg(nf+1) - lg(kf+1) - lg(nf-kf+1) + kf*math.Log(p) + (nf-kf)*math.Log1p(-p))
+}
+
+func TestStirlingTail(t *testing.T) {
+	for k := 0.0; k < 30; k++ {
+		lf, _ := math.Lgamma(k + 1)
+		want := lf - (0.5*math.Log(2*math.Pi) + (k+0.5)*math.Log(k+1) - (k + 1))
+		if got := stirlingTail(k); math.Abs(got-want) > 1e-9 {
+			t.Errorf("stirlingTail(%v) = %v, want %v", k, got, want)
+		}
+	}
+}
+
+func BenchmarkPickCounts(b *testing.B) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)), mockFrequencies(100)...)
+	for _, n := range []int{100, 1e6} {
+		b.Run("direct/"+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				chooser.directCounts(n)
+			}
+		})
+		b.Run("multinomial/"+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				chooser.multinomialCounts(n)
+			}
+		})
+	}
+}
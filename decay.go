@@ -0,0 +1,177 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// A Clock tells a DecayChooser the current time. The times it returns must not
+// go backwards; those from time.Now carry a monotonic clock reading, which
+// ensures that.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock backed by time.Now.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// A DecayChooserT picks items with weights that decay exponentially over time
+// unless refreshed, so that recent observations count for more than old ones.
+// Each Boost adds to an item's weight; thereafter its weight halves every
+// half-life. Decayed weights are computed lazily, at Pick time, from each
+// item's weight and the time it was last boosted.
+//
+// A DecayChooserT is not safe for concurrent use.
+type DecayChooserT[T any] struct {
+	entries   []decayEntry[T]
+	halfLife  time.Duration
+	clock     Clock
+	floor     float64
+	dropBelow bool
+	rng       source
+}
+
+// A DecayChooser is a DecayChooserT over untyped items.
+type DecayChooser = DecayChooserT[interface{}]
+
+// decayEntry is an item's weight as of the time it was last boosted.
+type decayEntry[T any] struct {
+	item    T
+	weight  float64
+	updated time.Time
+}
+
+// NewDecayChooserT initializes an empty DecayChooserT whose weights halve
+// every halfLife, configured by opts: WithClock replaces the system clock,
+// WithDecayFloor or WithDecayDropBelow bound how far weights may decay, and
+// the options choosing a rand source apply as for NewChooserTOpts. An error is
+// returned for a halfLife that is not positive, a negative or NaN floor, any
+// other option, or an invalid combination of options.
+func NewDecayChooserT[T any](halfLife time.Duration, opts ...Option) (*DecayChooserT[T], error) {
+	if halfLife <= 0 {
+		return nil, fmt.Errorf("error: half-life %v is not positive", halfLife)
+	}
+	o, err := buildOptions(opts, decayScope)
+	if err != nil {
+		return nil, err
+	}
+	if !(o.floor >= 0) || math.IsInf(o.floor, 1) {
+		return nil, fmt.Errorf("%w: floor %v", ErrInvalidWeight, o.floor)
+	}
+	if o.clock == nil {
+		o.clock = systemClock{}
+	}
+	return &DecayChooserT[T]{
+		halfLife:  halfLife,
+		clock:     o.clock,
+		floor:     o.floor,
+		dropBelow: o.dropBelow,
+		rng:       o.rng,
+	}, nil
+}
+
+// NewDecayChooser initializes an empty DecayChooser whose weights halve every
+// halfLife. See NewDecayChooserT.
+func NewDecayChooser(halfLife time.Duration, opts ...Option) (*DecayChooser, error) {
+	return NewDecayChooserT[interface{}](halfLife, opts...)
+}
+
+// Boost adds amount to the decayed weight of item, adding it if it is not yet
+// held, and restarts its decay from now. A negative, NaN or infinite amount is
+// reported as ErrInvalidWeight.
+//
+// Items are matched by ==, so this panics if T is an interface type holding an
+// item that is not comparable.
+func (d *DecayChooserT[T]) Boost(item T, amount float64) error {
+	if !(amount >= 0) || math.IsInf(amount, 1) {
+		return fmt.Errorf("%w: amount %v", ErrInvalidWeight, amount)
+	}
+	now := d.clock.Now()
+	for i := range d.entries {
+		if e := &d.entries[i]; equalItems(e.item, item) {
+			e.weight = d.decayed(*e, now) + amount
+			e.updated = now
+			return nil
+		}
+	}
+	d.entries = append(d.entries, decayEntry[T]{item: item, weight: amount, updated: now})
+	return nil
+}
+
+// Weight returns the current decayed weight of item, before any floor is
+// applied, and whether the DecayChooserT holds it.
+func (d *DecayChooserT[T]) Weight(item T) (float64, bool) {
+	now := d.clock.Now()
+	for _, e := range d.entries {
+		if equalItems(e.item, item) {
+			return d.decayed(e, now), true
+		}
+	}
+	return 0, false
+}
+
+// Pick returns a single random item, weighted by the decayed weights as of
+// now. Items that have decayed below a floor set by WithDecayDropBelow are
+// dropped first. ErrNoChoices is returned if no items are held, and
+// ErrAllZeroWeights if every weight is zero.
+func (d *DecayChooserT[T]) Pick() (T, error) {
+	var zero T
+	now := d.clock.Now()
+	weights := make([]float64, 0, len(d.entries))
+	kept := d.entries[:0]
+	var total float64
+	for _, e := range d.entries {
+		w := d.decayed(e, now)
+		if w < d.floor {
+			if d.dropBelow {
+				continue
+			}
+			w = d.floor
+		}
+		kept = append(kept, e)
+		weights = append(weights, w)
+		total += w
+	}
+	for i := len(kept); i < len(d.entries); i++ {
+		d.entries[i] = decayEntry[T]{}
+	}
+	d.entries = kept
+
+	if len(d.entries) == 0 {
+		return zero, ErrNoChoices
+	}
+	if total == 0 {
+		return zero, ErrAllZeroWeights
+	}
+	r := float64n(d.rng) * total
+	for i, w := range weights {
+		if r < w {
+			return d.entries[i].item, nil
+		}
+		r -= w
+	}
+	// Rounding can leave r just short of the total; take the last nonzero.
+	i := len(weights) - 1
+	for weights[i] == 0 {
+		i--
+	}
+	return d.entries[i].item, nil
+}
+
+// Len returns the number of items held by the DecayChooserT, including any
+// that the next Pick would drop.
+func (d *DecayChooserT[T]) Len() int {
+	return len(d.entries)
+}
+
+// decayed returns the weight of e decayed to the time now.
+func (d *DecayChooserT[T]) decayed(e decayEntry[T], now time.Time) float64 {
+	age := now.Sub(e.updated)
+	if age <= 0 {
+		return e.weight
+	}
+	return e.weight * math.Exp2(-float64(age)/float64(d.halfLife))
+}
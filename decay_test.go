@@ -0,0 +1,130 @@
+package weightedrand
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// decayShares returns the share of n picks from d that went to each item.
+func decayShares(t *testing.T, d *DecayChooser, n int) map[interface{}]float64 {
+	t.Helper()
+	shares := make(map[interface{}]float64)
+	for i := 0; i < n; i++ {
+		item, err := d.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[item] += 1 / float64(n)
+	}
+	return shares
+}
+
+func TestDecayChooser(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	d, err := NewDecayChooser(time.Hour, WithClock(clock), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	d.Boost("old", 3)
+	d.Boost("new", 1)
+	assertShares(t, decayShares(t, d, 10000), map[interface{}]float64{"old": 0.75, "new": 0.25})
+
+	// After one half-life both have halved, so their shares are unchanged;
+	// boosting new then tips the balance.
+	clock.advance(time.Hour)
+	if w, ok := d.Weight("old"); !ok || math.Abs(w-1.5) > 1e-9 {
+		t.Errorf("Weight(old) = %v, %v; want 1.5, true", w, ok)
+	}
+	d.Boost("new", 2.5)
+	if w, _ := d.Weight("new"); math.Abs(w-3) > 1e-9 {
+		t.Errorf("Weight(new) = %v, want 3", w)
+	}
+	assertShares(t, decayShares(t, d, 10000), map[interface{}]float64{"old": 1.5 / 4.5, "new": 3 / 4.5})
+
+	// Decay scales every weight alike, so without further boosts the shares
+	// hold steady while the weights shrink.
+	clock.advance(2 * time.Hour)
+	assertShares(t, decayShares(t, d, 10000), map[interface{}]float64{"old": 1.5 / 4.5, "new": 3 / 4.5})
+	if w, _ := d.Weight("old"); math.Abs(w-0.375) > 1e-9 {
+		t.Errorf("Weight(old) = %v, want 0.375", w)
+	}
+
+	if err := d.Boost("x", -1); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("Boost(-1) error = %v, want %v", err, ErrInvalidWeight)
+	}
+	if err := d.Boost("x", math.NaN()); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("Boost(NaN) error = %v, want %v", err, ErrInvalidWeight)
+	}
+	if d.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", d.Len())
+	}
+}
+
+func TestDecayChooserFloor(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	clamped, err := NewDecayChooser(time.Minute, WithClock(clock), WithSeed(1), WithDecayFloor(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped, err := NewDecayChooser(time.Minute, WithClock(clock), WithSeed(1), WithDecayDropBelow(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*DecayChooser{clamped, dropped} {
+		d.Boost("stale", 8)
+	}
+	clock.advance(10 * time.Minute)
+	for _, d := range []*DecayChooser{clamped, dropped} {
+		d.Boost("fresh", 3)
+	}
+
+	// stale has decayed to 8/1024, below the floor of 1.
+	assertShares(t, decayShares(t, clamped, 10000), map[interface{}]float64{"stale": 0.25, "fresh": 0.75})
+	assertShares(t, decayShares(t, dropped, 10000), map[interface{}]float64{"fresh": 1})
+	if dropped.Len() != 1 {
+		t.Errorf("Len() = %d after dropping, want 1", dropped.Len())
+	}
+	if _, ok := dropped.Weight("stale"); ok {
+		t.Error("dropped item still held")
+	}
+
+	clock.advance(time.Hour)
+	if _, err := dropped.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v after everything decayed, want %v", err, ErrNoChoices)
+	}
+	if item, err := clamped.Pick(); err != nil || item == nil {
+		t.Errorf("Pick() = %v, %v; want an item clamped to the floor", item, err)
+	}
+}
+
+func TestNewDecayChooserErrors(t *testing.T) {
+	if _, err := NewDecayChooser(0); err == nil {
+		t.Error("expected error for zero half-life")
+	}
+	if _, err := NewDecayChooser(time.Hour, WithDecayFloor(-1)); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("NewDecayChooser() error = %v, want %v", err, ErrInvalidWeight)
+	}
+	d, err := NewDecayChooser(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Boost("zero", 0)
+	if _, err := d.Pick(); err != ErrAllZeroWeights {
+		t.Errorf("Pick() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+}
@@ -0,0 +1,36 @@
+package weightedrand
+
+import "math/bits"
+
+// dedup returns a copy of cs in which the choices whose items share a key are
+// merged into the first of them, summing their weights, along with the number
+// merged away. A nil key compares the items themselves. If a sum does not fit
+// in a uint, no choices are returned, with ErrWeightOverflow.
+func dedup[T any](cs []ChoiceT[T], key func(interface{}) string) ([]ChoiceT[T], int, error) {
+	out := make([]ChoiceT[T], 0, len(cs))
+	seen := make(map[interface{}]int, len(cs))
+	for _, c := range cs {
+		var k interface{} = c.Item
+		if key != nil {
+			k = key(c.Item)
+		}
+		i, ok := seen[k]
+		if !ok {
+			seen[k] = len(out)
+			out = append(out, c)
+			continue
+		}
+		sum, carry := bits.Add(out[i].Weight, c.Weight, 0)
+		if carry != 0 {
+			return nil, 0, ErrWeightOverflow
+		}
+		out[i].Weight = sum
+	}
+	return out, len(cs) - len(out), nil
+}
+
+// Merged returns the number of choices that WithDedup merged into others
+// during construction, so that Len is that many fewer than were given.
+func (chs ChooserT[T]) Merged() int {
+	return chs.merged
+}
@@ -0,0 +1,73 @@
+package weightedrand
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithDedup(t *testing.T) {
+	chooser, err := NewChooserOpts([]Choice{
+		{Item: "a", Weight: 1},
+		{Item: "b", Weight: 2},
+		{Item: "a", Weight: 3},
+		{Item: "zero", Weight: 0},
+		{Item: "b", Weight: 0},
+		{Item: "zero", Weight: 0},
+	}, WithDedup(nil), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Choice{{Item: "a", Weight: 4}, {Item: "b", Weight: 2}, {Item: "zero", Weight: 0}}
+	if got := chooser.Choices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Choices() = %v, want %v", got, want)
+	}
+	if chooser.Len() != 3 || chooser.Merged() != 3 {
+		t.Errorf("Len(), Merged() = %d, %d; want 3, 3", chooser.Len(), chooser.Merged())
+	}
+	assertShares(t, pickShares(t, chooser, 20000), map[interface{}]float64{"a": 4.0 / 6, "b": 2.0 / 6})
+
+	all, err := NewChooserOpts([]Choice{{Item: 1, Weight: 1}, {Item: 1, Weight: 1}, {Item: 1, Weight: 1}}, WithDedup(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Len() != 1 || all.Merged() != 2 || all.TotalWeight() != 3 {
+		t.Errorf("Len(), Merged(), TotalWeight() = %d, %d, %d; want 1, 2, 3", all.Len(), all.Merged(), all.TotalWeight())
+	}
+
+	if plain := NewChooser(Choice{Item: 1, Weight: 1}, Choice{Item: 1, Weight: 1}); plain.Len() != 2 || plain.Merged() != 0 {
+		t.Errorf("without WithDedup: Len(), Merged() = %d, %d; want 2, 0", plain.Len(), plain.Merged())
+	}
+}
+
+func TestWithDedupKey(t *testing.T) {
+	input := []ChoiceT[string]{
+		{Item: "US-East", Weight: 2},
+		{Item: "us-east", Weight: 3},
+		{Item: "EU-West", Weight: 1},
+	}
+	chooser, err := NewChooserTOpts(input, WithDedup(func(item interface{}) string {
+		return strings.ToLower(item.(string))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ChoiceT[string]{{Item: "US-East", Weight: 5}, {Item: "EU-West", Weight: 1}}
+	if got := chooser.Choices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Choices() = %v, want %v", got, want)
+	}
+	if input[0].Weight != 2 {
+		t.Error("WithDedup modified the caller's slice")
+	}
+}
+
+func TestWithDedupOverflow(t *testing.T) {
+	chs, err := NewChooserOpts([]Choice{{Item: "a", Weight: ^uint(0)}, {Item: "b", Weight: 1}, {Item: "a", Weight: 1}}, WithDedup(nil))
+	if err != ErrWeightOverflow {
+		t.Errorf("NewChooserOpts() error = %v, want %v", err, ErrWeightOverflow)
+	}
+	// No half-merged choices are left behind.
+	if chs.Len() != 0 || len(chs.Choices()) != 0 {
+		t.Errorf("Len() = %d, Choices() = %v; want none", chs.Len(), chs.Choices())
+	}
+}
@@ -0,0 +1,169 @@
+package weightedrand
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A ChangeKind categorizes a ChangeT found by Diff.
+type ChangeKind int
+
+const (
+	// Added marks an item held only by the newer Chooser.
+	Added ChangeKind = iota
+	// Removed marks an item held only by the older Chooser.
+	Removed
+	// Reweighted marks an item held by both Choosers with different
+	// weights.
+	Reweighted
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Reweighted:
+		return "reweighted"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// A ChangeT describes how one item differs between two Choosers. The weight
+// of an item held by no choice of a Chooser is zero; that of an item held by
+// several is the sum of theirs.
+type ChangeT[T any] struct {
+	Kind      ChangeKind
+	Item      T
+	OldWeight uint64
+	NewWeight uint64
+}
+
+// A Change is a ChangeT over untyped items.
+type Change = ChangeT[interface{}]
+
+func (c ChangeT[T]) String() string {
+	return fmt.Sprintf("%v %v (weight %d -> %d)", c.Kind, c.Item, c.OldWeight, c.NewWeight)
+}
+
+// A DiffOption configures how Equal and Diff match the items of two
+// Choosers.
+type DiffOption func(*diffOptions)
+
+// diffOptions holds the configuration assembled from a list of DiffOptions.
+type diffOptions struct {
+	key func(interface{}) string
+}
+
+// DiffKey makes Equal and Diff treat items with the same key as the same
+// item, as WithDedup does, rather than comparing them with ==: for items that
+// are not comparable, or that are equal by some other measure.
+func DiffKey(key func(item interface{}) string) DiffOption {
+	return func(o *diffOptions) {
+		o.key = key
+	}
+}
+
+// Equal reports whether chs and other hold the same items with the same
+// weights, however the choices are ordered or split: the choices {a 1, b 2}
+// equal {b 2, a 1} and {b 1, a 1, b 1}. See Diff for how items are matched.
+func (chs ChooserT[T]) Equal(other ChooserT[T], opts ...DiffOption) bool {
+	return len(chs.Diff(other, opts...)) == 0
+}
+
+// Diff returns the changes that turn chs into other: every item whose total
+// weight differs between them, as Added if chs does not hold it, Removed if
+// other does not, and Reweighted otherwise. Items are listed in the original
+// order of their first choice in chs, and then, for those Added, in other. It
+// returns nil if the two are Equal.
+//
+// Items are matched by the key set with DiffKey or else compared with ==,
+// except that items that cannot be compared, such as slices or structs holding
+// slices in interface fields, are matched by their Go syntax representation,
+// formatted with %#v, so that slices with the same elements match. An item held with a weight of zero is still held, so it
+// differs from one that is absent.
+func (chs ChooserT[T]) Diff(other ChooserT[T], opts ...DiffOption) []ChangeT[T] {
+	var o diffOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	old, oldKeys := weightsByKey(chs, o.key)
+	next, nextKeys := weightsByKey(other, o.key)
+	var changes []ChangeT[T]
+	for _, k := range oldKeys {
+		w, nw := old[k], next[k]
+		switch {
+		case nw == nil:
+			changes = append(changes, ChangeT[T]{Kind: Removed, Item: w.item, OldWeight: w.weight})
+		case nw.weight != w.weight:
+			changes = append(changes, ChangeT[T]{Kind: Reweighted, Item: w.item, OldWeight: w.weight, NewWeight: nw.weight})
+		}
+	}
+	for _, k := range nextKeys {
+		if old[k] == nil {
+			nw := next[k]
+			changes = append(changes, ChangeT[T]{Kind: Added, Item: nw.item, NewWeight: nw.weight})
+		}
+	}
+	return changes
+}
+
+// itemWeight is the first item with a given key among the choices of a
+// Chooser, and the total weight of those choices.
+type itemWeight[T any] struct {
+	item   T
+	weight uint64
+}
+
+// weightsByKey returns the total weight of the choices of chs for each item
+// key, as Diff matches them, and the keys in the original order of their first
+// choice.
+func weightsByKey[T any](chs ChooserT[T], key func(interface{}) string) (map[interface{}]*itemWeight[T], []interface{}) {
+	weights := make(map[interface{}]*itemWeight[T], len(chs.data))
+	var keys []interface{}
+	for _, c := range chs.original() {
+		k := diffKey(c.Item, key)
+		if w := weights[k]; w != nil {
+			w.weight += uint64(c.Weight)
+			continue
+		}
+		weights[k] = &itemWeight[T]{item: c.Item, weight: uint64(c.Weight)}
+		keys = append(keys, k)
+	}
+	return weights, keys
+}
+
+// formattedItem is the %#v representation of an item that is not
+// comparable, typed so that it never equals a string item.
+type formattedItem string
+
+// diffKey returns the map key by which Diff matches item.
+func diffKey(item interface{}, key func(interface{}) string) interface{} {
+	if key != nil {
+		return key(item)
+	}
+	if t := reflect.TypeOf(item); t != nil && (!t.Comparable() || !hashable(item)) {
+		return formattedItem(fmt.Sprintf("%T %#v", item, item))
+	}
+	return item
+}
+
+// hashable reports whether item can be used as a map key. An item of a
+// comparable type cannot if it holds, in an interface field or element, a
+// value whose type is not comparable; hashing it then panics.
+func hashable(item interface{}) (ok bool) {
+	switch reflect.TypeOf(item).Kind() {
+	case reflect.Struct, reflect.Array:
+	default:
+		return true
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	_ = map[interface{}]bool{item: true}
+	return true
+}
@@ -0,0 +1,117 @@
+package weightedrand
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	base := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2}, Choice{Item: "off", Weight: 0})
+	cases := []struct {
+		name  string
+		other Chooser
+		want  bool
+	}{
+		{"same", NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2}, Choice{Item: "off", Weight: 0}), true},
+		{"reordered", NewChooser(Choice{Item: "off", Weight: 0}, Choice{Item: "b", Weight: 2}, Choice{Item: "a", Weight: 1}), true},
+		{"split", NewChooser(Choice{Item: "b", Weight: 1}, Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 1}, Choice{Item: "off", Weight: 0}), true},
+		{"reweighted", NewChooser(Choice{Item: "a", Weight: 2}, Choice{Item: "b", Weight: 2}, Choice{Item: "off", Weight: 0}), false},
+		{"zero weight dropped", NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2}), false},
+		{"scaled", NewChooser(Choice{Item: "a", Weight: 2}, Choice{Item: "b", Weight: 4}, Choice{Item: "off", Weight: 0}), false},
+		{"empty", NewChooser(), false},
+	}
+	for _, tc := range cases {
+		if got := base.Equal(tc.other); got != tc.want {
+			t.Errorf("%s: Equal() = %v, want %v", tc.name, got, tc.want)
+		}
+		if got := tc.other.Equal(base); got != tc.want {
+			t.Errorf("%s: reversed Equal() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if !NewChooser().Equal(NewChooser()) {
+		t.Error("empty Choosers are not Equal")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := NewChooserT(
+		ChoiceT[string]{Item: "keep", Weight: 5},
+		ChoiceT[string]{Item: "gone", Weight: 3},
+		ChoiceT[string]{Item: "heavier", Weight: 1},
+		ChoiceT[string]{Item: "lighter", Weight: 4},
+	)
+	next := NewChooserT(
+		ChoiceT[string]{Item: "new", Weight: 7},
+		ChoiceT[string]{Item: "lighter", Weight: 2},
+		ChoiceT[string]{Item: "keep", Weight: 5},
+		ChoiceT[string]{Item: "heavier", Weight: 9},
+		ChoiceT[string]{Item: "off", Weight: 0},
+	)
+	want := []ChangeT[string]{
+		{Kind: Removed, Item: "gone", OldWeight: 3},
+		{Kind: Reweighted, Item: "heavier", OldWeight: 1, NewWeight: 9},
+		{Kind: Reweighted, Item: "lighter", OldWeight: 4, NewWeight: 2},
+		{Kind: Added, Item: "new", NewWeight: 7},
+		{Kind: Added, Item: "off"},
+	}
+	if got := old.Diff(next); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+	if got := old.Diff(old); got != nil {
+		t.Errorf("Diff() with itself = %v, want nil", got)
+	}
+	if got, want := want[1].String(), "reweighted heavier (weight 1 -> 9)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := ChangeKind(9).String(); got != "ChangeKind(9)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestDiffNotComparable(t *testing.T) {
+	a := NewChooser(Choice{Item: []int{1, 2}, Weight: 1}, Choice{Item: "x", Weight: 1})
+	b := NewChooser(Choice{Item: "x", Weight: 1}, Choice{Item: []int{1, 2}, Weight: 1})
+	if !a.Equal(b) {
+		t.Error("Choosers of equal slices are not Equal")
+	}
+	c := NewChooser(Choice{Item: []int{1, 3}, Weight: 1}, Choice{Item: "x", Weight: 1})
+	if got := a.Diff(c); len(got) != 2 || got[0].Kind != Removed || got[1].Kind != Added {
+		t.Errorf("Diff() = %v, want []int{1, 2} removed and []int{1, 3} added", got)
+	}
+	// The formatted key of a slice never matches a string item.
+	d := NewChooser(Choice{Item: "[]int []int{1, 2}", Weight: 1}, Choice{Item: "x", Weight: 1})
+	if a.Equal(d) {
+		t.Error("a slice item Equal to a string item")
+	}
+
+	// A comparable struct type may still hold a slice in an interface.
+	type tagged struct {
+		Tag interface{}
+	}
+	e := NewChooser(Choice{Item: tagged{[]int{1}}, Weight: 1}, Choice{Item: tagged{"x"}, Weight: 1})
+	f := NewChooser(Choice{Item: tagged{"x"}, Weight: 1}, Choice{Item: tagged{[]int{1}}, Weight: 2})
+	if got := e.Diff(f); len(got) != 1 || got[0].Kind != Reweighted || got[0].NewWeight != 2 {
+		t.Errorf("Diff() = %v, want tagged{[]int{1}} reweighted to 2", got)
+	}
+}
+
+func TestDiffKey(t *testing.T) {
+	lower := DiffKey(func(item interface{}) string { return strings.ToLower(item.(string)) })
+	a := NewChooser(Choice{Item: "Host", Weight: 2}, Choice{Item: "other", Weight: 1})
+	b := NewChooser(Choice{Item: "host", Weight: 1}, Choice{Item: "HOST", Weight: 1}, Choice{Item: "OTHER", Weight: 1})
+	if a.Equal(b) {
+		t.Error("Equal() without a key ignores case")
+	}
+	if !a.Equal(b, lower) {
+		t.Errorf("Equal() with a key = false, Diff() = %v", a.Diff(b, lower))
+	}
+	c := NewChooser(Choice{Item: "HOST", Weight: 3})
+	want := []Change{
+		{Kind: Reweighted, Item: "Host", OldWeight: 2, NewWeight: 3},
+		{Kind: Removed, Item: "other", OldWeight: 1},
+	}
+	if got := a.Diff(c, lower); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
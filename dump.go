@@ -0,0 +1,64 @@
+package weightedrand
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// dumpRows is the most choices Dump lists in full; beyond it, only the first
+// and last dumpRows/2 are shown.
+const dumpRows = 20
+
+// String formats the choice as "item=X weight=Y", rendering the item with %v.
+func (c ChoiceT[T]) String() string {
+	return fmt.Sprintf("item=%v weight=%d", c.Item, c.Weight)
+}
+
+// Dump writes a table of the Chooser's choices to w, in the order it searches
+// them, giving for each its original index, item, weight, cumulative total and
+// probability of being picked. Items are rendered with %v. A Chooser of more
+// than 20 choices is truncated to its first and last 10, noting how many were
+// omitted. If the Chooser cannot be picked from, the reason is written first.
+func (chs ChooserT[T]) Dump(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%d choices, total weight %d\n", len(chs.data), chs.TotalWeight()); err != nil {
+		return err
+	}
+	if err := chs.Err(); err != nil {
+		if _, err := fmt.Fprintln(w, err); err != nil {
+			return err
+		}
+	}
+	if len(chs.data) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "index\titem\tweight\ttotal\tprobability")
+	omitted := 0
+	for i, c := range chs.data {
+		if len(chs.data) > dumpRows && i == dumpRows/2 {
+			omitted = len(chs.data) - dumpRows
+			fmt.Fprintln(tw, "...\t...\t...\t...\t...")
+		}
+		if omitted > 0 && i < len(chs.data)-dumpRows/2 {
+			continue
+		}
+		total, prob := "-", "-"
+		if i < chs.numTotals() {
+			total = fmt.Sprint(chs.total(i))
+		}
+		if chs.max > 0 {
+			prob = fmt.Sprintf("%.6f", float64(c.Weight)/float64(chs.max))
+		}
+		fmt.Fprintf(tw, "%d\t%v\t%d\t%s\t%s\n", chs.index(i), c.Item, c.Weight, total, prob)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if omitted > 0 {
+		_, err := fmt.Fprintf(w, "(%d choices omitted)\n", omitted)
+		return err
+	}
+	return nil
+}
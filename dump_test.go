@@ -0,0 +1,143 @@
+package weightedrand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestChoiceString(t *testing.T) {
+	cases := []struct {
+		choice fmt.Stringer
+		want   string
+	}{
+		{Choice{Item: "a", Weight: 3}, "item=a weight=3"},
+		{Choice{Weight: 0}, "item=<nil> weight=0"},
+		{ChoiceT[int]{Item: 42, Weight: 7}, "item=42 weight=7"},
+		{ChoiceT[region]{Item: "eu", Weight: 1}, "item=region eu weight=1"},
+	}
+	for _, tc := range cases {
+		if got := tc.choice.String(); got != tc.want {
+			t.Errorf("String() = %q, want %q", got, tc.want)
+		}
+	}
+	if got := fmt.Sprint(Choice{Item: "a", Weight: 3}); got != "item=a weight=3" {
+		t.Errorf("Sprint() = %q", got)
+	}
+}
+
+// region is an item implementing fmt.Stringer.
+type region string
+
+func (r region) String() string { return "region " + string(r) }
+
+func TestDump(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "c", Weight: 5},
+		Choice{Item: region("eu"), Weight: 1},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: 42, Weight: 2},
+	)
+	var buf bytes.Buffer
+	if err := chooser.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `4 choices, total weight 8
+index  item       weight  total  probability
+2      zero       0       0      0.000000
+1      region eu  1       1      0.125000
+3      42         2       3      0.250000
+0      c          5       8      0.625000
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Dump() =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := NewChooser().Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "0 choices, total weight 0\nerror: no choices\n"; buf.String() != want {
+		t.Errorf("Dump() = %q for empty chooser, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := NewChooser(Choice{Item: "a"}).Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want = `1 choices, total weight 0
+error: all choices have zero weight
+index  item  weight  total  probability
+0      a     0       0      -
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Dump() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDumpTruncated(t *testing.T) {
+	chooser := NewChooser(mockFrequencies(5000)...)
+	var buf bytes.Buffer
+	if err := chooser.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	// Summary, header, 10 rows, ellipsis, 10 rows and the omitted count.
+	if len(lines) != 2+dumpRows+2 {
+		t.Fatalf("Dump() wrote %d lines, want %d:\n%s", len(lines), 2+dumpRows+2, buf.String())
+	}
+	if want := "5000 choices, total weight 12502500"; lines[0] != want {
+		t.Errorf("first line = %q, want %q", lines[0], want)
+	}
+	if !strings.HasPrefix(lines[2], "0 ") || !strings.HasPrefix(lines[11], "9 ") {
+		t.Errorf("head rows = %q ... %q, want indices 0 to 9", lines[2], lines[11])
+	}
+	if !strings.HasPrefix(lines[12], "...") {
+		t.Errorf("line %q, want an ellipsis row", lines[12])
+	}
+	if !strings.HasPrefix(lines[13], "4990 ") || !strings.HasPrefix(lines[22], "4999 ") {
+		t.Errorf("tail rows = %q ... %q, want indices 4990 to 4999", lines[13], lines[22])
+	}
+	if want := "(4980 choices omitted)"; lines[len(lines)-1] != want {
+		t.Errorf("last line = %q, want %q", lines[len(lines)-1], want)
+	}
+
+	// Exactly dumpRows choices are listed in full.
+	buf.Reset()
+	NewChooser(mockFrequencies(dumpRows)...).Dump(&buf)
+	if n := strings.Count(buf.String(), "\n"); n != 2+dumpRows {
+		t.Errorf("Dump() wrote %d lines for %d choices, want %d", n, dumpRows, 2+dumpRows)
+	}
+}
+
+func TestDumpWriteError(t *testing.T) {
+	chooser := NewChooser(mockFrequencies(100)...)
+	var buf bytes.Buffer
+	chooser.Dump(&buf)
+	// Fail in the summary, the table and the omitted count.
+	for _, n := range []int{0, 40, buf.Len() - 1} {
+		w := &failingWriter{n: n}
+		if err := chooser.Dump(w); !errors.Is(err, errShortWrite) {
+			t.Errorf("Dump() after %d bytes error = %v, want %v", n, err, errShortWrite)
+		}
+	}
+}
+
+var errShortWrite = errors.New("short write")
+
+// failingWriter accepts n bytes and then fails every write.
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errShortWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
@@ -0,0 +1,123 @@
+package weightedrand
+
+import (
+	"errors"
+	"math/bits"
+	"math/rand"
+)
+
+// A DynamicChooserT holds many possible ChoiceT[T] whose weights may change
+// frequently. Its cumulative totals are kept in a Fenwick (binary indexed)
+// tree, so that both UpdateWeight and Pick take O(log n) time, where a ChooserT
+// would need an O(n) rebuild for every change.
+//
+// Choices keep the order they were passed in, which is the index used by
+// UpdateWeight and Weight. A DynamicChooserT is not safe for concurrent use.
+type DynamicChooserT[T any] struct {
+	data  []ChoiceT[T]
+	tree  []uint64 // 1-based Fenwick tree over the weights in data
+	total uint64
+}
+
+// A DynamicChooser is a DynamicChooserT over untyped items.
+type DynamicChooser = DynamicChooserT[interface{}]
+
+// NewDynamicChooserT initializes a new DynamicChooserT consisting of the
+// possible ChoiceT[T], in O(n) time. An error is returned if the weights sum to
+// more than the largest uint64.
+func NewDynamicChooserT[T any](cs ...ChoiceT[T]) (DynamicChooserT[T], error) {
+	data := make([]ChoiceT[T], len(cs))
+	copy(data, cs)
+	tree := make([]uint64, len(data)+1)
+	var total uint64
+	for i, c := range data {
+		if uint64(c.Weight) > maxTotal-total {
+			return DynamicChooserT[T]{}, ErrWeightOverflow
+		}
+		total += uint64(c.Weight)
+		tree[i+1] += uint64(c.Weight)
+		if j := (i + 1) + (i+1)&-(i+1); j < len(tree) {
+			tree[j] += tree[i+1]
+		}
+	}
+	return DynamicChooserT[T]{data: data, tree: tree, total: total}, nil
+}
+
+// NewDynamicChooser initializes a new DynamicChooser consisting of the possible
+// Choices, in O(n) time. An error is returned if the weights sum to more than
+// the largest uint64.
+func NewDynamicChooser(cs ...Choice) (DynamicChooser, error) {
+	return NewDynamicChooserT(cs...)
+}
+
+// UpdateWeight sets the weight of the i'th choice to w in O(log n) time. An
+// error is returned if i is out of range or the new weights would sum to more
+// than the largest uint64, in which case the DynamicChooser is unchanged.
+func (chs *DynamicChooserT[T]) UpdateWeight(i int, w uint) error {
+	if i < 0 || i >= len(chs.data) {
+		return errors.New("error: index out of range")
+	}
+	old := uint64(chs.data[i].Weight)
+	if uint64(w) > maxTotal-(chs.total-old) {
+		return ErrWeightOverflow
+	}
+	// The difference wraps around to subtract when the weight falls.
+	delta := uint64(w) - old
+	chs.data[i].Weight = w
+	chs.total += delta
+	for j := i + 1; j < len(chs.tree); j += j & -j {
+		chs.tree[j] += delta
+	}
+	return nil
+}
+
+// Weight returns the current weight of the i'th choice.
+func (chs DynamicChooserT[T]) Weight(i int) uint {
+	return chs.data[i].Weight
+}
+
+// Pick returns a single weighted random Choice.Item from the DynamicChooser.
+func (chs DynamicChooserT[T]) Pick() (T, error) {
+	return chs.PickSource(nil)
+}
+
+// PickSource returns a single weighted random Choice.Item from the
+// DynamicChooser, drawing from rs. A nil rs falls back to the global source in
+// math/rand.
+func (chs DynamicChooserT[T]) PickSource(rs *rand.Rand) (T, error) {
+	if err := chs.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+	return chs.data[chs.search(uint64n(fromRand(rs), chs.total)+1)].Item, nil
+}
+
+// search descends the tree to find the smallest index whose cumulative total
+// is at least r.
+func (chs DynamicChooserT[T]) search(r uint64) int {
+	pos := 0
+	for step := 1 << (bits.Len(uint(len(chs.data))) - 1); step > 0; step >>= 1 {
+		if next := pos + step; next < len(chs.tree) && chs.tree[next] < r {
+			pos = next
+			r -= chs.tree[next]
+		}
+	}
+	return pos
+}
+
+// Err returns the reason the DynamicChooser cannot be picked from, or nil if
+// it is usable.
+func (chs DynamicChooserT[T]) Err() error {
+	if len(chs.data) == 0 {
+		return ErrNoChoices
+	}
+	if chs.total == 0 {
+		return ErrAllZeroWeights
+	}
+	return nil
+}
+
+// Len returns the number of choices held by the DynamicChooser.
+func (chs DynamicChooserT[T]) Len() int {
+	return len(chs.data)
+}
@@ -0,0 +1,151 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestDynamicChooser(t *testing.T) {
+	choices := make([]Choice, 37)
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(rand.Intn(10))}
+	}
+	chooser, err := NewDynamicChooser(choices...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != len(choices) {
+		t.Errorf("Len() = %d, want %d", chooser.Len(), len(choices))
+	}
+	rs := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 5; round++ {
+		// Compare against a freshly built Chooser with the same weights.
+		weights := make([]Choice, len(choices))
+		for i := range weights {
+			weights[i] = Choice{Item: i, Weight: chooser.Weight(i)}
+		}
+		reference := NewChooserWithRand(rand.New(rand.NewSource(2)), weights...)
+
+		const n = 100000
+		got, want := make([]int, len(choices)), make([]int, len(choices))
+		for i := 0; i < n; i++ {
+			a, err := chooser.PickSource(rs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _ := reference.Pick()
+			got[a.(int)]++
+			want[b.(int)]++
+		}
+		for i := range got {
+			if chooser.Weight(i) == 0 && got[i] != 0 {
+				t.Errorf("round %d: zero weight choice %d picked %d times", round, i, got[i])
+			}
+			if diff := float64(got[i]-want[i]) / n; diff < -0.01 || diff > 0.01 {
+				t.Errorf("round %d: choice %d picked %d times, reference %d", round, i, got[i], want[i])
+			}
+		}
+
+		for j := 0; j < 10; j++ {
+			if err := chooser.UpdateWeight(rand.Intn(len(choices)), uint(rand.Intn(20))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestDynamicChooserErrors(t *testing.T) {
+	chooser, err := NewDynamicChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{-1, 2} {
+		if err := chooser.UpdateWeight(i, 1); err == nil {
+			t.Errorf("UpdateWeight(%d) expected error", i)
+		}
+	}
+	if is64Bit {
+		if err := chooser.UpdateWeight(1, ^uint(0)); err != ErrWeightOverflow {
+			t.Errorf("UpdateWeight overflow error = %v, want %v", err, ErrWeightOverflow)
+		}
+		if chooser.Weight(1) != 0 {
+			t.Error("failed UpdateWeight changed the weight")
+		}
+	}
+	if err := chooser.UpdateWeight(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chooser.Pick(); err != ErrAllZeroWeights {
+		t.Errorf("Pick() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if err := chooser.UpdateWeight(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if item, err := chooser.Pick(); err != nil || item != "b" {
+		t.Errorf("Pick() = %v, %v; want b", item, err)
+	}
+
+	empty, _ := NewDynamicChooser()
+	if _, err := empty.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+	// Totals are 64 bits wide on every platform, as for a Chooser.
+	wide, err := NewDynamicChooser(Choice{Item: "a", Weight: uint(maxInt)}, Choice{Item: "b", Weight: uint(maxInt)})
+	if err != nil {
+		t.Fatalf("NewDynamicChooser error = %v for weights summing past the largest int", err)
+	}
+	if _, err := wide.Pick(); err != nil {
+		t.Error(err)
+	}
+	if is64Bit {
+		if _, err := NewDynamicChooser(Choice{Weight: ^uint(0)}, Choice{Weight: 1}); err != ErrWeightOverflow {
+			t.Errorf("NewDynamicChooser error = %v, want %v", err, ErrWeightOverflow)
+		}
+	}
+}
+
+// BenchmarkDynamicChooser runs a mixed workload of one weight update per ten
+// picks over 100,000 choices, against rebuilding a Chooser on every update.
+func BenchmarkDynamicChooser(b *testing.B) {
+	const n = 100000
+	choices := mockChoices(n)
+
+	b.Run("Dynamic", func(b *testing.B) {
+		chooser, _ := NewDynamicChooser(choices...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if i%10 == 0 {
+				chooser.UpdateWeight(i%n, uint(i%7))
+			} else {
+				chooser.Pick()
+			}
+		}
+	})
+	b.Run("Rebuild", func(b *testing.B) {
+		chooser := NewChooser(choices...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if i%10 == 0 {
+				cs := chooser.original()
+				cs[i%n].Weight = uint(i % 7)
+				chooser.rebuild(cs)
+			} else {
+				chooser.Pick()
+			}
+		}
+	})
+}
+
+func BenchmarkDynamicPick(b *testing.B) {
+	for n := BMminChoices; n <= BMmaxChoices; n *= 10 {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			chooser, _ := NewDynamicChooser(mockChoices(n)...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				chooser.Pick()
+			}
+		})
+	}
+}
@@ -5,24 +5,28 @@ import (
 	"math/rand"
 	"time"
 
-	wr "github.com/mroth/weightedrand"
+	wr "github.com/yrh79/weightedrand"
 )
 
 func main() {
 	rand.Seed(time.Now().UTC().UnixNano()) // always seed random!
 
-	c := wr.NewChooser(
-		wr.Choice{Item: '🍆', Weight: 0},
-		wr.Choice{Item: '🍋', Weight: 1},
-		wr.Choice{Item: '🍊', Weight: 1},
-		wr.Choice{Item: '🍉', Weight: 3},
-		wr.Choice{Item: '🥑', Weight: 5},
+	c := wr.NewChooserT(
+		wr.ChoiceT[rune]{Item: '🍆', Weight: 0},
+		wr.ChoiceT[rune]{Item: '🍋', Weight: 1},
+		wr.ChoiceT[rune]{Item: '🍊', Weight: 1},
+		wr.ChoiceT[rune]{Item: '🍉', Weight: 3},
+		wr.ChoiceT[rune]{Item: '🥑', Weight: 5},
 	)
 
 	/* Let's pick a bunch of fruits so we can see the distribution in action! */
 	fruits := make([]rune, 40*18)
 	for i := 0; i < len(fruits); i++ {
-		fruits[i] = c.Pick().(rune)
+		f, err := c.Pick()
+		if err != nil {
+			panic(err)
+		}
+		fruits[i] = f
 	}
 	fmt.Println(string(fruits))
 
@@ -0,0 +1,41 @@
+package weightedrand
+
+import "errors"
+
+var errOutOfRange = errors.New("error: random value out of range")
+
+// PickFromFloat returns the Choice.Item selected by the random value r, which
+// must be in [0,1). No random numbers are drawn, so the result is a pure
+// function of r: a uniformly distributed r yields a weighted random item, with
+// the same selection Pick would make had its draw been
+// floor(r * TotalWeight()).
+func (chs ChooserT[T]) PickFromFloat(r float64) (T, error) {
+	var zero T
+	if err := chs.Err(); err != nil {
+		return zero, err
+	}
+	if !(r >= 0 && r < 1) {
+		return zero, errOutOfRange
+	}
+	v := uint64(r * float64(chs.max))
+	if v >= chs.max {
+		// Rounding can carry r just below 1 up to the total.
+		v = chs.max - 1
+	}
+	return chs.data[chs.search(v+1)].Item, nil
+}
+
+// PickFromInt returns the Choice.Item selected by the random value r, which
+// must be in [0,TotalWeight()). No random numbers are drawn: r selects the
+// first choice, in the Chooser's sorted order, whose cumulative weight exceeds
+// r, exactly as Pick does with its own draw.
+func (chs ChooserT[T]) PickFromInt(r uint64) (T, error) {
+	var zero T
+	if err := chs.Err(); err != nil {
+		return zero, err
+	}
+	if r >= chs.max {
+		return zero, errOutOfRange
+	}
+	return chs.data[chs.search(r+1)].Item, nil
+}
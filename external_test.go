@@ -0,0 +1,84 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPickFromInt(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 2},
+		Choice{Item: "c", Weight: 3},
+	) // totals: 0, 1, 3, 6
+	cases := []struct {
+		r    uint64
+		want string
+	}{
+		{0, "a"},
+		{1, "b"}, // exactly on the boundary of a's total
+		{2, "b"},
+		{3, "c"}, // exactly on the boundary of b's total
+		{5, "c"},
+	}
+	for _, tc := range cases {
+		if got, err := chooser.PickFromInt(tc.r); err != nil || got != tc.want {
+			t.Errorf("PickFromInt(%d) = %v, %v; want %v", tc.r, got, err, tc.want)
+		}
+	}
+	for _, r := range []uint64{6, math.MaxUint64} {
+		if _, err := chooser.PickFromInt(r); err != errOutOfRange {
+			t.Errorf("PickFromInt(%d) error = %v, want %v", r, err, errOutOfRange)
+		}
+	}
+	if _, err := NewChooser().PickFromInt(0); err != ErrNoChoices {
+		t.Errorf("got error %v, want %v", err, ErrNoChoices)
+	}
+
+	// Pick agrees with PickFromInt fed the same draws.
+	rs1, rs2 := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a, _ := chooser.PickSource(rs1)
+		b, _ := chooser.PickFromInt(uint64(rs2.Intn(6)))
+		if a != b {
+			t.Fatalf("draw %d: Pick() = %v, PickFromInt() = %v", i, a, b)
+		}
+	}
+}
+
+func TestPickFromFloat(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 3},
+		Choice{Item: "zero", Weight: 0},
+	) // sorted totals: 0, 1, 4
+	cases := []struct {
+		r    float64
+		want string
+	}{
+		{0, "a"},
+		{0.2499, "a"},
+		{0.25, "b"}, // exactly on the boundary
+		{0.5, "b"},
+		{math.Nextafter(1, 0), "b"},
+	}
+	for _, tc := range cases {
+		if got, err := chooser.PickFromFloat(tc.r); err != nil || got != tc.want {
+			t.Errorf("PickFromFloat(%v) = %v, %v; want %v", tc.r, got, err, tc.want)
+		}
+	}
+	for _, r := range []float64{-0.1, 1, 2, math.NaN(), math.Inf(1)} {
+		if _, err := chooser.PickFromFloat(r); err != errOutOfRange {
+			t.Errorf("PickFromFloat(%v) error = %v, want %v", r, err, errOutOfRange)
+		}
+	}
+
+	// A value so close to 1 that r*total rounds up must still select the last
+	// bucket.
+	big := NewChooser(Choice{Item: "x", Weight: 1}, Choice{Item: "y", Weight: uint(maxInt) - 1})
+	if got, err := big.PickFromFloat(math.Nextafter(1, 0)); err != nil || got != "y" {
+		t.Errorf("PickFromFloat(1-ε) = %v, %v; want y", got, err)
+	}
+}
@@ -0,0 +1,95 @@
+package weightedrand
+
+// pickWhereAttempts is the number of picks PickWhere tries before giving up on
+// rejection sampling and choosing among the accepted choices directly.
+const pickWhereAttempts = 16
+
+// Filter returns a new Chooser holding only those choices for which keep
+// returns true, in their original order. The result keeps the rand source of
+// chs, and the options kept when chs is mutated, but shares no storage with
+// it. If keep rejects every choice, the result is empty and ErrNoChoices is
+// returned with it.
+func (chs ChooserT[T]) Filter(keep func(ChoiceT[T]) bool) (ChooserT[T], error) {
+	var cs []ChoiceT[T]
+	for _, c := range chs.original() {
+		if keep(c) {
+			cs = append(cs, c)
+		}
+	}
+	f := chs.derive(buildChooser(cs, false))
+	return f, f.Err()
+}
+
+// PickWhere returns a single weighted random Choice.Item from among the
+// choices for which keep returns true, distributed as if picked from
+// Filter(keep) but without building a new Chooser.
+//
+// It first picks from the whole Chooser, rejecting choices that keep does not
+// accept, which is fast when most of the weight is accepted. After
+// pickWhereAttempts rejections it falls back to calling keep on every choice
+// and picking among those accepted, so it always terminates. keep must
+// therefore give the same answer for a choice each time; ErrNoChoices is
+// returned if it accepts no choice of nonzero weight.
+func (chs ChooserT[T]) PickWhere(keep func(ChoiceT[T]) bool) (T, error) {
+	var zero T
+	if err := chs.Err(); err != nil {
+		return zero, err
+	}
+	for attempt := 0; attempt < pickWhereAttempts; attempt++ {
+		if c := chs.data[chs.pick(chs.rng)]; keep(c) {
+			return c.Item, nil
+		}
+	}
+
+	var kept []int
+	var total uint64
+	for i, c := range chs.data {
+		if c.Weight > 0 && keep(c) {
+			kept = append(kept, i)
+			total += uint64(c.Weight)
+		}
+	}
+	i, ok := chs.pickAmong(kept, total)
+	if !ok {
+		return zero, ErrNoChoices
+	}
+	return chs.data[i].Item, nil
+}
+
+// pickAmong returns the index into chs.data of a weighted random choice from
+// among those at the indices in kept, whose weights sum to total. It reports
+// false if total is zero.
+func (chs ChooserT[T]) pickAmong(kept []int, total uint64) (int, bool) {
+	if total == 0 {
+		return -1, false
+	}
+	r := uint64n(chs.rng, total)
+	for _, i := range kept {
+		w := uint64(chs.data[i].Weight)
+		if r < w {
+			return i, true
+		}
+		r -= w
+	}
+	return kept[len(kept)-1], true
+}
+
+// PickExcluding returns a single weighted random Choice.Item from among the
+// choices whose Item equals none of exclude, as when retrying after the items
+// already tried have failed. The remaining choices keep their relative
+// weights, and ErrNoChoices is returned if none of nonzero weight remain. Like
+// PickWhere, it needs no new Chooser, and falls back to scanning every choice
+// when the excluded items hold most of the weight.
+//
+// Items are matched by ==, so this panics if T is an interface type holding an
+// item that is not comparable.
+func (chs ChooserT[T]) PickExcluding(exclude ...T) (T, error) {
+	return chs.PickWhere(func(c ChoiceT[T]) bool {
+		for _, item := range exclude {
+			if equalItems(c.Item, item) {
+				return false
+			}
+		}
+		return true
+	})
+}
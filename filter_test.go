@@ -0,0 +1,140 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// inRegion returns a predicate keeping the choices whose item starts with
+// region.
+func inRegion(region string) func(Choice) bool {
+	return func(c Choice) bool {
+		return c.Item.(string)[:len(region)] == region
+	}
+}
+
+func backends() Chooser {
+	return NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "us-1", Weight: 1},
+		Choice{Item: "eu-1", Weight: 50},
+		Choice{Item: "us-2", Weight: 3},
+		Choice{Item: "eu-2", Weight: 46},
+	)
+}
+
+func TestFilter(t *testing.T) {
+	chooser := backends()
+	us, err := chooser.Filter(inRegion("us"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"us-1", "us-2"}; !reflect.DeepEqual(us.Items(), want) {
+		t.Errorf("Items() = %v, want %v", us.Items(), want)
+	}
+	if us.rng != chooser.rng {
+		t.Error("Filter() did not keep the rand source")
+	}
+	assertShares(t, pickShares(t, us, 20000), map[interface{}]float64{"us-1": 0.25, "us-2": 0.75})
+	if chooser.Len() != 4 {
+		t.Errorf("Len() = %d after Filter, want 4", chooser.Len())
+	}
+
+	if _, err := chooser.Filter(inRegion("ap")); err != ErrNoChoices {
+		t.Errorf("Filter() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	configured := configuredChooser(t, chooser.Choices()...)
+	f, err := configured.Filter(inRegion("us"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Filter()", f, configured)
+}
+
+func TestPickWhere(t *testing.T) {
+	chooser := backends()
+	eu := inRegion("eu")
+	calls := 0
+	counting := func(c Choice) bool {
+		calls++
+		return eu(c)
+	}
+	// eu holds 96% of the weight, so rejection sampling almost always
+	// succeeds at once.
+	const n = 20000
+	for i := 0; i < n; i++ {
+		item, err := chooser.PickWhere(counting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !eu(Choice{Item: item}) {
+			t.Fatalf("PickWhere() = %v, want an eu backend", item)
+		}
+	}
+	if calls > n*11/10 {
+		t.Errorf("keep called %d times for %d picks, want rejection sampling", calls, n)
+	}
+
+	// us holds 4% of the weight, so many picks exhaust their attempts and
+	// fall back to scanning every choice.
+	us := inRegion("us")
+	shares := make(map[interface{}]float64)
+	fallbacks := 0
+	for i := 0; i < n; i++ {
+		calls = 0
+		item, err := chooser.PickWhere(func(c Choice) bool {
+			calls++
+			return us(c)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls > pickWhereAttempts {
+			if calls != pickWhereAttempts+chooser.Len() {
+				t.Fatalf("keep called %d times, want %d attempts then %d in the fallback", calls, pickWhereAttempts, chooser.Len())
+			}
+			fallbacks++
+		}
+		shares[item] += 1.0 / n
+	}
+	if fallbacks == 0 {
+		t.Error("PickWhere() never fell back to scanning")
+	}
+	assertShares(t, shares, map[interface{}]float64{"us-1": 0.25, "us-2": 0.75})
+
+	if _, err := chooser.PickWhere(inRegion("ap")); err != ErrNoChoices {
+		t.Errorf("PickWhere() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewChooser().PickWhere(eu); err != ErrNoChoices {
+		t.Errorf("PickWhere() on empty chooser error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func TestPickExcluding(t *testing.T) {
+	chooser := backends()
+	const n = 20000
+	for _, tc := range []struct {
+		exclude []interface{}
+		want    map[interface{}]float64
+	}{
+		{nil, map[interface{}]float64{"us-1": 0.01, "eu-1": 0.5, "us-2": 0.03, "eu-2": 0.46}},
+		{[]interface{}{"eu-1"}, map[interface{}]float64{"us-1": 0.02, "us-2": 0.06, "eu-2": 0.92}},
+		{[]interface{}{"eu-1", "eu-2"}, map[interface{}]float64{"us-1": 0.25, "us-2": 0.75}},
+		{[]interface{}{"eu-1", "eu-2", "us-2", "missing"}, map[interface{}]float64{"us-1": 1}},
+	} {
+		shares := make(map[interface{}]float64)
+		for i := 0; i < n; i++ {
+			item, err := chooser.PickExcluding(tc.exclude...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shares[item] += 1.0 / n
+		}
+		assertShares(t, shares, tc.want)
+	}
+
+	if _, err := chooser.PickExcluding("us-1", "eu-1", "us-2", "eu-2"); err != ErrNoChoices {
+		t.Errorf("PickExcluding(everything) error = %v, want %v", err, ErrNoChoices)
+	}
+}
@@ -0,0 +1,110 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// FloatChoiceT is a generic wrapper that can be used to add floating point
+// weights for any object of type T.
+type FloatChoiceT[T any] struct {
+	Item   T
+	Weight float64
+}
+
+// FloatChoice is a wrapper that can be used to add floating point weights for
+// any object
+type FloatChoice = FloatChoiceT[interface{}]
+
+// A FloatChooserT is like a ChooserT, but for choices with floating point
+// weights, such as scores or probabilities that do not scale well to integers.
+type FloatChooserT[T any] struct {
+	data   []FloatChoiceT[T]
+	totals []float64
+	max    float64
+	last   int   // index of the last choice with a nonzero weight
+	err    error // ErrNoChoices or ErrAllZeroWeights if nothing can be picked
+}
+
+// A FloatChooser is a FloatChooserT over untyped items.
+type FloatChooser = FloatChooserT[interface{}]
+
+// NewFloatChooserT initializes a new FloatChooserT consisting of the possible
+// FloatChoiceT[T]. An error is returned if any weight is negative, NaN or
+// infinite, or if the weights sum to more than the largest float64.
+func NewFloatChooserT[T any](cs ...FloatChoiceT[T]) (FloatChooserT[T], error) {
+	for i, c := range cs {
+		if c.Weight < 0 || math.IsNaN(c.Weight) || math.IsInf(c.Weight, 0) {
+			return FloatChooserT[T]{}, fmt.Errorf("%w %v for choice %d", ErrInvalidWeight, c.Weight, i)
+		}
+	}
+
+	// Summing from smallest to largest keeps the accumulated error down when
+	// the weights span many orders of magnitude.
+	data := make([]FloatChoiceT[T], len(cs))
+	copy(data, cs)
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Weight < data[j].Weight
+	})
+	totals := make([]float64, len(data))
+	runningTotal := 0.0
+	last := -1
+	for i, c := range data {
+		runningTotal += c.Weight
+		totals[i] = runningTotal
+		if c.Weight > 0 {
+			last = i
+		}
+	}
+	if math.IsInf(runningTotal, 0) {
+		return FloatChooserT[T]{}, fmt.Errorf("%w float64", ErrWeightOverflow)
+	}
+	chs := FloatChooserT[T]{data: data, totals: totals, max: runningTotal, last: last}
+	switch {
+	case len(data) == 0:
+		chs.err = ErrNoChoices
+	case runningTotal == 0:
+		chs.err = ErrAllZeroWeights
+	}
+	return chs, nil
+}
+
+// NewFloatChooser initializes a new FloatChooser consisting of the possible
+// FloatChoices. An error is returned if any weight is negative, NaN or
+// infinite, or if the weights sum to more than the largest float64.
+func NewFloatChooser(cs ...FloatChoice) (FloatChooser, error) {
+	return NewFloatChooserT(cs...)
+}
+
+// Pick returns a single weighted random FloatChoice.Item from the
+// FloatChooser.
+func (chs FloatChooserT[T]) Pick() (T, error) {
+	return chs.PickSource(nil)
+}
+
+// PickSource returns a single weighted random FloatChoice.Item from the
+// FloatChooser, drawing from rs. A nil rs falls back to the global source in
+// math/rand.
+func (chs FloatChooserT[T]) PickSource(rs *rand.Rand) (T, error) {
+	if chs.err != nil {
+		var zero T
+		return zero, chs.err
+	}
+	r := float64n(fromRand(rs)) * chs.max
+	i := sort.Search(len(chs.totals), func(i int) bool {
+		return chs.totals[i] > r
+	})
+	// Rounding in the multiplication can land r on the total itself, which
+	// belongs to the last bucket that has any weight.
+	if i > chs.last {
+		i = chs.last
+	}
+	return chs.data[i].Item, nil
+}
+
+// Len returns the number of choices held by the FloatChooser.
+func (chs FloatChooserT[T]) Len() int {
+	return len(chs.data)
+}
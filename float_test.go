@@ -0,0 +1,95 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFloatChooser(t *testing.T) {
+	choices := []FloatChoice{
+		{Item: "zero", Weight: 0},
+		{Item: "tiny", Weight: 1e-12},
+		{Item: "small", Weight: 0.037},
+		{Item: "medium", Weight: 3.7},
+		{Item: "large", Weight: 37e3},
+		{Item: "zero2", Weight: 0},
+	}
+	chooser, err := NewFloatChooser(choices...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != len(choices) {
+		t.Errorf("Len() = %d, want %d", chooser.Len(), len(choices))
+	}
+
+	total := 0.0
+	for _, c := range choices {
+		total += c.Weight
+	}
+	const n = 1000000
+	rs := rand.New(rand.NewSource(1))
+	counts := make(map[interface{}]int)
+	for i := 0; i < n; i++ {
+		item, err := chooser.PickSource(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[item]++
+	}
+	if counts["zero"]+counts["zero2"] != 0 {
+		t.Errorf("zero weight items picked: %v", counts)
+	}
+	if counts["tiny"] != 0 {
+		t.Errorf("1e-12 weight item picked %d times in %d", counts["tiny"], n)
+	}
+	for _, c := range choices[2:5] {
+		want := c.Weight / total
+		if got := float64(counts[c.Item]) / n; math.Abs(got-want) > 0.0005+want*0.05 {
+			t.Errorf("%v picked %.5f of the time, want %.5f", c.Item, got, want)
+		}
+	}
+}
+
+// TestFloatChooserLastBucket ensures a random draw that rounds up to the total
+// still lands on the last choice with any weight.
+func TestFloatChooserLastBucket(t *testing.T) {
+	chooser, err := NewFloatChooser(
+		FloatChoice{Item: "a", Weight: 0.1},
+		FloatChoice{Item: "b", Weight: 0.2},
+		FloatChoice{Item: "zero", Weight: 0},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chooser.max = chooser.totals[len(chooser.totals)-1] * 2 // force r >= total
+	for i := 0; i < 100; i++ {
+		if item, _ := chooser.Pick(); item == "zero" {
+			t.Fatal("picked zero weight item")
+		}
+	}
+}
+
+func TestFloatChooserInvalid(t *testing.T) {
+	for _, w := range []float64{-1, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := NewFloatChooser(FloatChoice{Item: 1, Weight: 1}, FloatChoice{Item: 2, Weight: w}); err == nil {
+			t.Errorf("expected error for weight %v", w)
+		}
+	}
+	if _, err := NewFloatChooser(
+		FloatChoice{Weight: math.MaxFloat64},
+		FloatChoice{Weight: math.MaxFloat64},
+	); err == nil {
+		t.Error("expected error for total overflowing float64")
+	}
+
+	for _, cs := range [][]FloatChoice{nil, {{Item: 1, Weight: 0}}} {
+		chooser, err := NewFloatChooser(cs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := chooser.Pick(); err == nil {
+			t.Errorf("expected error picking from %v", cs)
+		}
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/yrh79/weightedrand
 
-go 1.10
+go 1.18
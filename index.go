@@ -0,0 +1,76 @@
+package weightedrand
+
+import "math/rand"
+
+// An IndexChooser picks weighted random indices into a list of weights, for
+// callers that keep their items elsewhere, such as in a parallel slice. It
+// stores nothing but the cumulative totals, 8 bytes per weight, where a
+// Chooser also holds every item and its weight, and, unless the choices were
+// presorted, their original positions.
+//
+// Totals are kept in the order of the weights, so unlike a Chooser's, picks
+// for a given seed differ from those of NewChooser over the same weights.
+// Concurrency follows the same rules as for a Chooser.
+type IndexChooser struct {
+	totals []uint64
+	max    uint64
+	err    error
+	rng    source
+}
+
+// NewIndexChooser initializes a new IndexChooser picking indices into
+// weights, configured by opts; only the options choosing a rand source apply.
+// The IndexChooser does not retain weights. An error is returned for any other
+// option or an invalid combination of options, and otherwise for any problem
+// NewChooserTErr would report, in which case Pick reports it too.
+func NewIndexChooser(weights []uint, opts ...Option) (IndexChooser, error) {
+	o, err := buildOptions(opts, 0)
+	if err != nil {
+		return IndexChooser{}, err
+	}
+	ic := IndexChooser{totals: make([]uint64, len(weights)), rng: o.rng}
+	if len(weights) == 0 {
+		ic.err = ErrNoChoices
+		return ic, ic.err
+	}
+	ic.max, ic.err = accumulate(ic.totals, func(i int) uint { return weights[i] })
+	return ic, ic.err
+}
+
+// Pick returns the index of a single weighted random weight.
+func (ic IndexChooser) Pick() (int, error) {
+	return ic.pickFrom(ic.rng)
+}
+
+// PickSource returns the index of a single weighted random weight, drawing
+// from rs rather than the IndexChooser's own source. A nil rs falls back to
+// the global source in math/rand.
+func (ic IndexChooser) PickSource(rs *rand.Rand) (int, error) {
+	return ic.pickFrom(fromRand(rs))
+}
+
+// pickFrom returns the index of a single weighted random weight drawn from rs.
+func (ic IndexChooser) pickFrom(rs source) (int, error) {
+	if ic.err != nil {
+		return -1, ic.err
+	}
+	r := uint64n(rs, ic.max) + 1
+	return searchTotals(ic.totals, r), nil
+}
+
+// Err returns the reason the IndexChooser cannot be picked from, or nil if it
+// is usable. Pick returns this same error.
+func (ic IndexChooser) Err() error {
+	return ic.err
+}
+
+// Len returns the number of weights the IndexChooser picks among.
+func (ic IndexChooser) Len() int {
+	return len(ic.totals)
+}
+
+// TotalWeight returns the sum of the weights, or 0 if the IndexChooser cannot
+// be picked from.
+func (ic IndexChooser) TotalWeight() uint64 {
+	return ic.max
+}
@@ -0,0 +1,130 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestIndexChooser(t *testing.T) {
+	weights := []uint{3, 0, 1, 6}
+	ic, err := NewIndexChooser(weights, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ic.Len() != 4 || ic.TotalWeight() != 10 {
+		t.Errorf("Len(), TotalWeight() = %d, %d; want 4, 10", ic.Len(), ic.TotalWeight())
+	}
+	weights[0] = 100 // not retained
+
+	const n = 20000
+	var counts [4]int
+	for i := 0; i < n; i++ {
+		idx, err := ic.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[idx]++
+	}
+	for i, p := range []float64{0.3, 0, 0.1, 0.6} {
+		if got := float64(counts[i]) / n; got < p-0.02 || got > p+0.02 {
+			t.Errorf("index %d picked %.3f of the time, want %.3f", i, got, p)
+		}
+	}
+	if idx, err := ic.PickSource(rand.New(rand.NewSource(1))); err != nil || idx < 0 || idx >= 4 {
+		t.Errorf("PickSource() = %d, %v", idx, err)
+	}
+}
+
+func TestIndexChooserErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		weights []uint
+		err     error
+	}{
+		{"empty", nil, ErrNoChoices},
+		{"all zero", []uint{0, 0}, ErrAllZeroWeights},
+		{"overflow", []uint{^uint(0), 1}, ErrWeightOverflow},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == ErrWeightOverflow && !is64Bit {
+				t.Skip("weights cannot overflow int64 with a 32-bit uint")
+			}
+			ic, err := NewIndexChooser(tc.weights)
+			if err != tc.err {
+				t.Errorf("NewIndexChooser() error = %v, want %v", err, tc.err)
+			}
+			if idx, err := ic.Pick(); idx != -1 || err != tc.err {
+				t.Errorf("Pick() = %d, %v; want -1, %v", idx, err, tc.err)
+			}
+		})
+	}
+	if _, err := NewIndexChooser([]uint{1}, WithSeed(1), WithParallelRand()); err == nil {
+		t.Error("expected error for conflicting options")
+	}
+}
+
+// BenchmarkIndexChooserMemory compares the heap retained by an IndexChooser
+// against a Chooser over the same weights, reported per choice.
+func BenchmarkIndexChooserMemory(b *testing.B) {
+	const n = 1000000
+	weights := make([]uint, n)
+	choices := make([]Choice, n)
+	for i := range weights {
+		weights[i] = uint(rand.Intn(1000))
+		choices[i] = Choice{Item: i, Weight: weights[i]}
+	}
+	b.Run("Index", func(b *testing.B) {
+		b.ReportAllocs()
+		var ic IndexChooser
+		retained := heapRetained(func() {
+			for i := 0; i < b.N; i++ {
+				ic, _ = NewIndexChooser(weights)
+			}
+		})
+		b.ReportMetric(float64(retained)/n, "retained-B/choice")
+		runtime.KeepAlive(ic)
+	})
+	b.Run("Chooser", func(b *testing.B) {
+		b.ReportAllocs()
+		var chs Chooser
+		retained := heapRetained(func() {
+			for i := 0; i < b.N; i++ {
+				chs = NewChooser(choices...)
+			}
+		})
+		b.ReportMetric(float64(retained)/n, "retained-B/choice")
+		runtime.KeepAlive(chs)
+	})
+}
+
+// heapRetained returns the growth of the live heap across a call to fn.
+func heapRetained(fn func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	if after.HeapAlloc < before.HeapAlloc {
+		return 0
+	}
+	return after.HeapAlloc - before.HeapAlloc
+}
+
+func BenchmarkIndexChooserPick(b *testing.B) {
+	for n := BMminChoices; n <= BMmaxChoices; n *= 10 {
+		weights := make([]uint, n)
+		for i := range weights {
+			weights[i] = uint(rand.Intn(1000) + 1)
+		}
+		ic, _ := NewIndexChooser(weights)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ic.Pick()
+			}
+		})
+	}
+}
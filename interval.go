@@ -0,0 +1,168 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// An Interval is a half-open range of int64 values, [Lo, Hi), with the weight
+// of its being picked by an IntervalChooser. Lo is included and Hi is not, so
+// the single value x is the Interval {Lo: x, Hi: x + 1}.
+type Interval struct {
+	Lo, Hi int64
+	Weight uint
+}
+
+// width returns the number of values in iv, which may be up to the largest
+// uint64 for an Interval spanning every int64 but the largest.
+func (iv Interval) width() uint64 {
+	return uint64(iv.Hi) - uint64(iv.Lo)
+}
+
+// An IntervalChooser picks random int64 values from a set of Intervals,
+// choosing an Interval by weight and then a value uniformly within it:
+//
+//	ic, err := weightedrand.NewIntervalChooser([]weightedrand.Interval{
+//		{Lo: 0, Hi: 100, Weight: 70},
+//		{Lo: 100, Hi: 1000, Weight: 25},
+//		{Lo: 1000, Hi: 10000, Weight: 5},
+//	})
+//
+// Intervals may overlap, in which case a value they share may be picked from
+// any of them; CheckDisjoint rejects such sets. Concurrency follows the same
+// rules as for a Chooser.
+type IntervalChooser struct {
+	intervals []Interval
+	totals    []uint64
+	max       uint64
+	byWidth   bool
+	err       error
+	rng       source
+}
+
+// NewIntervalChooser initializes a new IntervalChooser picking from intervals,
+// each chosen in proportion to its Weight, configured by opts; only the options
+// choosing a rand source apply. An error is returned for any other option, an
+// invalid combination of options, an Interval whose Lo is not below its Hi, and
+// otherwise for any problem NewChooserTErr would report with the weights, in
+// which case Pick reports it too.
+func NewIntervalChooser(intervals []Interval, opts ...Option) (IntervalChooser, error) {
+	return newIntervalChooser(intervals, false, opts)
+}
+
+// NewIntervalChooserByWidth initializes a new IntervalChooser picking from
+// intervals, each chosen in proportion to its width, Hi - Lo, so that every
+// value is equally likely but for those in more than one Interval; their
+// Weight fields are ignored. ErrWeightOverflow is reported if the widths sum
+// to more than the largest uint64. See NewIntervalChooser.
+func NewIntervalChooserByWidth(intervals []Interval, opts ...Option) (IntervalChooser, error) {
+	return newIntervalChooser(intervals, true, opts)
+}
+
+func newIntervalChooser(intervals []Interval, byWidth bool, opts []Option) (IntervalChooser, error) {
+	o, err := buildOptions(opts, 0)
+	if err != nil {
+		return IntervalChooser{}, err
+	}
+	for i, iv := range intervals {
+		if iv.Lo >= iv.Hi {
+			return IntervalChooser{}, fmt.Errorf("error: interval %d is [%d, %d), which is empty", i, iv.Lo, iv.Hi)
+		}
+	}
+	ic := IntervalChooser{
+		intervals: append([]Interval(nil), intervals...),
+		totals:    make([]uint64, len(intervals)),
+		byWidth:   byWidth,
+		rng:       o.rng,
+	}
+	switch {
+	case len(intervals) == 0:
+		ic.err = ErrNoChoices
+	case byWidth:
+		ic.max, ic.err = accumulateWidths(ic.totals, intervals)
+	default:
+		ic.max, ic.err = accumulate(ic.totals, func(i int) uint { return intervals[i].Weight })
+	}
+	return ic, ic.err
+}
+
+// accumulateWidths sets each of totals to the running sum of the widths of
+// intervals up to and including intervals[i], and returns their sum. It
+// reports ErrWeightOverflow if the sum exceeds maxTotal.
+func accumulateWidths(totals []uint64, intervals []Interval) (uint64, error) {
+	runningTotal := uint64(0)
+	for i, iv := range intervals {
+		w := iv.width()
+		if w > maxTotal-runningTotal {
+			return 0, ErrWeightOverflow
+		}
+		runningTotal += w
+		totals[i] = runningTotal
+	}
+	return runningTotal, nil
+}
+
+// CheckDisjoint returns an error naming two of intervals that share a value,
+// or nil if none do. Intervals are half-open, so {Lo: 0, Hi: 10} and
+// {Lo: 10, Hi: 20} are disjoint.
+func CheckDisjoint(intervals []Interval) error {
+	order := make([]int, len(intervals))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return intervals[order[a]].Lo < intervals[order[b]].Lo
+	})
+	for k := 1; k < len(order); k++ {
+		prev, next := order[k-1], order[k]
+		if intervals[next].Lo < intervals[prev].Hi {
+			if next < prev {
+				prev, next = next, prev
+			}
+			return fmt.Errorf("error: intervals %d and %d overlap", prev, next)
+		}
+	}
+	return nil
+}
+
+// Pick returns a single random value, from an Interval chosen at random.
+func (ic IntervalChooser) Pick() (int64, error) {
+	return ic.pickFrom(ic.rng)
+}
+
+// PickSource returns a single random value, drawing from rs rather than the
+// IntervalChooser's own source. A nil rs falls back to the global source in
+// math/rand.
+func (ic IntervalChooser) PickSource(rs *rand.Rand) (int64, error) {
+	return ic.pickFrom(fromRand(rs))
+}
+
+// pickFrom returns a single random value drawn from rs. Weighted by width, a
+// single draw picks both the Interval and the value within it.
+func (ic IntervalChooser) pickFrom(rs source) (int64, error) {
+	if ic.err != nil {
+		return 0, ic.err
+	}
+	r := uint64n(rs, ic.max)
+	i := searchTotals(ic.totals, r+1)
+	iv := ic.intervals[i]
+	var offset uint64
+	if ic.byWidth {
+		offset = r - (ic.totals[i] - iv.width())
+	} else {
+		offset = uint64n(rs, iv.width())
+	}
+	return int64(uint64(iv.Lo) + offset), nil
+}
+
+// Err returns the reason the IntervalChooser cannot be picked from, or nil if
+// it is usable. Pick returns this same error.
+func (ic IntervalChooser) Err() error {
+	return ic.err
+}
+
+// Len returns the number of Intervals the IntervalChooser picks from.
+func (ic IntervalChooser) Len() int {
+	return len(ic.intervals)
+}
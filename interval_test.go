@@ -0,0 +1,165 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestIntervalChooser(t *testing.T) {
+	intervals := []Interval{
+		{Lo: 0, Hi: 10, Weight: 70},
+		{Lo: 10, Hi: 12, Weight: 25},
+		{Lo: -5, Hi: -4, Weight: 5}, // the single value -5
+		{Lo: 100, Hi: 200, Weight: 0},
+	}
+	ic, err := NewIntervalChooser(intervals, WithRand(rand.New(rand.NewSource(1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ic.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", ic.Len())
+	}
+	const n = 200000
+	counts := make(map[int64]int)
+	for i := 0; i < n; i++ {
+		v, err := ic.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[v]++
+	}
+	if len(counts) != 13 {
+		t.Errorf("picked %d distinct values, want 13: %v", len(counts), counts)
+	}
+	for _, v := range []int64{10, 11, -5} {
+		if counts[v] == 0 {
+			t.Errorf("never picked %d, which is within an interval", v)
+		}
+	}
+	for _, v := range []int64{12, -4, -6} {
+		if counts[v] != 0 {
+			t.Errorf("picked %d, which is outside every interval, %d times", v, counts[v])
+		}
+	}
+
+	// Each interval is picked in proportion to its weight, and each value
+	// within it uniformly.
+	for _, iv := range intervals[:3] {
+		p := float64(iv.Weight) / 100
+		total := 0
+		for v := iv.Lo; v < iv.Hi; v++ {
+			total += counts[v]
+		}
+		if got := float64(total) / n; math.Abs(got-p) > 0.01 {
+			t.Errorf("interval [%d, %d) picked %.4f of the time, want %.2f", iv.Lo, iv.Hi, got, p)
+		}
+		each := float64(total) / float64(iv.Hi-iv.Lo)
+		for v := iv.Lo; v < iv.Hi; v++ {
+			if math.Abs(float64(counts[v])-each) > 5*math.Sqrt(each) {
+				t.Errorf("value %d picked %d times, want about %.0f", v, counts[v], each)
+			}
+		}
+	}
+
+	typed, _ := NewIntervalChooser(intervals[2:3])
+	if v, err := typed.PickSource(rand.New(rand.NewSource(2))); v != -5 || err != nil {
+		t.Errorf("PickSource() = %d, %v; want -5", v, err)
+	}
+}
+
+func TestIntervalChooserByWidth(t *testing.T) {
+	intervals := []Interval{{Lo: 0, Hi: 3, Weight: 1000}, {Lo: 10, Hi: 11}}
+	ic, err := NewIntervalChooserByWidth(intervals, WithRand(rand.New(rand.NewSource(3))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 40000
+	counts := make(map[int64]int)
+	for i := 0; i < n; i++ {
+		v, _ := ic.Pick()
+		counts[v]++
+	}
+	for _, v := range []int64{0, 1, 2, 10} {
+		if got := float64(counts[v]) / n; math.Abs(got-0.25) > 0.01 {
+			t.Errorf("value %d picked %.4f of the time, want 0.25", v, got)
+		}
+	}
+	if len(counts) != 4 {
+		t.Errorf("picked %v, want only 0, 1, 2 and 10", counts)
+	}
+}
+
+func TestIntervalChooserExtremes(t *testing.T) {
+	whole := []Interval{{Lo: math.MinInt64, Hi: math.MaxInt64, Weight: 1}}
+	for _, build := range []func([]Interval, ...Option) (IntervalChooser, error){NewIntervalChooser, NewIntervalChooserByWidth} {
+		ic, err := build(whole, WithSeed(4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		negative := false
+		for i := 0; i < 100; i++ {
+			v, _ := ic.Pick()
+			if v == math.MaxInt64 {
+				t.Fatal("picked MaxInt64, which is excluded")
+			}
+			negative = negative || v < 0
+		}
+		if !negative {
+			t.Error("never picked a negative value from the whole range")
+		}
+	}
+	top, _ := NewIntervalChooser([]Interval{{Lo: math.MaxInt64 - 1, Hi: math.MaxInt64, Weight: 1}})
+	if v, _ := top.Pick(); v != math.MaxInt64-1 {
+		t.Errorf("Pick() = %d, want MaxInt64-1", v)
+	}
+	twice := append(whole, whole...)
+	if _, err := NewIntervalChooserByWidth(twice); err != ErrWeightOverflow {
+		t.Errorf("NewIntervalChooserByWidth() error = %v, want %v", err, ErrWeightOverflow)
+	}
+}
+
+func TestIntervalChooserErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		intervals []Interval
+		want      string
+	}{
+		{"empty", nil, ErrNoChoices.Error()},
+		{"zero width", []Interval{{Lo: 0, Hi: 1, Weight: 1}, {Lo: 5, Hi: 5, Weight: 1}}, "error: interval 1 is [5, 5), which is empty"},
+		{"reversed", []Interval{{Lo: 3, Hi: -3, Weight: 1}}, "error: interval 0 is [3, -3), which is empty"},
+		{"all zero", []Interval{{Lo: 0, Hi: 1}}, ErrAllZeroWeights.Error()},
+	}
+	for _, tc := range cases {
+		ic, err := NewIntervalChooser(tc.intervals)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s: NewIntervalChooser() error = %v, want %q", tc.name, err, tc.want)
+		}
+		if tc.name != "empty" && tc.name != "all zero" {
+			continue
+		}
+		if _, perr := ic.Pick(); perr != err || ic.Err() != err {
+			t.Errorf("%s: Pick() error = %v, want %v", tc.name, perr, err)
+		}
+	}
+}
+
+func TestCheckDisjoint(t *testing.T) {
+	cases := []struct {
+		name      string
+		intervals []Interval
+		want      string
+	}{
+		{"none", nil, ""},
+		{"adjacent", []Interval{{Lo: 10, Hi: 20}, {Lo: 0, Hi: 10}, {Lo: 20, Hi: 21}}, ""},
+		{"overlap", []Interval{{Lo: 0, Hi: 10}, {Lo: 20, Hi: 30}, {Lo: 9, Hi: 11}}, "error: intervals 0 and 2 overlap"},
+		{"nested", []Interval{{Lo: 5, Hi: 6}, {Lo: 0, Hi: 100}}, "error: intervals 0 and 1 overlap"},
+		{"same point", []Interval{{Lo: 7, Hi: 8}, {Lo: 7, Hi: 8}}, "error: intervals 0 and 1 overlap"},
+	}
+	for _, tc := range cases {
+		err := CheckDisjoint(tc.intervals)
+		if (tc.want == "" && err != nil) || (tc.want != "" && (err == nil || err.Error() != tc.want)) {
+			t.Errorf("%s: CheckDisjoint() = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package weightedrand
+
+import "fmt"
+
+// IntChoiceT is a generic wrapper that can be used to add signed integer
+// weights, as computed by arithmetic that may go negative on bad input, for
+// any object of type T. Negative weights are rejected rather than wrapped into
+// huge unsigned ones.
+type IntChoiceT[T any] struct {
+	Item   T
+	Weight int
+}
+
+// IntChoice is a wrapper that can be used to add signed integer weights for
+// any object
+type IntChoice = IntChoiceT[interface{}]
+
+// NewChooserTFromIntChoices initializes a new ChooserT consisting of the
+// possible IntChoiceT[T]. An error wrapping ErrInvalidWeight, and giving the
+// index and value, is returned for the first negative weight. Zero weights are
+// kept but never picked, as with NewChooserT, and any other problem
+// NewChooserTErr would report is returned.
+func NewChooserTFromIntChoices[T any](cs ...IntChoiceT[T]) (ChooserT[T], error) {
+	choices := make([]ChoiceT[T], len(cs))
+	for i, c := range cs {
+		if c.Weight < 0 {
+			return ChooserT[T]{}, fmt.Errorf("%w %d for choice %d", ErrInvalidWeight, c.Weight, i)
+		}
+		choices[i] = ChoiceT[T]{Item: c.Item, Weight: uint(c.Weight)}
+	}
+	return NewChooserTOpts(choices, WithBorrowedInput())
+}
+
+// NewChooserFromIntChoices initializes a new Chooser consisting of the
+// possible IntChoices. See NewChooserTFromIntChoices.
+func NewChooserFromIntChoices(cs ...IntChoice) (Chooser, error) {
+	return NewChooserTFromIntChoices(cs...)
+}
+
+// NewChooserTFromInts initializes a new ChooserT whose choices are items,
+// each weighted by the weight at the same index. An error is returned if the
+// slices differ in length, and otherwise as by NewChooserTFromIntChoices.
+func NewChooserTFromInts[T any](items []T, weights []int) (ChooserT[T], error) {
+	if len(items) != len(weights) {
+		return ChooserT[T]{}, fmt.Errorf("error: %d items but %d weights", len(items), len(weights))
+	}
+	cs := make([]IntChoiceT[T], len(items))
+	for i, item := range items {
+		cs[i] = IntChoiceT[T]{Item: item, Weight: weights[i]}
+	}
+	return NewChooserTFromIntChoices(cs...)
+}
+
+// NewChooserFromInts initializes a new Chooser whose choices are items, each
+// weighted by the weight at the same index. See NewChooserTFromInts.
+func NewChooserFromInts(items []interface{}, weights []int) (Chooser, error) {
+	return NewChooserTFromInts(items, weights)
+}
@@ -0,0 +1,70 @@
+package weightedrand
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestNewChooserFromInts(t *testing.T) {
+	items := []interface{}{"a", "zero", "b", "c"}
+	weights := []int{1, 0, 2, 5}
+	chooser, err := NewChooserFromInts(items, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{1, 0, 2, 5}; !reflect.DeepEqual(chooser.Weights(), want) {
+		t.Errorf("Weights() = %v, want %v", chooser.Weights(), want)
+	}
+
+	// The same weights by the uint path pick identically for the same seed.
+	chooser.rng = rand.New(rand.NewSource(6))
+	uints := NewChooserWithRand(rand.New(rand.NewSource(6)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "b", Weight: 2},
+		Choice{Item: "c", Weight: 5},
+	)
+	for i := 0; i < 10000; i++ {
+		if x, y := chooser.MustPick(), uints.MustPick(); x != y {
+			t.Fatalf("pick %d: %v from ints, %v from uints", i, x, y)
+		}
+	}
+
+	typed, err := NewChooserTFromIntChoices(IntChoiceT[int]{Item: 7, Weight: 3})
+	if err != nil || typed.MustPick() != 7 {
+		t.Errorf("NewChooserTFromIntChoices() = %v, %v", typed.Choices(), err)
+	}
+}
+
+func TestNewChooserFromIntsErrors(t *testing.T) {
+	_, err := NewChooserFromInts([]interface{}{"a", "b", "c"}, []int{4, -2, -7})
+	if !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidWeight)
+	}
+	if want := "error: invalid weight -2 for choice 1"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+	if _, err := NewChooserFromIntChoices(IntChoice{Item: "a", Weight: -1}); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidWeight)
+	}
+
+	_, err = NewChooserFromInts([]interface{}{"a", "b"}, []int{1})
+	if want := "error: 2 items but 1 weights"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+
+	if _, err := NewChooserFromInts(nil, nil); err != ErrNoChoices {
+		t.Errorf("error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewChooserTFromInts([]string{"a", "b"}, []int{0, 0}); err != ErrAllZeroWeights {
+		t.Errorf("error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if is64Bit {
+		big := int(^uint(0) >> 1)
+		if _, err := NewChooserFromInts([]interface{}{"a", "b", "c"}, []int{big, big, big}); err != ErrWeightOverflow {
+			t.Errorf("error = %v, want %v", err, ErrWeightOverflow)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package weightedrand
+
+import (
+	"container/heap"
+	"iter"
+)
+
+// Picks returns an iterator over n weighted random Choice.Items from the
+// Chooser, selected with replacement as by Pick and drawn from its rand source
+// one at a time as the loop asks for them. A negative n makes the sequence
+// infinite, so the loop must break out of it. The sequence is empty if the
+// Chooser cannot be picked from; check Err to tell why.
+func (chs ChooserT[T]) Picks(n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if chs.Err() != nil {
+			return
+		}
+		for i := 0; n < 0 || i < n; i++ {
+			j := chs.pick(chs.rng)
+			chs.observe(j)
+			if !yield(chs.data[j].Item) {
+				return
+			}
+		}
+	}
+}
+
+// Draws returns an iterator over the items and weights of the choices of the
+// Chooser, selected without replacement: each is drawn as if the ones before it
+// had been picked and removed, so the sequence is distributed like Shuffle,
+// and ends once every choice of nonzero weight has been drawn. Choices with a
+// weight of zero are never drawn.
+//
+// Random keys for every choice are drawn when the loop starts, taking O(n)
+// time, after which each item takes O(log n), so breaking out early is
+// cheaper than a full Shuffle.
+func (chs ChooserT[T]) Draws() iter.Seq2[T, uint] {
+	return func(yield func(T, uint) bool) {
+		if chs.Err() != nil {
+			return
+		}
+		// keyHeap is a min-heap, so negated keys pop largest first.
+		h := make(keyHeap, 0, len(chs.data))
+		for i, c := range chs.data {
+			if c.Weight > 0 {
+				h = append(h, keyedIndex{key: -sampleKey(chs.rng, c.Weight), index: i})
+			}
+		}
+		heap.Init(&h)
+		for h.Len() > 0 {
+			c := chs.data[heap.Pop(&h).(keyedIndex).index]
+			if !yield(c.Item, c.Weight) {
+				return
+			}
+		}
+	}
+}
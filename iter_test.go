@@ -0,0 +1,95 @@
+//go:build go1.23
+
+package weightedrand
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPicks(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 3},
+		Choice{Item: "zero", Weight: 0},
+	)
+	const n = 20000
+	shares := make(map[interface{}]float64)
+	count := 0
+	for item := range chooser.Picks(n) {
+		shares[item] += 1.0 / n
+		count++
+	}
+	if count != n {
+		t.Errorf("Picks(%d) yielded %d items", n, count)
+	}
+	assertShares(t, shares, map[interface{}]float64{"a": 0.25, "b": 0.75})
+
+	// The same seed yields the same sequence as Pick.
+	a := NewChooserWithRand(rand.New(rand.NewSource(42)), mockFrequencies(10)...)
+	b := NewChooserWithRand(rand.New(rand.NewSource(42)), mockFrequencies(10)...)
+	for item := range a.Picks(100) {
+		if want := b.MustPick(); item != want {
+			t.Fatalf("Picks() yielded %v, Pick %v", item, want)
+		}
+	}
+
+	count = 0
+	for range chooser.Picks(-1) {
+		if count++; count == 500 {
+			break
+		}
+	}
+	if count != 500 {
+		t.Errorf("infinite Picks() stopped after %d items", count)
+	}
+
+	for item := range NewChooser().Picks(-1) {
+		t.Fatalf("Picks() on empty chooser yielded %v", item)
+	}
+}
+
+func TestDraws(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 2},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "c", Weight: 7},
+	)
+	weights := map[interface{}]uint{"a": 1, "b": 2, "c": 7}
+
+	const n = 10000
+	firsts := make(map[interface{}]float64)
+	for i := 0; i < n; i++ {
+		seen := make(map[interface{}]bool)
+		for item, w := range chooser.Draws() {
+			if seen[item] {
+				t.Fatalf("Draws() yielded %v twice", item)
+			}
+			if len(seen) == 0 {
+				firsts[item] += 1.0 / n
+			}
+			seen[item] = true
+			if w != weights[item] {
+				t.Errorf("Draws() yielded %v with weight %d, want %d", item, w, weights[item])
+			}
+		}
+		if len(seen) != len(weights) {
+			t.Fatalf("Draws() yielded %v, want every nonzero item", seen)
+		}
+	}
+	assertShares(t, firsts, map[interface{}]float64{"a": 0.1, "b": 0.2, "c": 0.7})
+
+	count := 0
+	for range chooser.Draws() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Draws() yielded %d items before break, want 1", count)
+	}
+
+	for item := range NewChooser(Choice{Item: "zero"}).Draws() {
+		t.Fatalf("Draws() on all-zero chooser yielded %v", item)
+	}
+}
@@ -0,0 +1,80 @@
+package weightedrand
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// jsonChoice is the JSON representation of a ChoiceT[T]. The weight is kept
+// raw when decoding so that it can be validated strictly.
+type jsonChoice[T any, W any] struct {
+	Item   T `json:"item"`
+	Weight W `json:"weight"`
+}
+
+// MarshalJSON encodes c as a JSON object with "item" and "weight" fields.
+func (c ChoiceT[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonChoice[T, uint]{Item: c.Item, Weight: c.Weight})
+}
+
+// UnmarshalJSON decodes a JSON object with "item" and "weight" fields into c.
+// The weight must be a non-negative integer that fits in a uint; anything else
+// is reported as ErrInvalidWeight.
+//
+// For a Choice, the item decodes as the generic encoding/json types; use a
+// ChoiceT of a concrete type, or of json.RawMessage, to control it.
+func (c *ChoiceT[T]) UnmarshalJSON(data []byte) error {
+	var jc jsonChoice[T, json.RawMessage]
+	if err := json.Unmarshal(data, &jc); err != nil {
+		return err
+	}
+	w, err := strconv.ParseUint(string(jc.Weight), 10, strconv.IntSize)
+	if err != nil {
+		return fmt.Errorf("%w %s", ErrInvalidWeight, jc.Weight)
+	}
+	c.Item, c.Weight = jc.Item, uint(w)
+	return nil
+}
+
+// MarshalJSON encodes the choices of the Chooser as a JSON array of objects
+// with "item" and "weight" fields, in their original order.
+func (chs ChooserT[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(chs.original())
+}
+
+// UnmarshalJSON replaces the choices of the Chooser with those decoded from
+// a JSON array, as by NewChooserTFromJSON. The rand source is kept, and any
+// pick statistics restarted.
+func (chs *ChooserT[T]) UnmarshalJSON(data []byte) error {
+	decoded, err := NewChooserTFromJSON[T](data)
+	if err != nil {
+		return err
+	}
+	chs.replace(decoded)
+	return nil
+}
+
+// NewChooserTFromJSON initializes a new ChooserT from a JSON array of objects
+// with "item" and "weight" fields. An error, identifying the offending array
+// index where there is one, is returned for malformed JSON, invalid weights,
+// or a set of choices NewChooserTErr would reject.
+func NewChooserTFromJSON[T any](data []byte) (ChooserT[T], error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ChooserT[T]{}, err
+	}
+	cs := make([]ChoiceT[T], len(raw))
+	for i, r := range raw {
+		if err := json.Unmarshal(r, &cs[i]); err != nil {
+			return ChooserT[T]{}, fmt.Errorf("choice %d: %w", i, err)
+		}
+	}
+	return NewChooserTErr(cs...)
+}
+
+// NewChooserFromJSON initializes a new Chooser from a JSON array of objects
+// with "item" and "weight" fields. See NewChooserTFromJSON.
+func NewChooserFromJSON(data []byte) (Chooser, error) {
+	return NewChooserTFromJSON[interface{}](data)
+}
@@ -0,0 +1,99 @@
+package weightedrand
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChoiceJSON(t *testing.T) {
+	b, err := json.Marshal(Choice{Item: "a", Weight: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"item":"a","weight":3}`; string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+
+	var c ChoiceT[json.RawMessage]
+	if err := json.Unmarshal([]byte(`{"item": {"id": 7}, "weight": 12}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if string(c.Item) != `{"id": 7}` || c.Weight != 12 {
+		t.Errorf("Unmarshal() = %s/%d, want {\"id\": 7}/12", c.Item, c.Weight)
+	}
+
+	type server struct{ Host string }
+	var s ChoiceT[server]
+	if err := json.Unmarshal([]byte(`{"item": {"Host": "a.example"}, "weight": 2}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Item.Host != "a.example" || s.Weight != 2 {
+		t.Errorf("Unmarshal() = %+v", s)
+	}
+
+	for _, w := range []string{`"weight": -1`, `"weight": 1.5`, `"weight": 1e3`, `"weight": "3"`, `"weight": null`, `"weight": 99999999999999999999999`, `"other": 1`} {
+		err := json.Unmarshal([]byte(`{"item": "a", `+w+`}`), &c)
+		if !errors.Is(err, ErrInvalidWeight) {
+			t.Errorf("%s: got error %v, want %v", w, err, ErrInvalidWeight)
+		}
+	}
+}
+
+func TestNewChooserFromJSON(t *testing.T) {
+	chooser, err := NewChooserTFromJSON[string]([]byte(`[
+		{"item": "a", "weight": 1},
+		{"item": "b", "weight": 3},
+		{"item": "c", "weight": 0}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", chooser.Len())
+	}
+
+	_, err = NewChooserFromJSON([]byte(`[{"item": "a", "weight": 1}, {"item": "b", "weight": -2}]`))
+	if !errors.Is(err, ErrInvalidWeight) || !strings.Contains(err.Error(), "choice 1") {
+		t.Errorf("got error %v, want %v at choice 1", err, ErrInvalidWeight)
+	}
+	if _, err := NewChooserFromJSON([]byte(`[]`)); !errors.Is(err, ErrNoChoices) {
+		t.Errorf("got error %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewChooserFromJSON([]byte(`{`)); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
+
+// TestChooserJSONRoundTrip ensures a marshaled Chooser decodes to one with the
+// same choices, and so the same pick sequence for a given source.
+func TestChooserJSONRoundTrip(t *testing.T) {
+	choices := make([]ChoiceT[int], 50)
+	for i := range choices {
+		choices[i] = ChoiceT[int]{Item: i, Weight: uint(rand.Intn(10))}
+	}
+	chooser := NewChooserT(choices...)
+	b, err := json.Marshal(chooser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ChooserT[int]
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.data, chooser.data) || !reflect.DeepEqual(decoded.totals, chooser.totals) {
+		t.Fatalf("round trip changed choices:\n%v\n%v", chooser.data, decoded.data)
+	}
+
+	r1, r2 := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a, _ := chooser.PickSource(r1)
+		b, _ := decoded.PickSource(r2)
+		if a != b {
+			t.Fatalf("pick %d: %v != %v", i, a, b)
+		}
+	}
+}
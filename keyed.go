@@ -0,0 +1,29 @@
+package weightedrand
+
+import "hash/fnv"
+
+// PickByKey returns the Choice.Item that key maps to, drawing no random
+// numbers, so that the same key always selects the same item: for instance to
+// assign users to experiment groups by their IDs. Over many distinct keys,
+// items are selected in proportion to their weights.
+//
+// The mapping is part of the package's compatibility promise, so that keys
+// keep their items across releases and process restarts. The key is hashed
+// with 64-bit FNV-1a, and r = hash % TotalWeight() + 1 selects the first of
+// the Chooser's sorted choices whose cumulative total is at least r, as Pick
+// does with its random draw. Changing the weights or order of the choices
+// moves some keys to other items.
+func (chs ChooserT[T]) PickByKey(key []byte) (T, error) {
+	if err := chs.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+	h := fnv.New64a()
+	h.Write(key)
+	return chs.data[chs.search(h.Sum64()%chs.max+1)].Item, nil
+}
+
+// PickByString is like PickByKey, with the key given as a string.
+func (chs ChooserT[T]) PickByString(key string) (T, error) {
+	return chs.PickByKey([]byte(key))
+}
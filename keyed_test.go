@@ -0,0 +1,113 @@
+package weightedrand
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"testing"
+)
+
+func abTest() ChooserT[string] {
+	return NewChooserT(
+		ChoiceT[string]{Item: "control", Weight: 70},
+		ChoiceT[string]{Item: "variant", Weight: 20},
+		ChoiceT[string]{Item: "holdout", Weight: 10},
+	) // sorted totals: holdout 10, variant 30, control 100
+}
+
+// TestPickByKeyGolden pins the mapping from keys to items, which must never
+// change silently.
+func TestPickByKeyGolden(t *testing.T) {
+	chooser := abTest()
+	want := map[string]string{ // FNV-1a hash % 100 in comments
+		"":        "control", // 37
+		"user-1":  "holdout", // 8
+		"user-2":  "control", // 41
+		"user-3":  "control", // 30
+		"user-42": "variant", // 19
+		"alice":   "control", // 83
+		"bob":     "control", // 92
+	}
+	for key, want := range want {
+		if item, err := chooser.PickByString(key); err != nil || item != want {
+			t.Errorf("PickByString(%q) = %v, %v; want %s", key, item, err, want)
+		}
+	}
+}
+
+// TestPickByKeyBoundaries checks the hash values at the edges of each
+// choice's range of cumulative totals.
+func TestPickByKeyBoundaries(t *testing.T) {
+	chooser := abTest()
+	bounds := []struct {
+		mod  uint64 // hash % total
+		item string
+	}{
+		{0, "holdout"},
+		{9, "holdout"},
+		{10, "variant"},
+		{29, "variant"},
+		{30, "control"},
+		{99, "control"},
+	}
+	for _, b := range bounds {
+		key := keyWithHashMod(t, b.mod, 100)
+		if item, err := chooser.PickByKey(key); err != nil || item != b.item {
+			t.Errorf("PickByKey(%q) with hash %% 100 = %d: %v, %v; want %s", key, b.mod, item, err, b.item)
+		}
+	}
+
+	// Choices of equal weight keep their input order, so ties are broken the
+	// same way every time.
+	tied := NewChooserT(ChoiceT[string]{Item: "first", Weight: 1}, ChoiceT[string]{Item: "second", Weight: 1})
+	for _, b := range []struct {
+		mod  uint64
+		item string
+	}{{0, "first"}, {1, "second"}} {
+		key := keyWithHashMod(t, b.mod, 2)
+		if item, _ := tied.PickByKey(key); item != b.item {
+			t.Errorf("PickByKey(%q) = %s, want %s", key, item, b.item)
+		}
+	}
+}
+
+// keyWithHashMod returns a key whose FNV-1a hash is mod modulo total.
+func keyWithHashMod(t *testing.T, mod, total uint64) []byte {
+	t.Helper()
+	for i := 0; i < 100000; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		h := fnv.New64a()
+		h.Write(key)
+		if h.Sum64()%total == mod {
+			return key
+		}
+	}
+	t.Fatalf("no key found with hash %% %d = %d", total, mod)
+	return nil
+}
+
+func TestPickByKeyDistribution(t *testing.T) {
+	chooser := abTest()
+	const n = 100000
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		item, err := chooser.PickByString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again, _ := chooser.PickByString(key); again != item {
+			t.Fatalf("PickByString(%q) = %s, then %s", key, item, again)
+		}
+		counts[item]++
+	}
+	for item, p := range map[string]float64{"control": 0.7, "variant": 0.2, "holdout": 0.1} {
+		if got := float64(counts[item]) / n; math.Abs(got-p) > 0.01 {
+			t.Errorf("%s assigned %.3f of keys, want %.3f", item, got, p)
+		}
+	}
+
+	if _, err := NewChooser().PickByString("user-1"); err != ErrNoChoices {
+		t.Errorf("PickByString() error = %v, want %v", err, ErrNoChoices)
+	}
+}
@@ -0,0 +1,113 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// A LazyChooserT holds the choices of a ChooserT without building it, deferring
+// the sort and cumulative totals until the chooser is first needed, by Pick or
+// by an accessor that depends on them. Where many choosers are built but few
+// are ever picked from, as when they are made per request from cached
+// choices, the rest then cost next to nothing. Once built, the chooser, and so
+// every pick, is exactly that of NewChooserTOpts with the same choices and
+// options.
+//
+// The build is guarded by a sync.Once, so concurrent first picks are safe,
+// and thereafter a LazyChooserT is safe for concurrent use whenever its
+// ChooserT is.
+type LazyChooserT[T any] struct {
+	once sync.Once
+	cs   []ChoiceT[T]
+	o    options
+	chs  ChooserT[T]
+}
+
+// A LazyChooser is a LazyChooserT over untyped items.
+type LazyChooser = LazyChooserT[interface{}]
+
+// NewLazyChooserT returns a LazyChooserT of the possible ChoiceT[T],
+// configured by opts as for NewChooserTOpts. Only a problem with the options
+// is reported here; any problem with the choices is reported by Err and Pick
+// once the chooser is built.
+//
+// The LazyChooserT retains cs, which must not be modified afterwards. The
+// chooser copies it when built, as usual, or keeps it with WithBorrowedInput.
+func NewLazyChooserT[T any](cs []ChoiceT[T], opts ...Option) (*LazyChooserT[T], error) {
+	o, err := buildOptions(opts, chooserScope)
+	if err != nil {
+		return nil, err
+	}
+	return &LazyChooserT[T]{cs: cs, o: o}, nil
+}
+
+// NewLazyChooser returns a LazyChooser of the possible Choices. See
+// NewLazyChooserT.
+func NewLazyChooser(cs []Choice, opts ...Option) (*LazyChooser, error) {
+	return NewLazyChooserT(cs, opts...)
+}
+
+// Chooser returns the ChooserT, building it if this is its first use. The
+// error is the ChooserT's Err, unless WithFallback covers it, as for
+// NewChooserTOpts.
+func (lc *LazyChooserT[T]) Chooser() (ChooserT[T], error) {
+	chs := *lc.chooser()
+	if _, ok := chs.fallbackFor(chs.Err()); ok {
+		return chs, nil
+	}
+	return chs, chs.Err()
+}
+
+// chooser returns the ChooserT, building it if this is its first use. It
+// returns a pointer, which is never written through, to save copying the
+// ChooserT on every pick.
+func (lc *LazyChooserT[T]) chooser() *ChooserT[T] {
+	lc.once.Do(func() {
+		lc.chs = newChooser(lc.cs, lc.o)
+	})
+	return &lc.chs
+}
+
+// Pick returns a single weighted random Choice.Item, building the chooser if
+// this is its first use.
+func (lc *LazyChooserT[T]) Pick() (T, error) {
+	return lc.chooser().Pick()
+}
+
+// MustPick is like Pick but panics if the chooser cannot be picked from.
+func (lc *LazyChooserT[T]) MustPick() T {
+	return lc.chooser().MustPick()
+}
+
+// PickSource returns a single weighted random Choice.Item, drawing from rs
+// rather than the chooser's own source, and building the chooser if this is
+// its first use. A nil rs falls back to the global source in math/rand.
+func (lc *LazyChooserT[T]) PickSource(rs *rand.Rand) (T, error) {
+	return lc.chooser().PickSource(rs)
+}
+
+// Err returns the reason the chooser cannot be picked from, or nil if it is
+// usable, building the chooser if this is its first use.
+func (lc *LazyChooserT[T]) Err() error {
+	return lc.chooser().Err()
+}
+
+// Len returns the number of choices the chooser holds. Unless WithDedup may
+// merge some of them, that is known without building the chooser.
+func (lc *LazyChooserT[T]) Len() int {
+	if !lc.o.dedup {
+		return len(lc.cs)
+	}
+	return lc.chooser().Len()
+}
+
+// Choices returns a copy of the choices held by the chooser, in their original
+// order. Unless WithDedup or WithInvertedWeights may change them, or
+// WithBorrowedInput lets the build reorder them, they are known without
+// building the chooser.
+func (lc *LazyChooserT[T]) Choices() []ChoiceT[T] {
+	if !lc.o.dedup && !lc.o.invert && !lc.o.borrowInput {
+		return append([]ChoiceT[T](nil), lc.cs...)
+	}
+	return lc.chooser().Choices()
+}
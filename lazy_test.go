@@ -0,0 +1,140 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLazyChooser(t *testing.T) {
+	choices := []Choice{{Item: "a", Weight: 3}, {Item: "b", Weight: 1}, {Item: "c", Weight: 0}, {Item: "d", Weight: 6}}
+	lazy, err := NewLazyChooser(choices, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lazy.Len() != 4 || !reflect.DeepEqual(lazy.Choices(), choices) {
+		t.Errorf("Len(), Choices() = %d, %v before the build", lazy.Len(), lazy.Choices())
+	}
+	if lazy.chs.data != nil {
+		t.Fatal("Len or Choices built the chooser")
+	}
+
+	eager, _ := NewChooserOpts(choices, WithSeed(1))
+	for i := 0; i < 1000; i++ {
+		want := eager.MustPick()
+		if got := lazy.MustPick(); got != want {
+			t.Fatalf("pick %d = %v, want %v as from the eager chooser", i, got, want)
+		}
+	}
+	if lazy.Len() != 4 || !reflect.DeepEqual(lazy.Choices(), choices) || lazy.Err() != nil {
+		t.Errorf("Len(), Choices(), Err() = %d, %v, %v after the build", lazy.Len(), lazy.Choices(), lazy.Err())
+	}
+	chs, err := lazy.Chooser()
+	if err != nil || chs.TotalWeight() != 10 {
+		t.Errorf("Chooser() = %v, %v", chs.Choices(), err)
+	}
+	if _, err := lazy.PickSource(rand.New(rand.NewSource(2))); err != nil {
+		t.Errorf("PickSource() error = %v", err)
+	}
+}
+
+func TestLazyChooserOptions(t *testing.T) {
+	choices := []ChoiceT[string]{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}, {Item: "a", Weight: 3}}
+	deduped, _ := NewLazyChooserT(choices, WithDedup(nil))
+	if deduped.Len() != 2 {
+		t.Errorf("Len() = %d with WithDedup, want 2", deduped.Len())
+	}
+	inverted, _ := NewLazyChooserT(choices, WithInvertedWeights())
+	if got := inverted.Choices(); got[0].Weight != 3 || got[2].Weight != 1 {
+		t.Errorf("Choices() = %v with WithInvertedWeights", got)
+	}
+	borrowed := append([]ChoiceT[string](nil), choices...)
+	lazy, _ := NewLazyChooserT(borrowed, WithBorrowedInput())
+	lazy.Pick()
+	if got := lazy.Choices(); !reflect.DeepEqual(got, choices) {
+		t.Errorf("Choices() = %v with WithBorrowedInput, want %v", got, choices)
+	}
+
+	if _, err := NewLazyChooser(nil, WithParallelRand(), WithSeed(1)); err == nil {
+		t.Error("expected error for an invalid combination of options")
+	}
+	empty, err := NewLazyChooser(nil)
+	if err != nil {
+		t.Fatalf("NewLazyChooser() error = %v, want it deferred", err)
+	}
+	if _, err := empty.Pick(); err != ErrNoChoices || empty.Err() != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := empty.Chooser(); err != ErrNoChoices {
+		t.Errorf("Chooser() error = %v, want %v", err, ErrNoChoices)
+	}
+	fallback, _ := NewLazyChooser(nil, WithFallback("default"))
+	if _, err := fallback.Chooser(); err != nil {
+		t.Errorf("Chooser() error = %v with WithFallback", err)
+	}
+	if item, err := fallback.Pick(); item != "default" || err != nil {
+		t.Errorf("Pick() = %v, %v; want the fallback", item, err)
+	}
+}
+
+func TestLazyChooserConcurrentFirstPick(t *testing.T) {
+	lazy, _ := NewLazyChooser(mockFrequencies(100))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lazy.Len()
+			lazy.Choices()
+			for i := 0; i < 100; i++ {
+				if _, err := lazy.Pick(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkLazyChooser(b *testing.B) {
+	choices := mockFrequencies(100)
+	b.Run("construct/eager", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewChooser(choices...)
+		}
+	})
+	b.Run("construct/lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewLazyChooser(choices)
+		}
+	})
+	b.Run("first pick/eager", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewChooser(choices...).Pick()
+		}
+	})
+	b.Run("first pick/lazy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lazy, _ := NewLazyChooser(choices)
+			lazy.Pick()
+		}
+	})
+	b.Run("pick/eager", func(b *testing.B) {
+		chs := NewChooser(choices...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			chs.Pick()
+		}
+	})
+	b.Run("pick/lazy", func(b *testing.B) {
+		lazy, _ := NewLazyChooser(choices)
+		lazy.Pick()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			lazy.Pick()
+		}
+	})
+}
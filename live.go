@@ -0,0 +1,78 @@
+package weightedrand
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// A LiveChooserT holds a ChooserT that may be replaced while it is being
+// picked from, as when weights are reloaded from config. Each ChooserT stored
+// is an immutable snapshot, swapped in whole, so a Pick never waits on a
+// writer and always picks from either the old snapshot or the new one.
+//
+// Picks use the source of the snapshot they land on, which must therefore be
+// safe for concurrent use, as the default global source and those of
+// NewChooserParallel are. Writers are serialized among themselves, but never
+// block readers.
+//
+// The zero value holds an empty ChooserT, and is ready to use. A LiveChooserT
+// must not be copied after first use.
+type LiveChooserT[T any] struct {
+	v  atomic.Value // *ChooserT[T]
+	mu sync.Mutex   // serializes Store and Update
+}
+
+// A LiveChooser is a LiveChooserT over untyped items.
+type LiveChooser = LiveChooserT[interface{}]
+
+// NewLiveChooserT returns a LiveChooserT holding chs.
+func NewLiveChooserT[T any](chs ChooserT[T]) *LiveChooserT[T] {
+	l := new(LiveChooserT[T])
+	l.v.Store(&chs)
+	return l
+}
+
+// NewLiveChooser returns a LiveChooser holding chs.
+func NewLiveChooser(chs Chooser) *LiveChooser {
+	return NewLiveChooserT(chs)
+}
+
+// Pick returns a single weighted random Choice.Item from the current
+// snapshot.
+func (l *LiveChooserT[T]) Pick() (T, error) {
+	return l.load().Pick()
+}
+
+// Load returns the current snapshot. Mutating the returned ChooserT leaves the
+// LiveChooserT untouched, since mutations build fresh storage.
+func (l *LiveChooserT[T]) Load() ChooserT[T] {
+	return *l.load()
+}
+
+// Store replaces the current snapshot with chs.
+func (l *LiveChooserT[T]) Store(chs ChooserT[T]) {
+	l.mu.Lock()
+	l.v.Store(&chs)
+	l.mu.Unlock()
+}
+
+// Update replaces the current snapshot with the result of calling fn with it,
+// and returns the new snapshot. Calls to Update and Store are serialized, so
+// none of them is lost to another running at once. fn may mutate old, which
+// is a copy, but must not call back into the LiveChooserT's Store or Update.
+func (l *LiveChooserT[T]) Update(fn func(old ChooserT[T]) ChooserT[T]) ChooserT[T] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	next := fn(*l.load())
+	l.v.Store(&next)
+	return next
+}
+
+// load returns a pointer to the current snapshot, which must not be written
+// through, or to an empty ChooserT if none has been stored.
+func (l *LiveChooserT[T]) load() *ChooserT[T] {
+	if chs, ok := l.v.Load().(*ChooserT[T]); ok {
+		return chs
+	}
+	return new(ChooserT[T])
+}
@@ -0,0 +1,204 @@
+package weightedrand
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestLiveChooser(t *testing.T) {
+	var zero LiveChooser
+	if _, err := zero.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	live := NewLiveChooser(NewChooser(Choice{Item: "a", Weight: 1}))
+	if item, err := live.Pick(); err != nil || item != "a" {
+		t.Errorf("Pick() = %v, %v; want a", item, err)
+	}
+	live.Store(NewChooser(Choice{Item: "b", Weight: 1}))
+	if item, _ := live.Pick(); item != "b" {
+		t.Errorf("Pick() = %v after Store, want b", item)
+	}
+
+	got := live.Update(func(old Chooser) Chooser {
+		old.Add(Choice{Item: "c", Weight: 1})
+		old.SetWeight("b", 0)
+		return old
+	})
+	if got.Len() != 2 || live.Load().Len() != 2 {
+		t.Errorf("Len() = %d and %d after Update, want 2", got.Len(), live.Load().Len())
+	}
+	for i := 0; i < 100; i++ {
+		if item, _ := live.Pick(); item != "c" {
+			t.Fatalf("Pick() = %v after Update, want c", item)
+		}
+	}
+
+	// Mutating a loaded snapshot leaves the LiveChooser untouched.
+	snap := live.Load()
+	snap.Remove("c")
+	if item, _ := live.Pick(); item != "c" {
+		t.Errorf("Pick() = %v after mutating a snapshot, want c", item)
+	}
+}
+
+// TestLiveChooserStress picks from many goroutines while others swap in new
+// snapshots continuously. Each snapshot holds choices of a single generation
+// g, so a torn read would show up as a pick from a mix of generations. Run
+// with -race.
+func TestLiveChooserStress(t *testing.T) {
+	generation := func(g int) Chooser {
+		return NewChooser(
+			Choice{Item: [2]int{g, 0}, Weight: 1},
+			Choice{Item: [2]int{g, 1}, Weight: 2},
+			Choice{Item: [2]int{g, 2}, Weight: 0},
+		)
+	}
+	live := NewLiveChooser(generation(0))
+	done := make(chan struct{})
+	var readers, writers sync.WaitGroup
+
+	for r := 0; r < 16; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snap := live.Load()
+				item, err := snap.Pick()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				pair := item.([2]int)
+				if pair[1] == 2 || snap.Len() != 3 || snap.TotalWeight() != 3 {
+					t.Errorf("picked %v from a torn snapshot of %d choices", item, snap.Len())
+					return
+				}
+				if _, err := live.Pick(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			for i := 1; i <= 500; i++ {
+				if i%2 == 0 {
+					live.Store(generation(w*1000 + i))
+					continue
+				}
+				live.Update(func(old Chooser) Chooser {
+					g := old.Choices()[0].Item.([2]int)[0]
+					return generation(g + 1)
+				})
+			}
+		}(w)
+	}
+	writers.Wait()
+	close(done)
+	readers.Wait()
+}
+
+// TestLiveChooserUpdateSerialized checks that concurrent Updates are applied
+// one after another, so none of their increments is lost.
+func TestLiveChooserUpdateSerialized(t *testing.T) {
+	live := NewLiveChooserT(NewChooserT(ChoiceT[int]{Item: 0, Weight: 1}))
+	const goroutines, updates = 8, 200
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				live.Update(func(old ChooserT[int]) ChooserT[int] {
+					return NewChooserT(ChoiceT[int]{Item: old.MustPick() + 1, Weight: 1})
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if got := live.Load().MustPick(); got != goroutines*updates {
+		t.Errorf("MustPick() = %d after %d updates", got, goroutines*updates)
+	}
+}
+
+// BenchmarkLiveChooserParallel compares picking through a LiveChooser with
+// picking through a Chooser guarded by a sync.RWMutex, with and without a
+// writer swapping in a new Chooser continuously.
+func BenchmarkLiveChooserParallel(b *testing.B) {
+	for _, n := range []int{10, 1000} {
+		chooser := NewChooserParallel(mockFrequencies(n)...)
+		for _, swapping := range []bool{false, true} {
+			name := strconv.Itoa(n)
+			if swapping {
+				name += "/swapping"
+			}
+
+			b.Run("rwmutex/"+name, func(b *testing.B) {
+				var mu sync.RWMutex
+				current := chooser
+				stop := startSwapping(swapping, func() {
+					mu.Lock()
+					current = chooser
+					mu.Unlock()
+				})
+				defer stop()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						mu.RLock()
+						current.Pick()
+						mu.RUnlock()
+					}
+				})
+			})
+
+			b.Run("live/"+name, func(b *testing.B) {
+				live := NewLiveChooser(chooser)
+				stop := startSwapping(swapping, func() { live.Store(chooser) })
+				defer stop()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						live.Pick()
+					}
+				})
+			})
+		}
+	}
+}
+
+// startSwapping calls swap in a loop on another goroutine if enabled, until
+// the returned function is called.
+func startSwapping(enabled bool, swap func()) (stop func()) {
+	if !enabled {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				swap()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
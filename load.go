@@ -0,0 +1,164 @@
+package weightedrand
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// utf8BOM is the byte order mark some editors write at the start of a file.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// A CSVOption configures how NewChooserFromCSV reads its input.
+type CSVOption func(*csvOptions)
+
+// csvOptions holds the configuration assembled from a list of CSVOptions.
+type csvOptions struct {
+	header       bool
+	itemColumn   int
+	weightColumn int
+	comma        rune
+}
+
+// CSVHeader makes NewChooserFromCSV skip the first record, which holds the
+// column names.
+func CSVHeader() CSVOption {
+	return func(o *csvOptions) {
+		o.header = true
+	}
+}
+
+// CSVColumns makes NewChooserFromCSV read the item and the weight of each
+// record from the given zero-based columns, rather than the first and second.
+// Any other columns are ignored.
+func CSVColumns(item, weight int) CSVOption {
+	return func(o *csvOptions) {
+		o.itemColumn = item
+		o.weightColumn = weight
+	}
+}
+
+// CSVComma makes NewChooserFromCSV split fields on comma rather than ','.
+func CSVComma(comma rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = comma
+	}
+}
+
+// NewChooserFromCSV initializes a new Chooser from CSV records of an item and
+// its weight, by default in that order, streaming them from r. Items are kept
+// as strings and must not be empty; weights must be non-negative integers that
+// fit in a uint. A leading byte order mark and blank lines are skipped. An
+// error identifying the offending line is returned for malformed input, and
+// otherwise an error for any set of choices NewChooserErr would reject.
+func NewChooserFromCSV(r io.Reader, opts ...CSVOption) (Chooser, error) {
+	o := csvOptions{itemColumn: 0, weightColumn: 1, comma: ','}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.itemColumn < 0 || o.weightColumn < 0 {
+		return Chooser{}, errors.New("error: negative CSV column")
+	}
+	fields := o.itemColumn + 1
+	if o.weightColumn >= fields {
+		fields = o.weightColumn + 1
+	}
+
+	cr := csv.NewReader(skipBOM(r))
+	cr.Comma = o.comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	var cs []Choice
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Chooser{}, err
+		}
+		if first && o.header {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) < fields {
+			return Chooser{}, fmt.Errorf("line %d: error: %d fields, want at least %d", line, len(record), fields)
+		}
+		item := record[o.itemColumn]
+		if strings.TrimSpace(item) == "" {
+			return Chooser{}, fmt.Errorf("line %d: error: empty item", line)
+		}
+		w, err := strconv.ParseUint(strings.TrimSpace(record[o.weightColumn]), 10, strconv.IntSize)
+		if err != nil {
+			return Chooser{}, fmt.Errorf("line %d: %w %q", line, ErrInvalidWeight, record[o.weightColumn])
+		}
+		cs = append(cs, Choice{Item: item, Weight: uint(w)})
+	}
+	return NewChooserOpts(cs, WithBorrowedInput())
+}
+
+// NewChooserTFromNDJSON initializes a new ChooserT from newline-delimited
+// JSON, one object with "item" and "weight" fields per line, streaming them
+// from r. Each line is decoded as by ChoiceT.UnmarshalJSON, and its item must
+// be present, not null and not an empty string. A leading byte order mark and
+// blank lines are skipped. An error identifying the offending line is returned
+// for malformed input, and otherwise an error for any set of choices
+// NewChooserTErr would reject.
+func NewChooserTFromNDJSON[T any](r io.Reader) (ChooserT[T], error) {
+	br := skipBOM(r)
+	var cs []ChoiceT[T]
+	for line := 1; ; line++ {
+		data, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return ChooserT[T]{}, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			c, perr := parseNDJSONChoice[T](data)
+			if perr != nil {
+				return ChooserT[T]{}, fmt.Errorf("line %d: %w", line, perr)
+			}
+			cs = append(cs, c)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return NewChooserTOpts(cs, WithBorrowedInput())
+}
+
+// NewChooserFromNDJSON initializes a new Chooser from newline-delimited JSON.
+// See NewChooserTFromNDJSON.
+func NewChooserFromNDJSON(r io.Reader) (Chooser, error) {
+	return NewChooserTFromNDJSON[interface{}](r)
+}
+
+// parseNDJSONChoice decodes one line of NDJSON into a choice, rejecting a
+// missing or empty item.
+func parseNDJSONChoice[T any](data []byte) (ChoiceT[T], error) {
+	var raw jsonChoice[json.RawMessage, json.RawMessage]
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ChoiceT[T]{}, err
+	}
+	if item := string(raw.Item); item == "" || item == "null" || item == `""` {
+		return ChoiceT[T]{}, errors.New("error: empty item")
+	}
+	var c ChoiceT[T]
+	err := json.Unmarshal(data, &c)
+	return c, err
+}
+
+// skipBOM returns a buffered reader of r that skips a leading UTF-8 byte order
+// mark.
+func skipBOM(r io.Reader) *bufio.Reader {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	return br
+}
@@ -0,0 +1,257 @@
+package weightedrand
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewChooserFromCSV(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		opts  []CSVOption
+		want  []Choice
+	}{
+		{"plain", "a,1\nb,2\n", nil, []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}}},
+		{"no trailing newline", "a,1\nb,2", nil, []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}}},
+		{"header", "item,weight\na,1\n", []CSVOption{CSVHeader()}, []Choice{{Item: "a", Weight: 1}}},
+		{"BOM", "\ufeffa,1\nb,0\n", nil, []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 0}}},
+		{"BOM and header", "\ufeffitem,weight\na,1\n", []CSVOption{CSVHeader()}, []Choice{{Item: "a", Weight: 1}}},
+		{"blank lines", "\na,1\n\n\nb,2\n\n", nil, []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}}},
+		{"CRLF", "a,1\r\nb,2\r\n", nil, []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}}},
+		{"spaced weight", "a, 1 \n", nil, []Choice{{Item: "a", Weight: 1}}},
+		{"quoted", "\"x,y\",3\n", nil, []Choice{{Item: "x,y", Weight: 3}}},
+		{"columns", "1,w,5,a\n2,x,7,b\n", []CSVOption{CSVColumns(3, 2)}, []Choice{{Item: "a", Weight: 5}, {Item: "b", Weight: 7}}},
+		{"comma", "a;1\nb;2\n", []CSVOption{CSVComma(';')}, []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}}},
+		{"extra fields", "a,1,ignored\nb,2\n", nil, []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chooser, err := NewChooserFromCSV(strings.NewReader(tc.input), tc.opts...)
+			if err != nil {
+				t.Fatalf("NewChooserFromCSV() error = %v", err)
+			}
+			if got := chooser.Choices(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Choices() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewChooserFromCSVErrors(t *testing.T) {
+	// Errors from the parsers themselves are matched by prefix.
+	cases := []struct {
+		name  string
+		input string
+		opts  []CSVOption
+		want  string
+	}{
+		{"negative weight", "a,1\nb,-2\n", nil, `line 2: error: invalid weight "-2"`},
+		{"fractional weight", "a,1.5\n", nil, `line 1: error: invalid weight "1.5"`},
+		{"missing weight", "a,1\n\nb\n", nil, "line 3: error: 1 fields, want at least 2"},
+		{"empty weight", "a,\n", nil, `line 1: error: invalid weight ""`},
+		{"empty item", "a,1\n ,2\n", nil, "line 2: error: empty item"},
+		{"bad quote", "a,1\n\"b,2\n", nil, "parse error on line 2, column 6: "},
+		{"header only", "item,weight\n", []CSVOption{CSVHeader()}, ErrNoChoices.Error()},
+		{"unskipped header", "item,weight\na,1\n", nil, `line 1: error: invalid weight "weight"`},
+		{"all zero", "a,0\nb,0\n", nil, ErrAllZeroWeights.Error()},
+		{"negative column", "a,1\n", []CSVOption{CSVColumns(-1, 1)}, "error: negative CSV column"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewChooserFromCSV(strings.NewReader(tc.input), tc.opts...)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("NewChooserFromCSV() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+	_, err := NewChooserFromCSV(strings.NewReader("a,x\n"))
+	if !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("NewChooserFromCSV() error = %v, want %v", err, ErrInvalidWeight)
+	}
+}
+
+func TestNewChooserFromNDJSON(t *testing.T) {
+	input := "\ufeff" + `{"item":"a","weight":1}
+
+{"item":{"id":7},"weight":2}
+  {"weight":3,"item":5}  ` + "\r\n"
+	chooser, err := NewChooserFromNDJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Choice{
+		{Item: "a", Weight: 1},
+		{Item: map[string]interface{}{"id": float64(7)}, Weight: 2},
+		{Item: float64(5), Weight: 3},
+	}
+	if got := chooser.Choices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Choices() = %v, want %v", got, want)
+	}
+
+	typed, err := NewChooserTFromNDJSON[string](strings.NewReader(`{"item":"x","weight":4}`))
+	if err != nil || typed.MustPick() != "x" {
+		t.Errorf("NewChooserTFromNDJSON() = %v, %v; want a chooser of x", typed.Choices(), err)
+	}
+}
+
+func TestNewChooserFromNDJSONErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"negative weight", "{\"item\":\"a\",\"weight\":1}\n{\"item\":\"b\",\"weight\":-1}\n", "line 2: error: invalid weight -1"},
+		{"string weight", `{"item":"a","weight":"1"}`, `line 1: error: invalid weight "1"`},
+		{"missing weight", `{"item":"a"}`, "line 1: error: invalid weight"},
+		{"missing item", "\n\n{\"weight\":1}", "line 3: error: empty item"},
+		{"null item", `{"item":null,"weight":1}`, "line 1: error: empty item"},
+		{"empty item", `{"item":"","weight":1}`, "line 1: error: empty item"},
+		{"malformed", "{\"item\":\"a\",\"weight\":1}\n{\"item\":\"b\",", "line 2: unexpected end of JSON input"},
+		{"array", `[{"item":"a","weight":1}]`, "line 1: json: cannot unmarshal array"},
+		{"empty", "", ErrNoChoices.Error()},
+		{"all zero", `{"item":"a","weight":0}`, ErrAllZeroWeights.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewChooserFromNDJSON(strings.NewReader(tc.input))
+			if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("NewChooserFromNDJSON() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+	if _, err := NewChooserFromNDJSON(iotestErrReader{}); err != errShortWrite {
+		t.Errorf("NewChooserFromNDJSON() error = %v, want %v", err, errShortWrite)
+	}
+}
+
+// iotestErrReader fails every read with errShortWrite.
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) { return 0, errShortWrite }
+
+// generatedLines streams n generated lines, formatted by line, without ever
+// holding more than one of them.
+type generatedLines struct {
+	n, i int
+	line func(i int) string
+	buf  []byte
+}
+
+func (g *generatedLines) Read(p []byte) (int, error) {
+	if len(g.buf) == 0 {
+		if g.i == g.n {
+			return 0, io.EOF
+		}
+		g.buf = []byte(g.line(g.i))
+		g.i++
+	}
+	n := copy(p, g.buf)
+	g.buf = g.buf[n:]
+	return n, nil
+}
+
+func TestLoadLargeStreams(t *testing.T) {
+	const n = 200000
+	csvInput := &generatedLines{n: n, line: func(i int) string { return fmt.Sprintf("item-%d,%d\n", i, i%100) }}
+	chooser, err := NewChooserFromCSV(csvInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != n || chooser.TotalWeight() != n/100*4950 {
+		t.Errorf("CSV Len() = %d, TotalWeight() = %d; want %d, %d", chooser.Len(), chooser.TotalWeight(), n, n/100*4950)
+	}
+
+	ndjsonInput := &generatedLines{n: n, line: func(i int) string { return fmt.Sprintf("{\"item\":\"item-%d\",\"weight\":%d}\n", i, i%100) }}
+	chooser, err = NewChooserFromNDJSON(ndjsonInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != n || chooser.TotalWeight() != n/100*4950 {
+		t.Errorf("NDJSON Len() = %d, TotalWeight() = %d; want %d, %d", chooser.Len(), chooser.TotalWeight(), n, n/100*4950)
+	}
+
+	// Errors deep into a stream still name the right line.
+	bad := &generatedLines{n: n, line: func(i int) string {
+		if i == n-1 {
+			return "oops\n"
+		}
+		return fmt.Sprintf("item-%d,1\n", i)
+	}}
+	if _, err := NewChooserFromCSV(bad); err == nil || err.Error() != fmt.Sprintf("line %d: error: 1 fields, want at least 2", n) {
+		t.Errorf("NewChooserFromCSV() error = %v", err)
+	}
+}
+
+func TestLoadRoundTrip(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "c", Weight: 5},
+		Choice{Item: "a, quoted", Weight: 1},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "b", Weight: 2},
+	)
+
+	// Marshaled choices, one per line, load back as NDJSON.
+	var ndjson bytes.Buffer
+	for _, c := range chooser.Choices() {
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ndjson.Write(append(data, '\n'))
+	}
+	loaded, err := NewChooserFromNDJSON(&ndjson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Choices(), chooser.Choices()) {
+		t.Errorf("NDJSON round trip = %v, want %v", loaded.Choices(), chooser.Choices())
+	}
+
+	// The index, item and weight columns of Dump load back as CSV, once the
+	// padding is turned into commas.
+	var dump bytes.Buffer
+	loaded.Dump(&dump)
+	var table bytes.Buffer
+	w := csv.NewWriter(&table)
+	for _, line := range strings.Split(dump.String(), "\n")[1:] {
+		if fields := splitDumpRow(line); fields != nil {
+			w.Write(fields)
+		}
+	}
+	w.Flush()
+	fromDump, err := NewChooserFromCSV(&table, CSVHeader(), CSVColumns(1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromDump.Items(), []interface{}{"zero", "a, quoted", "b", "c"}) {
+		t.Errorf("Dump round trip items = %v, want Dump order", fromDump.Items())
+	}
+	for _, c := range loaded.Choices() {
+		if p, _ := fromDump.Probability(c.Item); p != float64(c.Weight)/8 {
+			t.Errorf("Dump round trip Probability(%v) = %v, want %v", c.Item, p, float64(c.Weight)/8)
+		}
+	}
+}
+
+// splitDumpRow splits a row of the table written by Dump into its columns,
+// which are separated by at least two spaces, or returns nil for a blank line.
+func splitDumpRow(line string) []string {
+	if line == "" {
+		return nil
+	}
+	var fields []string
+	for _, f := range strings.Split(line, "  ") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,48 @@
+package weightedrand
+
+// A lookupTable maps every value in [0,total) directly to the index of the
+// choice Pick selects for it, so that a pick costs a single array index
+// rather than a binary search. Only one of its index slices is used, the
+// narrowest that can hold every index.
+type lookupTable struct {
+	limit int // largest total to build a table for; 0 disables it
+	idx16 []uint16
+	idx32 []uint32
+}
+
+// buildLookup builds the lookup table of chs for totals up to limit.
+func (chs *ChooserT[T]) buildLookup(limit int) {
+	chs.lookup = lookupTable{limit: limit}
+	if limit <= 0 || !chs.valid || chs.max > uint64(limit) {
+		return
+	}
+	if len(chs.data) <= 1<<16 {
+		chs.lookup.idx16 = fillLookup(make([]uint16, chs.max), chs.totals)
+	} else {
+		chs.lookup.idx32 = fillLookup(make([]uint32, chs.max), chs.totals)
+	}
+}
+
+// fillLookup sets table[v] to the index of the first of totals greater than
+// v, for every v.
+func fillLookup[I uint16 | uint32](table []I, totals []uint64) []I {
+	v := uint64(0)
+	for i, total := range totals {
+		for ; v < total; v++ {
+			table[v] = I(i)
+		}
+	}
+	return table
+}
+
+// find returns the index into chs.data selected by the random value r in
+// [0,total), or false if the table was not built.
+func (t *lookupTable) find(r uint64) (int, bool) {
+	switch {
+	case t.idx16 != nil:
+		return int(t.idx16[r]), true
+	case t.idx32 != nil:
+		return int(t.idx32[r]), true
+	}
+	return 0, false
+}
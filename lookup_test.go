@@ -0,0 +1,79 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// TestWithLookupTable ensures that picks through the lookup table are exactly
+// those of the binary search.
+func TestWithLookupTable(t *testing.T) {
+	for _, n := range []int{1, 10, 1000, 70000} {
+		choices := make([]Choice, n)
+		for i := range choices {
+			choices[i] = Choice{Item: i, Weight: uint(i % 5)}
+		}
+		choices[0].Weight = 1
+		search, _ := NewChooserOpts(choices, WithSeed(1))
+		table, err := NewChooserOpts(choices, WithSeed(1), WithLookupTable(1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wide := n > 1<<16; (table.lookup.idx32 != nil) != wide || (table.lookup.idx16 != nil) == wide {
+			t.Errorf("n=%d: wrong index width for the table", n)
+		}
+		for v := uint64(0); v < table.max; v++ {
+			if got, _ := table.lookup.find(v); got != searchTotals(table.totals, v+1) {
+				t.Fatalf("n=%d: table maps %d to %d, search to %d", n, v, got, searchTotals(table.totals, v+1))
+			}
+		}
+		for i := 0; i < 10000; i++ {
+			if a, b := search.MustPick(), table.MustPick(); a != b {
+				t.Fatalf("n=%d pick %d: table picked %v, search %v", n, i, b, a)
+			}
+		}
+	}
+}
+
+func TestWithLookupTableLimit(t *testing.T) {
+	choices := []Choice{{Item: "a", Weight: 60}, {Item: "b", Weight: 40}}
+	chooser, _ := NewChooserOpts(choices, WithLookupTable(100))
+	if chooser.lookup.idx16 == nil {
+		t.Fatal("no table built for a total of 100 with limit 100")
+	}
+	chooser.Add(Choice{Item: "c", Weight: 1})
+	if chooser.lookup.idx16 != nil {
+		t.Error("table kept after the total grew past the limit")
+	}
+	if !chooser.Remove("c") || chooser.lookup.idx16 == nil {
+		t.Error("table not rebuilt after the total shrank back to the limit")
+	}
+	assertShares(t, pickShares(t, chooser, 20000), map[interface{}]float64{"a": 0.6, "b": 0.4})
+
+	empty, err := NewChooserOpts(nil, WithLookupTable(100))
+	if err != ErrNoChoices || empty.lookup.idx16 != nil {
+		t.Errorf("NewChooserOpts(nil) = %v; want no table and %v", err, ErrNoChoices)
+	}
+}
+
+func BenchmarkPickLookupTable(b *testing.B) {
+	for n := BMminChoices; n <= 10000; n *= 10 {
+		choices := make([]Choice, n)
+		for i := range choices {
+			choices[i] = Choice{Item: i, Weight: uint(rand.Intn(10) + 1)}
+		}
+		for _, limit := range []int{0, 1 << 20} {
+			name := "search"
+			if limit > 0 {
+				name = "table"
+			}
+			chooser, _ := NewChooserOpts(choices, WithSeed(1), WithLookupTable(limit))
+			b.Run(name+"/"+strconv.Itoa(n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					chooser.Pick()
+				}
+			})
+		}
+	}
+}
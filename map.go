@@ -0,0 +1,117 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+// NewChooserFromMap initializes a new ChooserT whose choices are the keys of m,
+// weighted by their values.
+//
+// Map iteration order is random, so the choices are first sorted by ascending
+// weight and then by key, making the layout, and so the picks for a given
+// seed, the same every time for the same map. Keys of string, integer and
+// float kinds sort naturally; any other keys sort by their Go syntax
+// representation, formatted with %#v. That holds addresses for pointer and
+// channel keys, and for structs and arrays holding them, so their order is
+// not stable across processes. An empty map gives a ChooserT with no choices,
+// as NewChooserT does.
+func NewChooserFromMap[K comparable](m map[K]uint) ChooserT[K] {
+	cs := make([]ChoiceT[K], 0, len(m))
+	for k, w := range m {
+		cs = append(cs, ChoiceT[K]{Item: k, Weight: w})
+	}
+	sortMapChoices(cs)
+	return buildChooser(cs, true)
+}
+
+// PickKey returns a single key of m picked at random, weighted by its value,
+// drawing from r, or from the global source in math/rand if r is nil. The keys
+// are laid out as by NewChooserFromMap, so that a seeded r picks the same key
+// every time for the same map, and the same key as the first Pick of a
+// KeyChooser built from m with an equally seeded r. Each call sorts the keys
+// afresh, taking O(n log n) time; build a KeyChooser to pick from m
+// repeatedly.
+func PickKey[K comparable](m map[K]uint, r *rand.Rand) (K, error) {
+	return NewChooserFromMap(m).PickSource(r)
+}
+
+// A KeyChooser picks among the keys of a map, weighted by their values. It is
+// built once, in O(n log n) time, and then picks in O(log n) time, returning
+// keys of their own type.
+type KeyChooser[K comparable] struct {
+	chs ChooserT[K]
+}
+
+// NewKeyChooser initializes a new KeyChooser over the keys of m, laid out as by
+// NewChooserFromMap, which draws its random numbers from r. A nil r falls back
+// to the global source in math/rand. Keys with a value of zero are kept but
+// never picked. ErrNoChoices is returned for an empty map, and
+// ErrAllZeroWeights or ErrWeightOverflow for weights NewChooserTErr would
+// reject.
+func NewKeyChooser[K comparable](m map[K]uint, r *rand.Rand) (KeyChooser[K], error) {
+	chs := NewChooserFromMap(m)
+	chs.rng = fromRand(r)
+	if err := chs.Err(); err != nil {
+		return KeyChooser[K]{}, err
+	}
+	return KeyChooser[K]{chs: chs}, nil
+}
+
+// Pick returns a single weighted random key of the map the KeyChooser was
+// built from.
+func (kc KeyChooser[K]) Pick() (K, error) {
+	return kc.chs.Pick()
+}
+
+// Len returns the number of keys held by the KeyChooser, including those of
+// zero weight.
+func (kc KeyChooser[K]) Len() int {
+	return kc.chs.Len()
+}
+
+// sortMapChoices sorts choices built from a map by ascending weight and then
+// by key.
+func sortMapChoices[K any](cs []ChoiceT[K]) {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Weight != cs[j].Weight {
+			return cs[i].Weight < cs[j].Weight
+		}
+		return lessKey(cs[i].Item, cs[j].Item)
+	})
+}
+
+// lessKey orders map keys deterministically: naturally for strings and
+// numbers, and by their %#v formatting otherwise, which is only as stable as
+// any addresses it holds.
+func lessKey[K any](a, b K) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsValid() && vb.IsValid() && va.Kind() == vb.Kind() {
+		switch va.Kind() {
+		case reflect.String:
+			return va.String() < vb.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return va.Int() < vb.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return va.Uint() < vb.Uint()
+		case reflect.Float32, reflect.Float64:
+			return va.Float() < vb.Float()
+		}
+	}
+	return fmt.Sprintf("%#v", a) < fmt.Sprintf("%#v", b)
+}
+
+// NewChooserFromSlice initializes a new ChooserT whose choices are items,
+// weighted by calling weightFn once for each of them during construction. The
+// Chooser holds its own copy of the items, so later changes to the slice do
+// not affect it. An error is returned for any problem NewChooserTErr would
+// report. A panic in weightFn is not recovered.
+func NewChooserFromSlice[T any](items []T, weightFn func(T) uint) (ChooserT[T], error) {
+	cs := make([]ChoiceT[T], len(items))
+	for i, item := range items {
+		cs[i] = ChoiceT[T]{Item: item, Weight: weightFn(item)}
+	}
+	return NewChooserTErr(cs...)
+}
@@ -0,0 +1,173 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestNewChooserFromMap(t *testing.T) {
+	m := map[string]uint{"d": 2, "a": 1, "c": 2, "b": 1, "zero": 0, "e": 5}
+	chooser := NewChooserFromMap(m)
+	if chooser.Len() != len(m) {
+		t.Errorf("Len() = %d, want %d", chooser.Len(), len(m))
+	}
+	want := []string{"zero", "a", "b", "c", "d", "e"}
+	if items := chooser.Items(); !reflect.DeepEqual(items, want) {
+		t.Errorf("Items() = %q, want %q", items, want)
+	}
+
+	// Repeated constructions, with fresh map iteration orders, lay out and
+	// pick identically.
+	for i := 0; i < 20; i++ {
+		other := NewChooserFromMap(m)
+		if !reflect.DeepEqual(other, chooser) {
+			t.Fatalf("construction %d differs:\n%+v\n%+v", i, other, chooser)
+		}
+	}
+	b, c := NewChooserFromMap(m), NewChooserFromMap(m)
+	b.rng, c.rng = rand.New(rand.NewSource(5)), rand.New(rand.NewSource(5))
+	for i := 0; i < 100; i++ {
+		if x, y := b.MustPick(), c.MustPick(); x != y {
+			t.Fatalf("pick %d: %v != %v", i, x, y)
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		if k := chooser.MustPick(); k == "zero" {
+			t.Fatal("picked zero weight key")
+		}
+	}
+
+	if _, err := NewChooserFromMap(map[string]uint{}).Pick(); err != ErrNoChoices {
+		t.Errorf("got error %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func TestNewChooserFromMapKeyOrder(t *testing.T) {
+	ints := NewChooserFromMap(map[int]uint{10: 1, -3: 1, 2: 1})
+	if want := []int{-3, 2, 10}; !reflect.DeepEqual(ints.Items(), want) {
+		t.Errorf("Items() = %v, want %v", ints.Items(), want)
+	}
+
+	type key struct{ A, B int }
+	structs := NewChooserFromMap(map[key]uint{{2, 1}: 1, {1, 2}: 1, {1, 1}: 1})
+	if want := []key{{1, 1}, {1, 2}, {2, 1}}; !reflect.DeepEqual(structs.Items(), want) {
+		t.Errorf("Items() = %v, want %v", structs.Items(), want)
+	}
+}
+
+func TestNewChooserFromSlice(t *testing.T) {
+	type server struct {
+		host     string
+		capacity uint
+	}
+	servers := []server{{"a", 1}, {"b", 0}, {"c", 3}}
+	chooser, err := NewChooserFromSlice(servers, func(s server) uint { return s.capacity })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{1, 0, 3}; !reflect.DeepEqual(chooser.Weights(), want) {
+		t.Errorf("Weights() = %v, want %v", chooser.Weights(), want)
+	}
+	if !reflect.DeepEqual(chooser.Items(), servers) {
+		t.Errorf("Items() = %v, want %v", chooser.Items(), servers)
+	}
+
+	// Mutating the input afterwards must not corrupt the chooser.
+	servers[0] = server{"mutated", 100}
+	for i := 0; i < 1000; i++ {
+		if s := chooser.MustPick(); s.host != "a" && s.host != "c" {
+			t.Fatalf("picked %v", s)
+		}
+	}
+
+	if _, err := NewChooserFromSlice([]server{}, func(s server) uint { return 1 }); err != ErrNoChoices {
+		t.Errorf("got error %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewChooserFromSlice([]int{1, 2}, func(int) uint { return 0 }); err != ErrAllZeroWeights {
+		t.Errorf("got error %v, want %v", err, ErrAllZeroWeights)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want the weightFn panic to propagate", r)
+		}
+	}()
+	NewChooserFromSlice([]int{1}, func(int) uint { panic("boom") })
+}
+
+func TestPickKey(t *testing.T) {
+	m := map[string]uint{"a": 1, "b": 3, "zero": 0}
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		k, err := PickKey(m, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[k]++
+	}
+	if counts["zero"] != 0 || counts["b"] < 2*counts["a"] {
+		t.Errorf("PickKey() counts = %v, want about 1:3 and no zero", counts)
+	}
+
+	// A seeded source picks the same key every time, however the map
+	// iterates, and the same as a KeyChooser seeded alike.
+	ints := map[int]uint{1: 5, 2: 5, 3: 5, 4: 5, 5: 5, 6: 5}
+	first, _ := PickKey(ints, rand.New(rand.NewSource(9)))
+	for i := 0; i < 20; i++ {
+		if k, _ := PickKey(ints, rand.New(rand.NewSource(9))); k != first {
+			t.Fatalf("PickKey() = %d, then %d with the same seed", first, k)
+		}
+	}
+	kc, err := NewKeyChooser(ints, rand.New(rand.NewSource(9)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := kc.Pick(); k != first {
+		t.Errorf("KeyChooser.Pick() = %d, PickKey() = %d with the same seed", k, first)
+	}
+
+	if _, err := PickKey(map[string]uint{}, nil); err != ErrNoChoices {
+		t.Errorf("PickKey() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := PickKey(map[int]uint{1: 0, 2: 0}, nil); err != ErrAllZeroWeights {
+		t.Errorf("PickKey() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+}
+
+func TestKeyChooser(t *testing.T) {
+	kc, err := NewKeyChooser(map[int]uint{10: 1, 20: 0, 30: 2}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kc.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", kc.Len())
+	}
+	for i := 0; i < 1000; i++ {
+		k, err := kc.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != 10 && k != 30 {
+			t.Fatalf("Pick() = %d", k)
+		}
+	}
+
+	a, _ := NewKeyChooser(map[string]uint{"x": 1, "y": 2, "z": 3}, rand.New(rand.NewSource(4)))
+	b, _ := NewKeyChooser(map[string]uint{"z": 3, "y": 2, "x": 1}, rand.New(rand.NewSource(4)))
+	for i := 0; i < 100; i++ {
+		x, _ := a.Pick()
+		y, _ := b.Pick()
+		if x != y {
+			t.Fatalf("pick %d: %q != %q", i, x, y)
+		}
+	}
+
+	if _, err := NewKeyChooser(map[string]uint(nil), nil); err != ErrNoChoices {
+		t.Errorf("NewKeyChooser() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewKeyChooser(map[string]uint{"a": 0}, nil); err != ErrAllZeroWeights {
+		t.Errorf("NewKeyChooser() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+}
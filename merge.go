@@ -0,0 +1,72 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// MergeT returns a new ChooserT holding the choices of every one of choosers,
+// in order, with their weights unchanged. The result shares no storage with
+// choosers, and takes the configuration of the first of them, its rand source
+// and the options kept when a chooser is mutated, as Union does; with no
+// choosers, it draws from the global source in math/rand. Like NewChooserTErr,
+// it reports ErrNoChoices if there are no choices at all, and ErrWeightOverflow
+// if the combined weights overflow.
+func MergeT[T any](choosers ...ChooserT[T]) (ChooserT[T], error) {
+	n := 0
+	for _, c := range choosers {
+		n += len(c.data)
+	}
+	cs := make([]ChoiceT[T], 0, n)
+	for _, c := range choosers {
+		cs = c.appendOriginal(cs)
+	}
+	var first ChooserT[T]
+	if len(choosers) > 0 {
+		first = choosers[0]
+	}
+	chs := first.derive(buildChooser(cs, false))
+	return chs, chs.Err()
+}
+
+// Merge returns a new Chooser holding the choices of every one of choosers.
+// See MergeT.
+func Merge(choosers ...Chooser) (Chooser, error) {
+	return MergeT(choosers...)
+}
+
+// Union returns a new Chooser holding the choices of chs followed by those of
+// other, with the weights of other multiplied by scale and rounded to the
+// nearest integer. The result keeps the rand source of chs, and the options
+// kept when chs is mutated, but shares no storage with either Chooser; nothing
+// of the configuration of other is kept.
+//
+// A negative, NaN or infinite scale is reported as ErrInvalidWeight, and a
+// scaled weight or combined total too large to represent as ErrWeightOverflow.
+func (chs ChooserT[T]) Union(other ChooserT[T], scale float64) (ChooserT[T], error) {
+	if !(scale >= 0) || math.IsInf(scale, 1) {
+		return ChooserT[T]{}, fmt.Errorf("%w: scale %v", ErrInvalidWeight, scale)
+	}
+	cs := chs.appendOriginal(make([]ChoiceT[T], 0, len(chs.data)+len(other.data)))
+	scaled := other.appendOriginal(cs)[len(cs):]
+	for i := range scaled {
+		w, err := scaleWeight(scaled[i].Weight, scale)
+		if err != nil {
+			return ChooserT[T]{}, err
+		}
+		scaled[i].Weight = w
+	}
+	u := chs.derive(buildChooser(cs[:len(cs)+len(scaled)], false))
+	return u, u.Err()
+}
+
+// scaleWeight returns w multiplied by scale, rounded to the nearest integer,
+// or ErrWeightOverflow if that does not fit in a uint.
+func scaleWeight(w uint, scale float64) (uint, error) {
+	v := math.Round(float64(w) * scale)
+	if v >= math.Ldexp(1, bits.UintSize) {
+		return 0, fmt.Errorf("%w: weight %d scaled by %v", ErrWeightOverflow, w, scale)
+	}
+	return uint(v), nil
+}
@@ -0,0 +1,124 @@
+package weightedrand
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	sports := NewChooser(Choice{Item: "x", Weight: 1}, Choice{Item: "y", Weight: 3})
+	news := NewChooser(Choice{Item: "z", Weight: 4})
+	merged, err := Merge(sports, NewChooser(), news)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Choice{{Item: "x", Weight: 1}, {Item: "y", Weight: 3}, {Item: "z", Weight: 4}}
+	if got := merged.Choices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Choices() = %v, want %v", got, want)
+	}
+	rand.Seed(1)
+	assertShares(t, pickShares(t, merged, 20000), map[interface{}]float64{"x": 0.125, "y": 0.375, "z": 0.5})
+
+	// The merged chooser shares no storage with its sources.
+	merged.data[0].Weight = 100
+	if sports.data[0].Weight != 1 || news.data[0].Weight != 4 {
+		t.Error("Merge() shares storage with its sources")
+	}
+
+	if _, err := Merge(); err != ErrNoChoices {
+		t.Errorf("Merge() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := Merge(NewChooser(), NewChooser()); err != ErrNoChoices {
+		t.Errorf("Merge(empty, empty) error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := Merge(NewChooser(Choice{Item: "a"}), news); err != nil {
+		t.Errorf("Merge(zero, news) error = %v, want nil", err)
+	}
+	if is64Bit {
+		half := NewChooser(Choice{Weight: ^uint(0)/2 + 1})
+		if _, err := Merge(half, half); err != ErrWeightOverflow {
+			t.Errorf("Merge() error = %v, want %v", err, ErrWeightOverflow)
+		}
+	}
+}
+
+func TestUnion(t *testing.T) {
+	sports := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "x", Weight: 1},
+		Choice{Item: "y", Weight: 3},
+	)
+	news := NewChooser(Choice{Item: "z", Weight: 4})
+	union, err := sports.Union(news, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := union.Weights(), []uint{1, 3, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Weights() = %v, want %v", got, want)
+	}
+	if union.rng != sports.rng {
+		t.Error("Union() did not keep the rand source")
+	}
+	assertShares(t, pickShares(t, union, 20000), map[interface{}]float64{"x": 1.0 / 6, "y": 0.5, "z": 1.0 / 3})
+	if news.data[0].Weight != 4 {
+		t.Error("Union() modified the weights of other")
+	}
+
+	// Scaling by zero keeps the choices but never picks them.
+	union, err = sports.Union(news, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if union.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", union.Len())
+	}
+
+	for _, scale := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if _, err := sports.Union(news, scale); !errors.Is(err, ErrInvalidWeight) {
+			t.Errorf("Union(scale %v) error = %v, want %v", scale, err, ErrInvalidWeight)
+		}
+	}
+	if _, err := sports.Union(news, math.MaxFloat64); !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("Union(huge scale) error = %v, want %v", err, ErrWeightOverflow)
+	}
+	if _, err := NewChooser().Union(NewChooser(), 1); err != ErrNoChoices {
+		t.Errorf("Union(empty) error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func TestMergeConfiguration(t *testing.T) {
+	configured := configuredChooser(t, Choice{Item: "x", Weight: 1}, Choice{Item: "y", Weight: 3})
+	plain := NewChooser(Choice{Item: "z", Weight: 4})
+
+	merged, err := Merge(configured, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Merge()", merged, configured)
+	reversed, err := Merge(plain, configured)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Merge()", reversed, plain)
+
+	union, err := configured.Union(plain, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Union()", union, configured)
+	if union, err = plain.Union(configured, 1); err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Union()", union, plain)
+
+	// Statistics restart for the merged choices.
+	merged.MustPick()
+	if got := configured.Stats(); got["x"]+got["y"] != 0 {
+		t.Errorf("Stats() = %v after picking from the merged chooser, want none", got)
+	}
+	if got := merged.Stats(); got["x"]+got["y"]+got["z"] != 1 {
+		t.Errorf("Stats() = %v for the merged chooser, want one pick", got)
+	}
+}
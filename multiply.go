@@ -0,0 +1,99 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math"
+)
+
+// PickWithMultipliers returns a single weighted random Choice.Item, picked as
+// if each choice's weight were multiplied by mult(item) for this one pick, as
+// for a contextual boost that would be too costly to apply by rebuilding the
+// Chooser. The Chooser itself is unchanged. A multiplier of 0 excludes a
+// choice, and ErrAllZeroWeights is returned if every choice is excluded.
+//
+// mult is called once for every choice of nonzero weight, and the products
+// are summed as float64, so each pick takes O(n) time;
+// PickWithBoundedMultipliers is usually faster when an upper bound on the
+// multipliers is known. A negative, NaN or infinite multiplier is reported as
+// ErrInvalidWeight, and products summing to more than the largest float64 as
+// ErrWeightOverflow.
+func (chs ChooserT[T]) PickWithMultipliers(mult func(item T) float64) (T, error) {
+	var zero T
+	if err := chs.Err(); err != nil {
+		return zero, err
+	}
+	weights := make([]float64, len(chs.data))
+	total := 0.0
+	for i, c := range chs.data {
+		if c.Weight == 0 {
+			continue
+		}
+		m, err := chs.multiplier(mult, i, math.Inf(1))
+		if err != nil {
+			return zero, err
+		}
+		weights[i] = float64(c.Weight) * m
+		total += weights[i]
+	}
+	if math.IsInf(total, 1) {
+		return zero, fmt.Errorf("%w float64", ErrWeightOverflow)
+	}
+	if total == 0 {
+		return zero, ErrAllZeroWeights
+	}
+	r := float64n(chs.rng) * total
+	last := -1
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		if r < w {
+			return chs.data[i].Item, nil
+		}
+		r -= w
+		last = i
+	}
+	// Rounding may leave r just short of the last weight.
+	return chs.data[last].Item, nil
+}
+
+// PickWithBoundedMultipliers is like PickWithMultipliers, given that no
+// multiplier exceeds bound. It first picks by the base weights alone, accepting
+// the choice with probability mult(item)/bound, which calls mult only a few
+// times when the multipliers are mostly near bound. After pickWhereAttempts
+// rejections it falls back to PickWithMultipliers, so that it always
+// terminates, and picks are distributed exactly as for PickWithMultipliers
+// either way. mult must therefore give the same answer for an item each time.
+//
+// A bound that is not positive and finite is reported as ErrInvalidWeight, as
+// is a multiplier above bound when one is met.
+func (chs ChooserT[T]) PickWithBoundedMultipliers(mult func(item T) float64, bound float64) (T, error) {
+	var zero T
+	if !(bound > 0) || math.IsInf(bound, 1) {
+		return zero, fmt.Errorf("%w: multiplier bound %v", ErrInvalidWeight, bound)
+	}
+	if err := chs.Err(); err != nil {
+		return zero, err
+	}
+	for attempt := 0; attempt < pickWhereAttempts; attempt++ {
+		i := chs.pick(chs.rng)
+		m, err := chs.multiplier(mult, i, bound)
+		if err != nil {
+			return zero, err
+		}
+		if m == bound || float64n(chs.rng)*bound < m {
+			return chs.data[i].Item, nil
+		}
+	}
+	return chs.PickWithMultipliers(mult)
+}
+
+// multiplier returns mult of the item of the choice at position i in chs.data,
+// or ErrInvalidWeight if it is negative, NaN or above bound.
+func (chs ChooserT[T]) multiplier(mult func(item T) float64, i int, bound float64) (float64, error) {
+	m := mult(chs.data[i].Item)
+	if !(m >= 0) || math.IsInf(m, 1) || m > bound {
+		return 0, fmt.Errorf("%w: multiplier %v for choice %d", ErrInvalidWeight, m, chs.index(i))
+	}
+	return m, nil
+}
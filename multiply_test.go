@@ -0,0 +1,116 @@
+package weightedrand
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPickWithMultipliers(t *testing.T) {
+	choices := []Choice{
+		{Item: "en", Weight: 1},
+		{Item: "fr", Weight: 2},
+		{Item: "de", Weight: 3},
+		{Item: "off", Weight: 0},
+	}
+	boost := map[interface{}]float64{"en": 4, "fr": 1, "de": 0, "off": 100}
+	mult := func(item interface{}) float64 { return boost[item] }
+	// With the multipliers, en weighs 4 and fr 2, and de is excluded.
+	want := map[interface{}]float64{"en": 4.0 / 6, "fr": 2.0 / 6}
+	picks := map[string]func(Chooser) (interface{}, error){
+		"exact":   func(c Chooser) (interface{}, error) { return c.PickWithMultipliers(mult) },
+		"bounded": func(c Chooser) (interface{}, error) { return c.PickWithBoundedMultipliers(mult, 100) },
+		"tight":   func(c Chooser) (interface{}, error) { return c.PickWithBoundedMultipliers(mult, 4) },
+	}
+	for name, pick := range picks {
+		chooser := NewChooserWithRand(rand.New(rand.NewSource(1)), choices...)
+		before := chooser.Choices()
+		const n = 60000
+		counts := make(map[interface{}]int)
+		for i := 0; i < n; i++ {
+			item, err := pick(chooser)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			counts[item]++
+		}
+		for item, p := range want {
+			if got := float64(counts[item]) / n; math.Abs(got-p) > 0.01 {
+				t.Errorf("%s: %v picked %.4f of the time, want %.4f", name, item, got, p)
+			}
+		}
+		if counts["de"] != 0 || counts["off"] != 0 {
+			t.Errorf("%s: picked excluded items: %v", name, counts)
+		}
+		if !reflect.DeepEqual(chooser.Choices(), before) || chooser.TotalWeight() != 6 {
+			t.Errorf("%s: Choices() = %v after picks, want %v", name, chooser.Choices(), before)
+		}
+	}
+
+	typed := NewChooserT(ChoiceT[int]{Item: 1, Weight: 1}, ChoiceT[int]{Item: 2, Weight: 1})
+	if item, err := typed.PickWithMultipliers(func(i int) float64 { return float64(i - 1) }); item != 2 || err != nil {
+		t.Errorf("PickWithMultipliers() = %v, %v; want 2", item, err)
+	}
+}
+
+func TestPickWithMultipliersErrors(t *testing.T) {
+	chooser := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 1})
+	constant := func(m float64) func(interface{}) float64 {
+		return func(interface{}) float64 { return m }
+	}
+	cases := []struct {
+		name string
+		mult func(interface{}) float64
+		want error
+	}{
+		{"zero", constant(0), ErrAllZeroWeights},
+		{"negative", constant(-1), ErrInvalidWeight},
+		{"NaN", constant(math.NaN()), ErrInvalidWeight},
+		{"infinite", constant(math.Inf(1)), ErrInvalidWeight},
+		{"overflow", constant(math.MaxFloat64), ErrWeightOverflow},
+	}
+	for _, tc := range cases {
+		if _, err := chooser.PickWithMultipliers(tc.mult); !errors.Is(err, tc.want) {
+			t.Errorf("%s: PickWithMultipliers() error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if _, err := chooser.PickWithMultipliers(constant(-1)); err == nil || err.Error() != "error: invalid weight: multiplier -1 for choice 0" {
+		t.Errorf("PickWithMultipliers() error = %v", err)
+	}
+	if _, err := chooser.PickWithBoundedMultipliers(constant(2), 1); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("PickWithBoundedMultipliers() error = %v for a multiplier above the bound", err)
+	}
+	if _, err := chooser.PickWithBoundedMultipliers(constant(0), 1); err != ErrAllZeroWeights {
+		t.Errorf("PickWithBoundedMultipliers() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	for _, bound := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := chooser.PickWithBoundedMultipliers(constant(1), bound); !errors.Is(err, ErrInvalidWeight) {
+			t.Errorf("PickWithBoundedMultipliers() error = %v for bound %v", err, bound)
+		}
+	}
+	if _, err := NewChooser().PickWithMultipliers(constant(1)); err != ErrNoChoices {
+		t.Errorf("PickWithMultipliers() error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func BenchmarkPickWithMultipliers(b *testing.B) {
+	chooser := NewChooser(mockFrequencies(1000)...)
+	mult := func(item interface{}) float64 {
+		if item.(int)%2 == 0 {
+			return 2
+		}
+		return 1
+	}
+	b.Run("exact", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			chooser.PickWithMultipliers(mult)
+		}
+	})
+	b.Run("bounded", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			chooser.PickWithBoundedMultipliers(mult, 2)
+		}
+	})
+}
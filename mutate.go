@@ -0,0 +1,87 @@
+package weightedrand
+
+// The methods in this file modify a Chooser in place, recomputing its totals
+// eagerly so that subsequent picks reflect the change. Each builds fresh
+// storage, leaving earlier copies of the Chooser untouched, but a Chooser must
+// not be mutated while it is being picked from concurrently.
+//
+// Items are matched by ==, so these methods panic if T is an interface type
+// holding an item that is not comparable. Where several choices match, the
+// first in original order is used.
+
+// Add adds c to the possible choices of the Chooser, after all existing ones.
+func (chs *ChooserT[T]) Add(c ChoiceT[T]) {
+	chs.rebuild(append(chs.original(), c))
+}
+
+// Remove removes the first choice whose Item equals item, and reports whether
+// one was found. Removing the last choice leaves the Chooser empty, so Pick
+// will return an error until more are added.
+func (chs *ChooserT[T]) Remove(item T) bool {
+	cs := chs.original()
+	i := find(cs, item)
+	if i < 0 {
+		return false
+	}
+	chs.rebuild(append(cs[:i], cs[i+1:]...))
+	return true
+}
+
+// SetWeight sets the weight of the first choice whose Item equals item to w,
+// and reports whether one was found. A missing item is not added.
+func (chs *ChooserT[T]) SetWeight(item T, w uint) bool {
+	cs := chs.original()
+	i := find(cs, item)
+	if i < 0 {
+		return false
+	}
+	cs[i].Weight = w
+	chs.rebuild(cs)
+	return true
+}
+
+// find returns the index of the first choice in cs whose Item equals item, or
+// -1 if there is none.
+func find[T any](cs []ChoiceT[T], item T) int {
+	for i, c := range cs {
+		if equalItems(c.Item, item) {
+			return i
+		}
+	}
+	return -1
+}
+
+// rebuild replaces the choices of chs with cs, recomputing the sorted order
+// and cumulative totals but keeping its rand source.
+func (chs *ChooserT[T]) rebuild(cs []ChoiceT[T]) {
+	chs.replace(buildChooser(cs, false))
+}
+
+// replace replaces chs with next, keeping the rand source of chs, its pick
+// statistics mode with the counters restarted, its lookup table limit, its
+// compact totals mode, its pick hook and its fallback.
+func (chs *ChooserT[T]) replace(next ChooserT[T]) {
+	next.rng = chs.rng
+	next.onPick = chs.onPick
+	next.backup = chs.backup
+	if chs.stats != nil {
+		next.stats = newPickStats(len(next.data))
+	}
+	next.buildLookup(chs.lookup.limit)
+	next.compact = chs.compact
+	next.compactTotals()
+	*chs = next
+}
+
+// derive returns next configured as chs is, as by replace, for the methods
+// returning a new Chooser built from chs. chs itself is unchanged.
+func (chs ChooserT[T]) derive(next ChooserT[T]) ChooserT[T] {
+	chs.replace(next)
+	return chs
+}
+
+// equalItems reports whether a and b are equal items, panicking if they are
+// not comparable.
+func equalItems[T any](a, b T) bool {
+	return interface{}(a) == interface{}(b)
+}
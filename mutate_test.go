@@ -0,0 +1,115 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// pickShares picks n times from chs and returns the share of picks won by each
+// item.
+func pickShares(t *testing.T, chs Chooser, n int) map[interface{}]float64 {
+	t.Helper()
+	shares := make(map[interface{}]float64)
+	for i := 0; i < n; i++ {
+		item, err := chs.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[item] += 1 / float64(n)
+	}
+	return shares
+}
+
+// configuredChooser returns a Chooser of cs with every option that a Chooser
+// derived from it should keep.
+func configuredChooser(t *testing.T, cs ...Choice) Chooser {
+	t.Helper()
+	chs, err := NewChooserOpts(cs, WithSeed(1), WithStats(), WithLookupTable(1000), WithCompactTotals(),
+		WithOnPick(func(interface{}, int) {}), WithFallback("fallback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chs
+}
+
+// assertDerived reports any option of from that got, derived from it by name,
+// has not kept.
+func assertDerived(t *testing.T, name string, got, from Chooser) {
+	t.Helper()
+	if got.rng != from.rng {
+		t.Errorf("%s did not keep the rand source", name)
+	}
+	if (got.stats == nil) != (from.stats == nil) || (got.onPick == nil) != (from.onPick == nil) {
+		t.Errorf("%s did not keep WithStats or WithOnPick", name)
+	}
+	if got.lookup.limit != from.lookup.limit || got.compact != from.compact {
+		t.Errorf("%s did not keep WithLookupTable or WithCompactTotals", name)
+	}
+	if got.backup != from.backup {
+		t.Errorf("%s did not keep WithFallback", name)
+	}
+}
+
+func assertShares(t *testing.T, got, want map[interface{}]float64) {
+	t.Helper()
+	for item, w := range want {
+		if g := got[item]; g < w-0.02 || g > w+0.02 {
+			t.Errorf("%v picked %.3f of the time, want %.3f", item, g, w)
+		}
+	}
+	for item, g := range got {
+		if _, ok := want[item]; !ok {
+			t.Errorf("%v picked %.3f of the time, want never", item, g)
+		}
+	}
+}
+
+func TestMutations(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 1},
+	)
+	assertShares(t, pickShares(t, chooser, 10000), map[interface{}]float64{"a": 0.5, "b": 0.5})
+
+	chooser.Add(Choice{Item: "c", Weight: 2})
+	if chooser.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", chooser.Len())
+	}
+	assertShares(t, pickShares(t, chooser, 10000), map[interface{}]float64{"a": 0.25, "b": 0.25, "c": 0.5})
+
+	if !chooser.SetWeight("a", 5) {
+		t.Error("SetWeight(a) = false, want true")
+	}
+	assertShares(t, pickShares(t, chooser, 10000), map[interface{}]float64{"a": 0.625, "b": 0.125, "c": 0.25})
+
+	if !chooser.Remove("c") {
+		t.Error("Remove(c) = false, want true")
+	}
+	assertShares(t, pickShares(t, chooser, 10000), map[interface{}]float64{"a": 5.0 / 6, "b": 1.0 / 6})
+
+	if chooser.SetWeight("missing", 1) {
+		t.Error("SetWeight(missing) = true, want false")
+	}
+	if chooser.Remove("missing") {
+		t.Error("Remove(missing) = true, want false")
+	}
+	if chooser.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", chooser.Len())
+	}
+
+	chooser.SetWeight("b", 0)
+	assertShares(t, pickShares(t, chooser, 1000), map[interface{}]float64{"a": 1})
+
+	chooser.Remove("a")
+	chooser.Remove("b")
+	if _, err := chooser.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+	chooser.Add(Choice{Item: "d", Weight: 1})
+	if item, err := chooser.Pick(); err != nil || item != "d" {
+		t.Errorf("Pick() = %v, %v; want d", item, err)
+	}
+	if chooser.rng == nil {
+		t.Error("mutation discarded the rand source")
+	}
+}
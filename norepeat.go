@@ -0,0 +1,150 @@
+package weightedrand
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A NoRepeatChooserT picks weighted random items like a ChooserT, except that
+// a choice it has returned is not returned again until a window of further
+// picks has passed, which avoids the runs of the same heavy item that look
+// broken in a rotating display. While a choice is in the window, it is as if
+// its weight were zero, so the others keep their relative weights. Heavy
+// choices are therefore picked somewhat less often than their share of the
+// weight in the long run, and light ones more.
+//
+// If the window is as large as the number of choices of nonzero weight, it is
+// shrunk to one fewer, so that there is always something to pick; with a
+// single such choice, it is returned every time.
+//
+// Suppression is per choice, not per item: two choices with equal items are
+// tracked separately, so one may be returned right after the other. Merge
+// them with WithDedup first if an item must not repeat.
+//
+// A NoRepeatChooserT guards the picks it remembers with a mutex, so it is safe
+// for concurrent use if its rand source is.
+type NoRepeatChooserT[T any] struct {
+	mu     sync.Mutex
+	chs    ChooserT[T]
+	window int
+	recent []int // positions in chs.data of the last picks, as a ring
+	next   int   // position in recent of the oldest pick, once it is full
+}
+
+// A NoRepeatChooser is a NoRepeatChooserT over untyped items.
+type NoRepeatChooser = NoRepeatChooserT[interface{}]
+
+// NewNoRepeatChooserT initializes a new NoRepeatChooserT consisting of the
+// possible ChoiceT[T], configured by opts as for NewChooserTOpts, with
+// WithNoImmediateRepeat setting the window. An error is returned for a
+// negative window, and for any problem NewChooserTOpts would report.
+func NewNoRepeatChooserT[T any](cs []ChoiceT[T], opts ...Option) (*NoRepeatChooserT[T], error) {
+	o, err := buildOptions(opts, chooserScope|noRepeatScope)
+	if err != nil {
+		return nil, err
+	}
+	if !o.windowSet {
+		o.window = 1
+	}
+	if o.window < 0 {
+		return nil, fmt.Errorf("error: negative window %d", o.window)
+	}
+	chs := newChooser(cs, o)
+	if err := chs.Err(); err != nil {
+		return nil, err
+	}
+	nonzero := 0
+	for _, c := range chs.data {
+		if c.Weight > 0 {
+			nonzero++
+		}
+	}
+	if o.window >= nonzero {
+		o.window = nonzero - 1
+	}
+	return &NoRepeatChooserT[T]{
+		chs:    chs,
+		window: o.window,
+		recent: make([]int, 0, o.window),
+	}, nil
+}
+
+// NewNoRepeatChooser initializes a new NoRepeatChooser consisting of the
+// possible Choices. See NewNoRepeatChooserT.
+func NewNoRepeatChooser(cs []Choice, opts ...Option) (*NoRepeatChooser, error) {
+	return NewNoRepeatChooserT(cs, opts...)
+}
+
+// Pick returns a single weighted random Choice.Item from among the choices not
+// returned by the last Window picks.
+//
+// Like PickWhere, it first picks from all the choices, rejecting recent ones,
+// and after pickWhereAttempts rejections picks directly among the rest.
+func (nr *NoRepeatChooserT[T]) Pick() (T, error) {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	chs := nr.chs
+	i := -1
+	for attempt := 0; attempt < pickWhereAttempts && i < 0; attempt++ {
+		if j := chs.pick(chs.rng); !nr.isRecent(j) {
+			i = j
+		}
+	}
+	if i < 0 {
+		var kept []int
+		var total uint64
+		for j, c := range chs.data {
+			if c.Weight > 0 && !nr.isRecent(j) {
+				kept = append(kept, j)
+				total += uint64(c.Weight)
+			}
+		}
+		i, _ = chs.pickAmong(kept, total)
+	}
+	nr.remember(i)
+	chs.observe(i)
+	return chs.data[i].Item, nil
+}
+
+// isRecent reports whether the choice at position i in nr.chs.data is among
+// the last picks.
+func (nr *NoRepeatChooserT[T]) isRecent(i int) bool {
+	for _, j := range nr.recent {
+		if j == i {
+			return true
+		}
+	}
+	return false
+}
+
+// remember records a pick of the choice at position i in nr.chs.data,
+// forgetting the oldest pick once the window is full.
+func (nr *NoRepeatChooserT[T]) remember(i int) {
+	switch {
+	case nr.window == 0:
+	case len(nr.recent) < nr.window:
+		nr.recent = append(nr.recent, i)
+	default:
+		nr.recent[nr.next] = i
+		nr.next = (nr.next + 1) % nr.window
+	}
+}
+
+// Reset forgets the recent picks, so that any choice may be picked next.
+func (nr *NoRepeatChooserT[T]) Reset() {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	nr.recent = nr.recent[:0]
+	nr.next = 0
+}
+
+// Window returns the number of recent picks that are never repeated, after
+// any shrinking to fit the number of choices of nonzero weight.
+func (nr *NoRepeatChooserT[T]) Window() int {
+	return nr.window
+}
+
+// Len returns the number of choices held by the NoRepeatChooser.
+func (nr *NoRepeatChooserT[T]) Len() int {
+	return nr.chs.Len()
+}
@@ -0,0 +1,206 @@
+package weightedrand
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestNoRepeatChooser(t *testing.T) {
+	choices := []Choice{
+		{Item: "heavy", Weight: 90},
+		{Item: "b", Weight: 4},
+		{Item: "c", Weight: 3},
+		{Item: "d", Weight: 2},
+		{Item: "e", Weight: 1},
+		{Item: "zero", Weight: 0},
+	}
+	for _, window := range []int{1, 2, 3} {
+		nr, err := NewNoRepeatChooser(choices, WithNoImmediateRepeat(window), WithSeed(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nr.Window() != window {
+			t.Errorf("Window() = %d, want %d", nr.Window(), window)
+		}
+		var last []interface{}
+		counts := make(map[interface{}]int)
+		const n = 100000
+		for i := 0; i < n; i++ {
+			item, err := nr.Pick()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, prev := range last {
+				if item == prev {
+					t.Fatalf("window %d: pick %d repeated %v within %v", window, i, item, last)
+				}
+			}
+			if last = append(last, item); len(last) > window {
+				last = last[1:]
+			}
+			counts[item]++
+		}
+		if counts["zero"] != 0 {
+			t.Errorf("window %d: picked zero weight item %d times", window, counts["zero"])
+		}
+		// The light choices keep their order of frequency.
+		for _, pair := range [][2]string{{"b", "c"}, {"c", "d"}, {"d", "e"}} {
+			if counts[pair[0]] <= counts[pair[1]] {
+				t.Errorf("window %d: %s picked %d times, not more than %s at %d", window, pair[0], counts[pair[0]], pair[1], counts[pair[1]])
+			}
+		}
+	}
+}
+
+func TestNoRepeatChooserProportional(t *testing.T) {
+	// With many light choices, excluding the last pick barely changes the
+	// distribution.
+	choices := make([]Choice, 50)
+	var total float64
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(i%5 + 1)}
+		total += float64(i%5 + 1)
+	}
+	nr, err := NewNoRepeatChooser(choices, WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 200000
+	counts := make([]int, len(choices))
+	for i := 0; i < n; i++ {
+		item, _ := nr.Pick()
+		counts[item.(int)]++
+	}
+	for i, c := range choices {
+		p := float64(c.Weight) / total
+		if got := float64(counts[i]) / n; math.Abs(got-p) > 0.2*p {
+			t.Errorf("choice %d picked %.4f of the time, want about %.4f", i, got, p)
+		}
+	}
+}
+
+func TestNoRepeatChooserWindowTooLarge(t *testing.T) {
+	choices := []Choice{{Item: "a", Weight: 5}, {Item: "b", Weight: 1}, {Item: "c", Weight: 1}, {Item: "zero"}}
+	nr, err := NewNoRepeatChooser(choices, WithNoImmediateRepeat(10), WithSeed(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nr.Window() != 2 {
+		t.Errorf("Window() = %d, want 2", nr.Window())
+	}
+	// The three choices must then cycle in a fixed order.
+	var first [3]interface{}
+	for i := 0; i < 300; i++ {
+		item, err := nr.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 3 {
+			first[i] = item
+		} else if item != first[i%3] {
+			t.Fatalf("pick %d = %v, want %v", i, item, first[i%3])
+		}
+	}
+
+	single, err := NewNoRepeatChooser([]Choice{{Item: "only", Weight: 1}, {Item: "zero"}}, WithNoImmediateRepeat(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if item, err := single.Pick(); err != nil || item != "only" {
+			t.Fatalf("Pick() = %v, %v; want only", item, err)
+		}
+	}
+}
+
+func TestNoRepeatChooserDuplicateItems(t *testing.T) {
+	// Equal items in separate choices are tracked separately; merged by
+	// WithDedup, they alternate with the other item.
+	choices := []Choice{{Item: "a", Weight: 1}, {Item: "a", Weight: 1}, {Item: "b", Weight: 1}}
+	nr, err := NewNoRepeatChooser(choices, WithDedup(nil), WithSeed(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nr.Window() != 1 {
+		t.Errorf("Window() = %d, want 1", nr.Window())
+	}
+	var last interface{}
+	for i := 0; i < 100; i++ {
+		item, err := nr.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item == last {
+			t.Fatalf("pick %d = %v, same as the one before", i, item)
+		}
+		last = item
+	}
+}
+
+func TestNoRepeatChooserOptions(t *testing.T) {
+	choices := []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 1}}
+	nr, err := NewNoRepeatChooser(choices)
+	if err != nil || nr.Window() != 1 || nr.Len() != 2 {
+		t.Fatalf("NewNoRepeatChooser() = %v, %v; want a window of 1 over 2 choices", nr, err)
+	}
+	a, _ := nr.Pick()
+	b, _ := nr.Pick()
+	if a == b {
+		t.Errorf("picked %v twice running", a)
+	}
+	nr.Reset()
+	if len(nr.recent) != 0 {
+		t.Errorf("Reset() left %v remembered", nr.recent)
+	}
+
+	off, err := NewNoRepeatChooser(choices, WithNoImmediateRepeat(0), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repeated := false
+	for i, prev := 0, interface{}(nil); i < 100; i++ {
+		item, _ := off.Pick()
+		repeated = repeated || item == prev
+		prev = item
+	}
+	if !repeated {
+		t.Error("a window of 0 never repeated a pick")
+	}
+
+	if _, err := NewNoRepeatChooser(choices, WithNoImmediateRepeat(-1)); err == nil {
+		t.Error("expected error for a negative window")
+	}
+	if _, err := NewNoRepeatChooser(nil); err != ErrNoChoices {
+		t.Errorf("NewNoRepeatChooser(nil) error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewNoRepeatChooser([]Choice{{Item: "a"}}); err != ErrAllZeroWeights {
+		t.Errorf("NewNoRepeatChooser() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+}
+
+// TestNoRepeatChooserConcurrent picks from many goroutines at once. Run with
+// -race.
+func TestNoRepeatChooserConcurrent(t *testing.T) {
+	nr, err := NewNoRepeatChooser(mockFrequencies(10), WithNoImmediateRepeat(3), WithParallelRand(), WithStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if _, err := nr.Pick(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := sumStats(nr.chs.Stats()); got != 8000 {
+		t.Errorf("stats count %d picks, want 8000", got)
+	}
+}
@@ -0,0 +1,136 @@
+package weightedrand
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// NormalizedWeights returns the probability of each choice held by the
+// Chooser, its weight divided by the total, in their original order. The
+// result sums to 1, up to rounding, unless the total is zero, in which case
+// every probability is zero.
+func (chs ChooserT[T]) NormalizedWeights() []float64 {
+	probs := make([]float64, len(chs.data))
+	total := float64(chs.TotalWeight())
+	if total == 0 {
+		return probs
+	}
+	for i, c := range chs.data {
+		probs[chs.index(i)] = float64(c.Weight) / total
+	}
+	return probs
+}
+
+// A ZeroPolicy tells Normalize what to do with a choice of nonzero weight whose
+// rescaled weight rounds to zero.
+type ZeroPolicy int
+
+const (
+	// KeepAsOne gives such choices a weight of 1, taken from the heaviest
+	// choices, so that they can still be picked.
+	KeepAsOne ZeroPolicy = iota
+	// DropZeros leaves such choices out of the result.
+	DropZeros
+)
+
+// Normalize returns a new Chooser holding the choices of chs, in their
+// original order, with their weights rescaled to sum to exactly scale. Each
+// weight is rounded down, and the units left over go to the choices with the
+// largest remainders, so that every weight is within 1 of its exact share.
+// Choices that round to zero are handled by policy; choices with a weight of
+// zero stay at zero. The result keeps the rand source of chs, and the options
+// kept when chs is mutated.
+//
+// An error is returned if chs cannot be picked from, if scale is zero, or if
+// policy is KeepAsOne and scale is smaller than the number of choices of
+// nonzero weight.
+func (chs ChooserT[T]) Normalize(scale uint, policy ZeroPolicy) (ChooserT[T], error) {
+	if err := chs.Err(); err != nil {
+		return ChooserT[T]{}, err
+	}
+	if scale == 0 {
+		return ChooserT[T]{}, errors.New("error: zero scale")
+	}
+	cs := chs.original()
+	positive := 0
+	for _, c := range cs {
+		if c.Weight > 0 {
+			positive++
+		}
+	}
+	if policy == KeepAsOne && uint64(positive) > uint64(scale) {
+		return ChooserT[T]{}, fmt.Errorf("error: scale %d too small for %d choices of nonzero weight", scale, positive)
+	}
+	shares := apportion(uint64(scale), chs.max, len(cs), func(i int) uint { return cs[i].Weight })
+
+	var rounded []int
+	for i, c := range cs {
+		if c.Weight > 0 && shares[i] == 0 {
+			rounded = append(rounded, i)
+		}
+		cs[i].Weight = uint(shares[i])
+	}
+	switch policy {
+	case KeepAsOne:
+		for _, i := range rounded {
+			cs[heaviest(cs)].Weight--
+			cs[i].Weight = 1
+		}
+	case DropZeros:
+		kept := cs[:0]
+		for i, c := range cs {
+			if len(rounded) > 0 && rounded[0] == i {
+				rounded = rounded[1:]
+				continue
+			}
+			kept = append(kept, c)
+		}
+		cs = kept
+	}
+
+	n := chs.derive(buildChooser(cs, false))
+	return n, n.Err()
+}
+
+// apportion divides n units among count shares, the i-th of weight(i) out of
+// a total weight of total, by largest remainder: each share gets its exact
+// part of n rounded down, and the units left over go one each to the shares
+// with the largest remainders, the earlier share on a tie. The shares sum to
+// exactly n, each is within 1 of its exact part, and a share of zero weight
+// gets nothing. total must be the nonzero sum of the weights.
+func apportion(n, total uint64, count int, weight func(i int) uint) []uint64 {
+	shares := make([]uint64, count)
+	rems := make([]uint64, count)
+	order := make([]int, 0, count)
+	left := n
+	for i := range shares {
+		w := weight(i)
+		if w == 0 {
+			continue
+		}
+		hi, lo := bits.Mul64(uint64(w), n)
+		shares[i], rems[i] = bits.Div64(hi, lo, total)
+		left -= shares[i]
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return rems[order[a]] > rems[order[b]]
+	})
+	for _, i := range order[:left] {
+		shares[i]++
+	}
+	return shares
+}
+
+// heaviest returns the index of the first choice of greatest weight in cs.
+func heaviest[T any](cs []ChoiceT[T]) int {
+	best := 0
+	for i, c := range cs {
+		if c.Weight > cs[best].Weight {
+			best = i
+		}
+	}
+	return best
+}
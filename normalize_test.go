@@ -0,0 +1,121 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizedWeights(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "c", Weight: 5},
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "b", Weight: 4},
+	)
+	if got, want := chooser.NormalizedWeights(), []float64{0.5, 0.1, 0, 0.4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizedWeights() = %v, want %v", got, want)
+	}
+	if got := NewChooser(Choice{Item: "a"}).NormalizedWeights(); !reflect.DeepEqual(got, []float64{0}) {
+		t.Errorf("NormalizedWeights() = %v for all zero weights, want [0]", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		n := rng.Intn(20) + 1
+		choices := make([]Choice, n)
+		for i := range choices {
+			choices[i] = Choice{Item: i, Weight: uint(rng.Intn(1000))}
+		}
+		choices[0].Weight++
+		chooser := NewChooser(choices...)
+		scale := uint(rng.Intn(1000) + n)
+		for _, policy := range []ZeroPolicy{KeepAsOne, DropZeros} {
+			norm, err := chooser.Normalize(scale, policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if norm.TotalWeight() != uint64(scale) {
+				t.Fatalf("TotalWeight() = %d, want exactly %d", norm.TotalWeight(), scale)
+			}
+			probs := chooser.NormalizedWeights()
+			j := 0
+			for _, c := range norm.Choices() {
+				for choices[j].Item != c.Item {
+					j++ // dropped
+				}
+				exact := probs[j] * float64(scale)
+				if c.Weight == 1 && exact < 1 && policy == KeepAsOne {
+					continue // kept at 1
+				}
+				// KeepAsOne takes a unit from a heavy choice per tiny one.
+				if math.Abs(float64(c.Weight)-exact) > 1+float64(n) {
+					t.Errorf("item %v rescaled to %d, want about %.2f", c.Item, c.Weight, exact)
+				}
+				if policy == DropZeros && math.Abs(float64(c.Weight)-exact) >= 1 {
+					t.Errorf("item %v rescaled to %d, want within 1 of %.2f", c.Item, c.Weight, exact)
+				}
+				j++
+			}
+		}
+	}
+
+	configured := configuredChooser(t, Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2})
+	normalized, err := configured.Normalize(100, KeepAsOne)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Normalize()", normalized, configured)
+}
+
+func TestNormalizePolicies(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "tiny", Weight: 1},
+		Choice{Item: "big", Weight: 996},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "small", Weight: 3},
+	)
+	keep, err := chooser.Normalize(10, KeepAsOne)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Choice{{Item: "tiny", Weight: 1}, {Item: "big", Weight: 8}, {Item: "zero", Weight: 0}, {Item: "small", Weight: 1}}
+	if got := keep.Choices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeepAsOne Choices() = %v, want %v", got, want)
+	}
+	drop, err := chooser.Normalize(10, DropZeros)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Choice{{Item: "big", Weight: 10}, {Item: "zero", Weight: 0}}
+	if got := drop.Choices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DropZeros Choices() = %v, want %v", got, want)
+	}
+
+	if _, err := chooser.Normalize(2, KeepAsOne); err == nil {
+		t.Error("expected error for a scale smaller than the number of choices")
+	}
+	if _, err := chooser.Normalize(0, DropZeros); err == nil {
+		t.Error("expected error for zero scale")
+	}
+	if _, err := NewChooser().Normalize(10, DropZeros); err != ErrNoChoices {
+		t.Errorf("Normalize() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	// Weights whose product with scale overflows 64 bits are still exact.
+	if is64Bit {
+		huge := NewChooser(Choice{Item: "a", Weight: uint(maxInt) / 4}, Choice{Item: "b", Weight: uint(maxInt) / 4 * 3})
+		norm, err := huge.Normalize(^uint(0), DropZeros)
+		if err != nil || norm.TotalWeight() != uint64(^uint(0)) {
+			t.Errorf("Normalize() = %v, %v; want weights summing to the largest uint", norm.Weights(), err)
+		}
+		shift := 38
+		norm, err = huge.Normalize(4<<shift, DropZeros)
+		if err != nil || !reflect.DeepEqual(norm.Weights(), []uint{1 << shift, 3 << shift}) {
+			t.Errorf("Normalize() = %v, %v; want [2^38 3*2^38]", norm.Weights(), err)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package weightedrand
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// An Option configures a chooser built by NewChooserOpts or NewChooserTOpts.
+// The options choosing a rand source apply to every constructor taking
+// Options; the others apply only where their documentation says, and any other
+// constructor given one returns an error.
+type Option func(*options)
+
+// An optionScope is a set of the kinds of constructor an Option applies to.
+type optionScope uint8
+
+const (
+	// chooserScope is the constructors building a ChooserT: NewChooserTOpts,
+	// NewLazyChooserT, NewNoRepeatChooserT and NewStratifiedChooserT.
+	chooserScope optionScope = 1 << iota
+	noRepeatScope
+	stratifiedScope
+	decayScope
+)
+
+// A scopedOption records that the Option called name was given, and the
+// constructors it applies to.
+type scopedOption struct {
+	name  string
+	scope optionScope
+}
+
+// options holds the configuration assembled from a list of Options.
+type options struct {
+	rng         source
+	rngSet      bool
+	parallel    bool
+	presorted   bool
+	borrowInput bool
+	clock       Clock
+	floor       float64
+	dropBelow   bool
+	stats       bool
+	lookupLimit int
+	compact     bool
+	invert      bool
+	dedup       bool
+	dedupKey    func(interface{}) string
+	onPick      func(item interface{}, index int)
+	fallback    interface{}
+	hasFallback bool
+	window      int
+	windowSet   bool
+	perN        int
+	perNSet     bool
+	scoped      []scopedOption
+}
+
+// restrict records that the Option called name applies only to the
+// constructors in scope.
+func (o *options) restrict(name string, scope optionScope) {
+	o.scoped = append(o.scoped, scopedOption{name: name, scope: scope})
+}
+
+// WithRand makes the chooser draw its random numbers from r rather than the
+// global source in math/rand. A nil r selects the global source explicitly.
+// A *rand.Rand is not safe for concurrent use, so neither is the chooser.
+func WithRand(r *rand.Rand) Option {
+	return func(o *options) {
+		o.rng = fromRand(r)
+		o.rngSet = true
+	}
+}
+
+// WithSeed makes the chooser draw its random numbers from its own source,
+// seeded with seed, so that its picks are reproducible regardless of any other
+// use of math/rand. Like WithRand, the chooser is then not safe for concurrent
+// use. Only a chooser built WithSeed can have its place in the sequence saved
+// by SaveState.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.rng = newSeededRand(seed, 0)
+		o.rngSet = true
+	}
+}
+
+// WithSource makes the chooser draw its random numbers from s, which may be a
+// *rand.Rand from math/rand/v2, such as one backed by PCG or ChaCha8, as well
+// as one from math/rand. A *rand.Rand from math/rand draws exactly as with
+// WithRand. A nil s selects the global source in math/rand. Unless s is safe
+// for concurrent use, neither is the chooser.
+func WithSource(s Source) Option {
+	return func(o *options) {
+		o.rng = fromSource(s)
+		o.rngSet = true
+	}
+}
+
+// WithParallelRand makes the chooser draw its random numbers from a pool of
+// independently seeded sources, as NewChooserParallel does. It cannot be
+// combined with WithRand, WithSeed or WithSource.
+func WithParallelRand() Option {
+	return func(o *options) {
+		o.parallel = true
+	}
+}
+
+// WithPresorted promises that the choices are already sorted by ascending
+// weight, so that construction skips the sort, and the table mapping sorted
+// positions back to input positions, taking O(n) time rather than O(n log n).
+// For input that really is sorted, the chooser is otherwise the same as one
+// from NewChooser, down to the items picked for a given seed. The promise is
+// not checked: picks from unsorted choices, kept in input order, are still
+// distributed correctly, but the mapping from random numbers to items differs
+// from that of NewChooser.
+func WithPresorted() Option {
+	return func(o *options) {
+		o.restrict("WithPresorted", chooserScope)
+		o.presorted = true
+	}
+}
+
+// WithoutSort is an alias for WithPresorted, for choices that are not sorted
+// but whose pick sequence for a given seed need not match that of NewChooser.
+func WithoutSort() Option {
+	return WithPresorted()
+}
+
+// WithInputCopy makes the chooser work on a copy of the choices, leaving the
+// caller's slice untouched. This is the default, so it only serves to override
+// an earlier WithBorrowedInput.
+func WithInputCopy() Option {
+	return func(o *options) {
+		o.restrict("WithInputCopy", chooserScope)
+		o.borrowInput = false
+	}
+}
+
+// WithBorrowedInput makes the chooser sort the caller's slice of choices in
+// place and keep it, saving the allocation and copy of a large input. The
+// caller must not modify the slice afterwards.
+func WithBorrowedInput() Option {
+	return func(o *options) {
+		o.restrict("WithBorrowedInput", chooserScope)
+		o.borrowInput = true
+	}
+}
+
+// WithStats makes the chooser count how many times each of its choices is
+// returned by Pick, MustPick, PickSource, PickWith, PickIndex, PickN,
+// FillPicks, FillPickIndices, PickSecure and Picks, for reporting by Stats. The
+// counters are updated atomically, so picks remain safe for concurrent use, and
+// are shared by copies of the chooser.
+func WithStats() Option {
+	return func(o *options) {
+		o.restrict("WithStats", chooserScope)
+		o.stats = true
+	}
+}
+
+// WithLookupTable makes the chooser precompute a lookup table from every
+// possible random value to its choice, if the total weight is at most
+// maxTotal, turning each pick into a single array index; a larger total falls
+// back to the usual binary search. Picks are identical either way, so this
+// only trades memory for speed.
+//
+// The table takes 2 bytes per unit of total weight for up to 65536 choices,
+// and 4 bytes otherwise: a total of 10000 costs about 20 KB. It is rebuilt,
+// subject to the same limit, whenever the chooser is mutated.
+func WithLookupTable(maxTotal int) Option {
+	return func(o *options) {
+		o.restrict("WithLookupTable", chooserScope)
+		o.lookupLimit = maxTotal
+	}
+}
+
+// WithCompactTotals makes the chooser store its cumulative totals in 32 bits
+// rather than 64 when the total weight fits, saving 4 bytes per choice and
+// fitting twice as many totals in each cache line searched by a pick. A
+// larger total keeps the wide totals, as does any total after a mutation
+// grows it past 32 bits. Picks are identical either way: a total that fits in
+// 32 bits is drawn as a 32-bit value regardless.
+//
+// Construction still sums the weights in 64 bits first, so only the memory
+// held afterwards is saved.
+func WithCompactTotals() Option {
+	return func(o *options) {
+		o.restrict("WithCompactTotals", chooserScope)
+		o.compact = true
+	}
+}
+
+// WithInvertedWeights makes the chooser favor the choices of smallest weight,
+// as when weights count recent uses and the least used should be picked most.
+// Each weight w is replaced by maxWeight-w+1, where maxWeight is the largest
+// weight of any choice, so that a weight of zero becomes the most likely and
+// equal weights stay equal. The one inverted weight that would not fit in a
+// uint, of a zero when maxWeight is the largest uint, is held at the largest
+// uint instead.
+//
+// The chooser holds the inverted weights, as reported by Choices, Weights and
+// Probability. Only construction inverts: weights given later to Add or
+// SetWeight are used as they are. With WithDedup, choices are merged before
+// their weights are inverted.
+func WithInvertedWeights() Option {
+	return func(o *options) {
+		o.restrict("WithInvertedWeights", chooserScope)
+		o.invert = true
+	}
+}
+
+// WithDedup makes the chooser merge choices holding the same item into one,
+// at the position of the first, with the sum of their weights. Items are the
+// same if key returns the same string for them or, with a nil key, if they are
+// equal by ==, which panics for items that are not comparable. A sum too large
+// for a uint makes the chooser unusable with ErrWeightOverflow. Merged reports
+// how many choices were merged away.
+//
+// Only construction merges: choices added later by Add are kept separate.
+func WithDedup(key func(item interface{}) string) Option {
+	return func(o *options) {
+		o.restrict("WithDedup", chooserScope)
+		o.dedup = true
+		o.dedupKey = key
+	}
+}
+
+// WithOnPick makes the chooser call fn synchronously after every selection made
+// by the methods counted by WithStats, once per item for PickN and the Fill
+// methods, with the item picked and its index as reported by PickIndex. It is
+// meant for metrics and tracing. fn runs on the hot path of every pick and must
+// be safe for concurrent use if picks are; a panic in fn propagates to the
+// caller of the pick. Without WithOnPick, picks make no call and no allocation.
+// The hook is kept when the chooser is mutated.
+func WithOnPick(fn func(item interface{}, index int)) Option {
+	return func(o *options) {
+		o.restrict("WithOnPick", chooserScope)
+		o.onPick = fn
+	}
+}
+
+// WithFallback makes the chooser return item, rather than ErrNoChoices or
+// ErrAllZeroWeights, from Pick, MustPick, PickSource, PickWith, PickN,
+// PickNParallel, FillPicks and PickSecure when it has no choice of nonzero
+// weight. The fallback takes no part in weighted selection, is not counted by
+// Len or by WithStats, and is reported to the WithOnPick hook with an index of
+// -1. Err still reports the underlying problem, so that it can be alerted on,
+// and so do the methods returning indices. A nil item stands for the zero T;
+// any other item must be a T, or the chooser is unusable.
+func WithFallback(item interface{}) Option {
+	return func(o *options) {
+		o.restrict("WithFallback", chooserScope)
+		o.fallback = item
+		o.hasFallback = true
+	}
+}
+
+// WithNoImmediateRepeat makes a NoRepeatChooser remember its last window picks
+// and never return any of them again until window further picks have been
+// made, rather than the default of 1. A window of 0 disables the suppression.
+func WithNoImmediateRepeat(window int) Option {
+	return func(o *options) {
+		o.restrict("WithNoImmediateRepeat", noRepeatScope)
+		o.window = window
+		o.windowSet = true
+	}
+}
+
+// WithMinimumShare makes a StratifiedChooser return every choice of nonzero
+// weight at least once in any perN consecutive picks.
+func WithMinimumShare(perN int) Option {
+	return func(o *options) {
+		o.restrict("WithMinimumShare", stratifiedScope)
+		o.perN = perN
+		o.perNSet = true
+	}
+}
+
+// WithClock makes a DecayChooser read the time from c rather than the system
+// clock, so that tests can control how weights decay.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.restrict("WithClock", decayScope)
+		o.clock = c
+	}
+}
+
+// WithDecayFloor makes a DecayChooser clamp decayed weights to at least floor,
+// so that an item never becomes less likely than that however long ago it was
+// boosted.
+func WithDecayFloor(floor float64) Option {
+	return func(o *options) {
+		o.restrict("WithDecayFloor", decayScope)
+		o.floor = floor
+		o.dropBelow = false
+	}
+}
+
+// WithDecayDropBelow makes a DecayChooser drop items whose decayed weight has
+// fallen below floor, as if they had never been boosted.
+func WithDecayDropBelow(floor float64) Option {
+	return func(o *options) {
+		o.restrict("WithDecayDropBelow", decayScope)
+		o.floor = floor
+		o.dropBelow = true
+	}
+}
+
+// buildOptions applies opts in order, reporting invalid combinations and any
+// option that applies to none of the constructors in accept.
+func buildOptions(opts []Option, accept optionScope) (options, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for _, so := range o.scoped {
+		if so.scope&accept == 0 {
+			return options{}, fmt.Errorf("error: %s does not apply to this chooser", so.name)
+		}
+	}
+	if o.parallel {
+		if o.rngSet {
+			return options{}, errors.New("error: WithParallelRand cannot be combined with WithRand, WithSeed or WithSource")
+		}
+		o.rng = newPoolSource()
+	}
+	return o, nil
+}
+
+// NewChooserTOpts initializes a new ChooserT consisting of the possible
+// ChoiceT[T], configured by opts. It returns an error for an option meant only
+// for another kind of chooser or an invalid combination of options, and
+// otherwise for any problem NewChooserTErr would report that WithFallback does
+// not cover.
+func NewChooserTOpts[T any](cs []ChoiceT[T], opts ...Option) (ChooserT[T], error) {
+	o, err := buildOptions(opts, chooserScope)
+	if err != nil {
+		return ChooserT[T]{}, err
+	}
+	chs := newChooser(cs, o)
+	if _, ok := chs.fallbackFor(chs.Err()); ok {
+		return chs, nil
+	}
+	return chs, chs.Err()
+}
+
+// NewChooserOpts initializes a new Chooser consisting of the possible Choices,
+// configured by opts. See NewChooserTOpts.
+func NewChooserOpts(cs []Choice, opts ...Option) (Chooser, error) {
+	return NewChooserTOpts(cs, opts...)
+}
@@ -0,0 +1,426 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithRand(t *testing.T) {
+	choices := make([]Choice, 20)
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(i)}
+	}
+	c1, err := NewChooserOpts(choices, WithRand(rand.New(rand.NewSource(7))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := NewChooserWithRand(rand.New(rand.NewSource(7)), choices...)
+	for i := 0; i < 100; i++ {
+		if a, b := c1.MustPick(), c2.MustPick(); a != b {
+			t.Fatalf("pick %d: %v != %v", i, a, b)
+		}
+	}
+
+	c3, err := NewChooserOpts(choices, WithRand(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3.rng != nil {
+		t.Error("WithRand(nil) did not select the global source")
+	}
+}
+
+func TestWithParallelRand(t *testing.T) {
+	chooser, err := NewChooserOpts([]Choice{{Item: 1, Weight: 1}}, WithParallelRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := chooser.rng.(poolSource); !ok {
+		t.Errorf("rng = %T, want poolSource", chooser.rng)
+	}
+	if _, err := NewChooserOpts([]Choice{{Item: 1, Weight: 1}}, WithParallelRand(), WithRand(nil)); err == nil {
+		t.Error("expected error combining WithParallelRand and WithRand")
+	}
+}
+
+func TestOptionScope(t *testing.T) {
+	choices := []Choice{{Item: 1, Weight: 1}, {Item: 2, Weight: 1}}
+	newChooser := func(opt Option) error {
+		_, err := NewChooserOpts(choices, opt)
+		return err
+	}
+	newDecay := func(opt Option) error {
+		_, err := NewDecayChooser(time.Second, opt)
+		return err
+	}
+	newIndex := func(opt Option) error {
+		_, err := NewIndexChooser([]uint{1, 1}, opt)
+		return err
+	}
+	newReservoir := func(opt Option) error {
+		_, err := NewReservoir(1, opt)
+		return err
+	}
+	for _, tc := range []struct {
+		name  string
+		build func(Option) error
+		opt   Option
+	}{
+		{"WithClock", newChooser, WithClock(nil)},
+		{"WithDecayFloor", newChooser, WithDecayFloor(1)},
+		{"WithDecayDropBelow", newChooser, WithDecayDropBelow(1)},
+		{"WithNoImmediateRepeat", newChooser, WithNoImmediateRepeat(1)},
+		{"WithMinimumShare", newChooser, WithMinimumShare(2)},
+		{"WithStats", newDecay, WithStats()},
+		{"WithDedup", newIndex, WithDedup(nil)},
+		{"WithFallback", newReservoir, WithFallback(0)},
+	} {
+		want := "error: " + tc.name + " does not apply to this chooser"
+		if err := tc.build(tc.opt); err == nil || err.Error() != want {
+			t.Errorf("%s: error = %v, want %q", tc.name, err, want)
+		}
+	}
+	if _, err := NewIndexChooser([]uint{1}, WithSeed(1)); err != nil {
+		t.Errorf("NewIndexChooser() error = %v with WithSeed", err)
+	}
+}
+
+func TestWithPresorted(t *testing.T) {
+	// Unsorted input is kept as is, and picks stay correct.
+	choices := []Choice{{Item: "c", Weight: 3}, {Item: "zero", Weight: 0}, {Item: "a", Weight: 1}}
+	chooser, err := NewChooserOpts(choices, WithPresorted())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint64{3, 3, 4}; !reflect.DeepEqual(chooser.totals, want) {
+		t.Errorf("totals = %v, want %v", chooser.totals, want)
+	}
+	if choices[0].Item != "c" {
+		t.Error("WithPresorted sorted the input")
+	}
+	counts := map[interface{}]int{}
+	chooser.rng = rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		counts[chooser.MustPick()]++
+	}
+	if counts["zero"] != 0 || counts["a"] > counts["c"] {
+		t.Errorf("unexpected distribution %v", counts)
+	}
+}
+
+func TestWithInputCopy(t *testing.T) {
+	choices := []Choice{{Item: "c", Weight: 3}, {Item: "b", Weight: 2}, {Item: "a", Weight: 1}}
+	orig := append([]Choice(nil), choices...)
+	chooser, err := NewChooserOpts(choices, WithInputCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(choices, orig) {
+		t.Errorf("input modified to %v", choices)
+	}
+	if chooser.data[0].Item != "a" {
+		t.Errorf("chooser data not sorted: %v", chooser.data)
+	}
+	choices[0].Weight = 100
+	if chooser.data[2].Weight != 3 {
+		t.Error("chooser shares storage with the input")
+	}
+}
+
+func TestOptionsCombined(t *testing.T) {
+	choices := []Choice{{Item: "c", Weight: 3}, {Item: "a", Weight: 1}}
+	orig := append([]Choice(nil), choices...)
+	c1, err := NewChooserOpts(choices, WithInputCopy(), WithPresorted(), WithRand(rand.New(rand.NewSource(3))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(choices, orig) || !reflect.DeepEqual(c1.data, orig) {
+		t.Errorf("input %v and data %v should both be unchanged", choices, c1.data)
+	}
+	c2, _ := NewChooserOpts(choices, WithInputCopy(), WithPresorted(), WithRand(rand.New(rand.NewSource(3))))
+	for i := 0; i < 100; i++ {
+		if a, b := c1.MustPick(), c2.MustPick(); a != b {
+			t.Fatalf("pick %d: %v != %v", i, a, b)
+		}
+	}
+
+	if _, err := NewChooserOpts(nil, WithInputCopy()); err != ErrNoChoices {
+		t.Errorf("got error %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func TestWithoutSort(t *testing.T) {
+	choices := make([]Choice, 30)
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(rand.Intn(10))}
+	}
+	unsorted, err := NewChooserOpts(choices, WithoutSort(), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, _ := NewChooserOpts(choices, WithSeed(2))
+	if !reflect.DeepEqual(unsorted.data, choices) || unsorted.indices != nil {
+		t.Errorf("WithoutSort reordered the choices: %v", unsorted.data)
+	}
+
+	// Both choosers should produce the same distribution.
+	const n = 200000
+	a, b := make([]int, len(choices)), make([]int, len(choices))
+	for i := 0; i < n; i++ {
+		a[unsorted.MustPick().(int)]++
+		b[sorted.MustPick().(int)]++
+	}
+	for i, c := range choices {
+		if c.Weight == 0 && a[i] != 0 {
+			t.Errorf("zero weight choice %d picked %d times", i, a[i])
+		}
+		if diff := float64(a[i]-b[i]) / n; diff < -0.005 || diff > 0.005 {
+			t.Errorf("choice %d picked %d times unsorted, %d sorted", i, a[i], b[i])
+		}
+	}
+
+	// Indices are the input positions, unchanged.
+	for i := 0; i < 100; i++ {
+		idx, _ := unsorted.PickIndex()
+		if choices[idx].Weight == 0 {
+			t.Fatalf("PickIndex() = %d, a zero weight choice", idx)
+		}
+	}
+}
+
+func BenchmarkNewChooserWithoutSort(b *testing.B) {
+	for n := BMminChoices; n <= BMmaxChoices; n *= 10 {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			choices := mockChoices(n)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_, _ = NewChooserOpts(choices, WithoutSort())
+			}
+		})
+	}
+}
+
+func TestWithOnPick(t *testing.T) {
+	choices := []Choice{
+		{Item: "c", Weight: 3},
+		{Item: "a", Weight: 1},
+		{Item: "zero", Weight: 0},
+	}
+	calls := 0
+	var seen []interface{}
+	chooser, err := NewChooserOpts(choices, WithSeed(1), WithOnPick(func(item interface{}, index int) {
+		calls++
+		seen = append(seen, item)
+		if choices[index].Item != item {
+			t.Errorf("hook got item %v with index %d, which holds %v", item, index, choices[index].Item)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chooser.MustPick()
+	chooser.PickSource(nil)
+	chooser.PickIndex()
+	seen = nil
+	items, _ := chooser.PickN(50)
+	if calls != 53 {
+		t.Errorf("hook called %d times for 53 picks", calls)
+	}
+	if !reflect.DeepEqual(seen, items) {
+		t.Errorf("hook saw %v, PickN returned %v", seen, items)
+	}
+
+	// The hook survives mutation, and errors are not reported to it.
+	chooser.Add(Choice{Item: "b", Weight: 1})
+	chooser.MustPick()
+	if calls != 54 {
+		t.Errorf("hook called %d times after Add, want 54", calls)
+	}
+	empty, _ := NewChooserOpts(nil, WithOnPick(func(interface{}, int) { calls++ }))
+	empty.Pick()
+	empty.PickN(3)
+	if calls != 54 {
+		t.Errorf("hook called for failed picks")
+	}
+
+	// A panic in the hook reaches the caller.
+	boom, _ := NewChooserOpts(choices, WithOnPick(func(interface{}, int) { panic("boom") }))
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want the hook's panic", r)
+		}
+	}()
+	boom.Pick()
+	t.Error("Pick() returned despite the hook panicking")
+}
+
+func TestWithFallback(t *testing.T) {
+	var hooked []int
+	onPick := WithOnPick(func(item interface{}, index int) {
+		if item != "default" {
+			t.Errorf("hook got %v, want the fallback", item)
+		}
+		hooked = append(hooked, index)
+	})
+	cases := map[string]struct {
+		choices []Choice
+		err     error
+	}{
+		"empty":    {nil, ErrNoChoices},
+		"all zero": {[]Choice{{Item: "a"}, {Item: "b"}}, ErrAllZeroWeights},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hooked = nil
+			chooser, err := NewChooserOpts(tc.choices, WithFallback("default"), onPick)
+			if err != nil {
+				t.Fatalf("NewChooserOpts() error = %v, want nil with a fallback", err)
+			}
+			if err := chooser.Err(); err != tc.err {
+				t.Errorf("Err() = %v, want %v", err, tc.err)
+			}
+			if chooser.Len() != len(tc.choices) {
+				t.Errorf("Len() = %d, want %d", chooser.Len(), len(tc.choices))
+			}
+			for _, pick := range []func() (interface{}, error){
+				chooser.Pick,
+				chooser.PickSecure,
+				func() (interface{}, error) { return chooser.PickSource(rand.New(rand.NewSource(1))) },
+				func() (interface{}, error) { return chooser.MustPick(), nil },
+			} {
+				if item, err := pick(); err != nil || item != "default" {
+					t.Errorf("pick = %v, %v; want the fallback", item, err)
+				}
+			}
+			items, err := chooser.PickN(3)
+			if want := []interface{}{"default", "default", "default"}; err != nil || !reflect.DeepEqual(items, want) {
+				t.Errorf("PickN(3) = %v, %v; want %v", items, err, want)
+			}
+			items, err = chooser.PickNParallel(3, 2)
+			if want := []interface{}{"default", "default", "default"}; err != nil || !reflect.DeepEqual(items, want) {
+				t.Errorf("PickNParallel(3, 2) = %v, %v; want %v", items, err, want)
+			}
+			if want := []int{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1}; !reflect.DeepEqual(hooked, want) {
+				t.Errorf("hook indices = %v, want %v", hooked, want)
+			}
+			if _, err := chooser.PickIndex(); err != tc.err {
+				t.Errorf("PickIndex() error = %v, want %v", err, tc.err)
+			}
+		})
+	}
+
+	// The fallback is never returned while a choice can be picked, and
+	// survives the chooser being mutated.
+	chooser, err := NewChooserOpts([]Choice{{Item: "a", Weight: 1}, {Item: "b"}}, WithFallback("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if item := chooser.MustPick(); item != "a" {
+			t.Fatalf("Pick() = %v, want a", item)
+		}
+	}
+	chooser.SetWeight("a", 0)
+	if item, err := chooser.Pick(); err != nil || item != "default" {
+		t.Errorf("Pick() = %v, %v after zeroing every weight; want the fallback", item, err)
+	}
+	chooser.Add(Choice{Item: "c", Weight: 2})
+	if item := chooser.MustPick(); item != "c" {
+		t.Errorf("Pick() = %v after Add, want c", item)
+	}
+
+	// Overflow is not covered.
+	if is64Bit {
+		if _, err := NewChooserOpts([]Choice{{Weight: ^uint(0)}, {Weight: 1}}, WithFallback("default")); err != ErrWeightOverflow {
+			t.Errorf("NewChooserOpts() error = %v, want %v", err, ErrWeightOverflow)
+		}
+	}
+}
+
+func TestWithFallbackTyped(t *testing.T) {
+	chooser, err := NewChooserTOpts([]ChoiceT[int]{{Item: 1}}, WithFallback(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item, err := chooser.Pick(); err != nil || item != 7 {
+		t.Errorf("Pick() = %v, %v; want 7", item, err)
+	}
+	if chooser, _ := NewChooserTOpts([]ChoiceT[int]{{Item: 1}}, WithFallback(nil)); chooser.MustPick() != 0 {
+		t.Errorf("Pick() = %v with a nil fallback, want 0", chooser.MustPick())
+	}
+	if _, err := NewChooserTOpts([]ChoiceT[int]{{Item: 1, Weight: 1}}, WithFallback("seven")); err == nil {
+		t.Error("expected error for a fallback that is not a T")
+	}
+}
+
+func BenchmarkPickOnPick(b *testing.B) {
+	for _, hooked := range []bool{false, true} {
+		name := "none"
+		var opts []Option
+		if hooked {
+			name = "hook"
+			var n int
+			opts = append(opts, WithOnPick(func(interface{}, int) { n++ }))
+		}
+		chooser, _ := NewChooserOpts(mockFrequencies(1000), opts...)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				chooser.Pick()
+			}
+		})
+	}
+}
+
+func TestWithInvertedWeights(t *testing.T) {
+	choices := []Choice{
+		{Item: "used often", Weight: 7},
+		{Item: "unused", Weight: 0},
+		{Item: "used once", Weight: 1},
+		{Item: "used some", Weight: 4},
+	}
+	chooser, err := NewChooserOpts(choices, WithInvertedWeights(), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{1, 8, 7, 4}; !reflect.DeepEqual(chooser.Weights(), want) {
+		t.Errorf("Weights() = %v, want %v", chooser.Weights(), want)
+	}
+	if choices[0].Weight != 7 {
+		t.Error("inverting modified the input")
+	}
+
+	// The empirical frequencies are ordered opposite to the raw weights.
+	shares := pickShares(t, chooser, 40000)
+	order := []interface{}{"unused", "used once", "used some", "used often"}
+	for i := 1; i < len(order); i++ {
+		if shares[order[i-1]] <= shares[order[i]] {
+			t.Errorf("%v picked %.3f of the time, no more than %v at %.3f", order[i-1], shares[order[i-1]], order[i], shares[order[i]])
+		}
+	}
+	assertShares(t, shares, map[interface{}]float64{"unused": 0.4, "used once": 0.35, "used some": 0.2, "used often": 0.05})
+
+	equal, _ := NewChooserOpts([]Choice{{Item: "a", Weight: 5}, {Item: "b", Weight: 5}}, WithInvertedWeights())
+	if want := []uint{1, 1}; !reflect.DeepEqual(equal.Weights(), want) {
+		t.Errorf("Weights() = %v for equal weights, want %v", equal.Weights(), want)
+	}
+	zeros, err := NewChooserOpts([]Choice{{Item: "a", Weight: 0}}, WithInvertedWeights())
+	if err != nil || zeros.MustPick() != "a" {
+		t.Errorf("NewChooserOpts() = %v, %v for all zero weights, want a chooser of a", zeros.Choices(), err)
+	}
+	if _, err := NewChooserOpts(nil, WithInvertedWeights()); err != ErrNoChoices {
+		t.Errorf("NewChooserOpts() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	// The extremes of uint invert without wrapping around.
+	max := ^uint(0)
+	extremes, _ := NewChooserOpts([]Choice{{Item: "max", Weight: max}, {Item: "one", Weight: 1}, {Item: "zero", Weight: 0}}, WithInvertedWeights())
+	if want := []uint{1, max, max}; !reflect.DeepEqual(extremes.Weights(), want) {
+		t.Errorf("Weights() = %v, want %v", extremes.Weights(), want)
+	}
+}
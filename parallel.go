@@ -0,0 +1,61 @@
+package weightedrand
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// PickNParallel returns n weighted random Choice.Items from the Chooser,
+// selected with replacement as by PickN, but split across workers goroutines
+// for very large batches. A workers of zero or less uses runtime.GOMAXPROCS.
+//
+// Each worker draws from its own source, seeded by a draw from the Chooser's
+// source, and fills its own contiguous chunk of the result: worker i fills
+// items [i*n/workers, (i+1)*n/workers). The output is therefore reproducible
+// for a given seed, n and number of workers, though it differs from that of
+// PickN. Any hook set by WithOnPick is called from the workers concurrently.
+func (chs ChooserT[T]) PickNParallel(n, workers int) ([]T, error) {
+	if n < 0 {
+		return nil, errors.New("error: negative n")
+	}
+	if err := chs.Err(); err != nil {
+		if _, ok := chs.fallbackFor(err); !ok {
+			return nil, err
+		}
+		// The fallback needs no random numbers, so no workers.
+		items := make([]T, n)
+		chs.FillPicks(items)
+		return items, nil
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	seeds := make([]int64, workers)
+	for w := range seeds {
+		seeds[w] = int63n(chs.rng, math.MaxInt64)
+	}
+
+	items := make([]T, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rs := rand.New(rand.NewSource(seeds[w]))
+			chunk := items[w*n/workers : (w+1)*n/workers]
+			for i := range chunk {
+				j := chs.pick(rs)
+				chs.observe(j)
+				chunk[i] = chs.data[j].Item
+			}
+		}(w)
+	}
+	wg.Wait()
+	return items, nil
+}
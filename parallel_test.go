@@ -0,0 +1,69 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestPickNParallel(t *testing.T) {
+	choices := []Choice{
+		{Item: "a", Weight: 1},
+		{Item: "zero", Weight: 0},
+		{Item: "b", Weight: 3},
+		{Item: "c", Weight: 6},
+	}
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)), choices...)
+	const n = 100003 // not a multiple of the worker count
+	items, err := chooser.PickNParallel(n, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != n {
+		t.Fatalf("got %d items, want %d", len(items), n)
+	}
+	shares := make(map[interface{}]float64)
+	for _, item := range items {
+		if item == nil {
+			t.Fatal("unfilled item")
+		}
+		shares[item] += 1.0 / n
+	}
+	assertShares(t, shares, map[interface{}]float64{"a": 0.1, "b": 0.3, "c": 0.6})
+
+	// The same seed and worker count reproduce the output exactly.
+	again, _ := NewChooserWithRand(rand.New(rand.NewSource(1)), choices...).PickNParallel(n, 4)
+	if !reflect.DeepEqual(items, again) {
+		t.Error("PickNParallel() differs between runs with the same seed")
+	}
+	other, _ := NewChooserWithRand(rand.New(rand.NewSource(2)), choices...).PickNParallel(n, 4)
+	if reflect.DeepEqual(items, other) {
+		t.Error("PickNParallel() identical for different seeds")
+	}
+
+	if items, err := chooser.PickNParallel(3, 0); err != nil || len(items) != 3 {
+		t.Errorf("PickNParallel(3, 0) = %v, %v", items, err)
+	}
+	if items, err := chooser.PickNParallel(0, 4); err != nil || len(items) != 0 {
+		t.Errorf("PickNParallel(0, 4) = %v, %v", items, err)
+	}
+	if _, err := chooser.PickNParallel(-1, 4); err == nil {
+		t.Error("expected error for negative n")
+	}
+	if _, err := NewChooser().PickNParallel(10, 4); err != ErrNoChoices {
+		t.Errorf("PickNParallel() error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func BenchmarkPickNParallel(b *testing.B) {
+	chooser := NewChooser(mockFrequencies(1000)...)
+	const n = 1000000
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(strconv.Itoa(workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				chooser.PickNParallel(n, workers)
+			}
+		})
+	}
+}
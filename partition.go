@@ -0,0 +1,68 @@
+package weightedrand
+
+import "math/rand"
+
+// PartitionT shuffles items and cuts them into len(weights) groups, the i-th
+// sized in proportion to weights[i], as when assigning a fixed list of users
+// to experiment arms of exact sizes. Group sizes are apportioned by largest
+// remainder: each gets its exact share of len(items) rounded down, and the
+// items left over go one each to the groups with the largest remainders, the
+// earlier group on a tie. The sizes therefore always sum to len(items), and
+// each is within 1 of its exact share; a group of zero weight is empty.
+//
+// Every item appears in exactly one group, the groups holding the shuffled
+// items in order; which items land in which group is random, drawn from r, or
+// from the global source in math/rand if r is nil. items is not modified, and
+// the groups share storage with one another but not with items, with their
+// capacities clipped so that appending to one leaves the others intact.
+//
+// ErrNoChoices is returned for empty weights, ErrAllZeroWeights if every
+// weight is zero, and ErrWeightOverflow if they sum to more than the largest
+// uint64.
+func PartitionT[T any](items []T, weights []uint, r *rand.Rand) ([][]T, error) {
+	total, err := partitionTotal(weights)
+	if err != nil {
+		return nil, err
+	}
+	sizes := apportion(uint64(len(items)), total, len(weights), func(i int) uint { return weights[i] })
+	rs := fromRand(r)
+	shuffled := append([]T(nil), items...)
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j := intn(rs, i+1)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+	groups := make([][]T, len(weights))
+	start := 0
+	for i, size := range sizes {
+		end := start + int(size)
+		groups[i] = shuffled[start:end:end]
+		start = end
+	}
+	return groups, nil
+}
+
+// Partition shuffles items and cuts them into groups sized in proportion to
+// weights. See PartitionT.
+func Partition(items []interface{}, weights []uint, r *rand.Rand) ([][]interface{}, error) {
+	return PartitionT(items, weights, r)
+}
+
+// partitionTotal returns the sum of weights, reporting ErrNoChoices if there
+// are none, ErrAllZeroWeights if it is zero, and ErrWeightOverflow if it
+// exceeds maxTotal.
+func partitionTotal(weights []uint) (uint64, error) {
+	if len(weights) == 0 {
+		return 0, ErrNoChoices
+	}
+	var total uint64
+	for _, w := range weights {
+		if uint64(w) > maxTotal-total {
+			return 0, ErrWeightOverflow
+		}
+		total += uint64(w)
+	}
+	if total == 0 {
+		return 0, ErrAllZeroWeights
+	}
+	return total, nil
+}
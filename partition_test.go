@@ -0,0 +1,117 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPartition(t *testing.T) {
+	users := make([]interface{}, 1000)
+	for i := range users {
+		users[i] = i
+	}
+	cases := []struct {
+		n       int
+		weights []uint
+		want    []int
+	}{
+		{1000, []uint{60, 30, 10}, []int{600, 300, 100}},
+		{10, []uint{1, 1, 1}, []int{4, 3, 3}},           // ties go to the earlier group
+		{7, []uint{50, 0, 30, 20}, []int{4, 0, 2, 1}},   // 3.5, 2.1, 1.4
+		{100, []uint{1, 2, 3, 0}, []int{17, 33, 50, 0}}, // 16.67, 33.33, 50
+		{0, []uint{3, 1}, []int{0, 0}},
+		{1, []uint{1, 5, 1}, []int{0, 1, 0}},
+		{3, []uint{^uint(0) / 2, ^uint(0) / 2}, []int{2, 1}},
+	}
+	for _, tc := range cases {
+		groups, err := Partition(users[:tc.n], tc.weights, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes := make([]int, len(groups))
+		var all []int
+		for i, g := range groups {
+			sizes[i] = len(g)
+			for _, item := range g {
+				all = append(all, item.(int))
+			}
+		}
+		if !reflect.DeepEqual(sizes, tc.want) {
+			t.Errorf("Partition(%d, %v) sizes = %v, want %v", tc.n, tc.weights, sizes, tc.want)
+		}
+		sort.Ints(all)
+		for i, v := range all {
+			if v != i {
+				t.Fatalf("Partition(%d, %v) items = %v, want each of 0 to %d once", tc.n, tc.weights, all, tc.n-1)
+			}
+		}
+		if len(all) != tc.n {
+			t.Errorf("Partition(%d, %v) holds %d items", tc.n, tc.weights, len(all))
+		}
+	}
+	for i, u := range users {
+		if u != i {
+			t.Fatal("Partition modified items")
+		}
+	}
+
+	groups, _ := PartitionT([]string{"a", "b", "c"}, []uint{1, 1, 1}, nil)
+	groups[0] = append(groups[0], "x")
+	if groups[1][0] == "x" {
+		t.Error("appending to one group overwrote another")
+	}
+}
+
+func TestPartitionRandomness(t *testing.T) {
+	// Over many runs, each item lands in the first of two equal groups
+	// about half the time.
+	items := []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	r := rand.New(rand.NewSource(2))
+	const runs = 4000
+	first := make([]int, len(items))
+	for run := 0; run < runs; run++ {
+		groups, err := Partition(items, []uint{1, 1}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range groups[0] {
+			first[item.(int)]++
+		}
+	}
+	for i, n := range first {
+		// The standard deviation of n is sqrt(runs/4) = 31.6.
+		if n < runs/2-160 || n > runs/2+160 {
+			t.Errorf("item %d in the first group %d of %d times", i, n, runs)
+		}
+	}
+
+	a, _ := Partition(items, []uint{1, 1}, rand.New(rand.NewSource(3)))
+	b, _ := Partition(items, []uint{1, 1}, rand.New(rand.NewSource(3)))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Partition() with the same seed gave %v and %v", a, b)
+	}
+}
+
+func TestPartitionErrors(t *testing.T) {
+	items := []interface{}{"a", "b"}
+	cases := []struct {
+		weights []uint
+		want    error
+	}{
+		{nil, ErrNoChoices},
+		{[]uint{0, 0}, ErrAllZeroWeights},
+	}
+	if is64Bit {
+		cases = append(cases, struct {
+			weights []uint
+			want    error
+		}{[]uint{^uint(0), 1}, ErrWeightOverflow})
+	}
+	for _, tc := range cases {
+		if groups, err := Partition(items, tc.weights, nil); err != tc.want || groups != nil {
+			t.Errorf("Partition(%v) = %v, %v; want %v", tc.weights, groups, err, tc.want)
+		}
+	}
+}
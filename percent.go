@@ -0,0 +1,91 @@
+package weightedrand
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// A PercentageOption configures the sum required by NewChooserTFromPercentages
+// and its variants.
+type PercentageOption func(*percentageOptions)
+
+// percentageOptions holds the configuration assembled from a list of
+// PercentageOptions.
+type percentageOptions struct {
+	sum uint64
+}
+
+// AllowSum makes NewChooserTFromPercentages require the weights to sum to n
+// rather than 100, as for permille or basis points.
+func AllowSum(n uint) PercentageOption {
+	return func(o *percentageOptions) {
+		o.sum = uint64(n)
+	}
+}
+
+// NewChooserTFromPercentages initializes a new ChooserT whose choices are the
+// keys of m, weighted by their values, as NewChooserFromMap does, but only if
+// the values sum to exactly 100, or to the total set by AllowSum. Otherwise an
+// error giving the actual sum is returned. Keys with a value of zero are kept
+// but never picked.
+func NewChooserTFromPercentages[K comparable](m map[K]uint, opts ...PercentageOption) (ChooserT[K], error) {
+	chs := NewChooserFromMap(m)
+	if err := checkSum(chs, opts); err != nil {
+		return ChooserT[K]{}, err
+	}
+	return chs, nil
+}
+
+// NewChooserFromPercentages initializes a new Chooser whose choices are the
+// keys of m, weighted by their values, laid out as by NewChooserFromMap. See
+// NewChooserTFromPercentages.
+func NewChooserFromPercentages(m map[interface{}]uint, opts ...PercentageOption) (Chooser, error) {
+	cs := make([]Choice, 0, len(m))
+	for k, w := range m {
+		cs = append(cs, Choice{Item: k, Weight: w})
+	}
+	sortMapChoices(cs)
+	chs := buildChooser(cs, true)
+	if err := checkSum(chs, opts); err != nil {
+		return Chooser{}, err
+	}
+	return chs, nil
+}
+
+// NewChooserTFromPercentageChoices initializes a new ChooserT consisting of
+// the possible ChoiceT[T], as NewChooserT does, but only if their weights sum
+// to exactly 100, or to the total set by AllowSum. See
+// NewChooserTFromPercentages.
+func NewChooserTFromPercentageChoices[T any](cs []ChoiceT[T], opts ...PercentageOption) (ChooserT[T], error) {
+	chs := NewChooserT(cs...)
+	if err := checkSum(chs, opts); err != nil {
+		return ChooserT[T]{}, err
+	}
+	return chs, nil
+}
+
+// NewChooserFromPercentageChoices initializes a new Chooser consisting of the
+// possible Choices. See NewChooserTFromPercentageChoices.
+func NewChooserFromPercentageChoices(cs []Choice, opts ...PercentageOption) (Chooser, error) {
+	return NewChooserTFromPercentageChoices(cs, opts...)
+}
+
+// checkSum reports an error unless the weights of chs sum to the total
+// required by opts.
+func checkSum[T any](chs ChooserT[T], opts []PercentageOption) error {
+	o := percentageOptions{sum: 100}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.sum == 0 {
+		return errors.New("error: required sum is zero")
+	}
+	if chs.Err() == ErrWeightOverflow {
+		return fmt.Errorf("error: weights sum to more than %d, want %d", uint64(math.MaxUint64), o.sum)
+	}
+	if total := chs.TotalWeight(); total != o.sum {
+		return fmt.Errorf("error: weights sum to %d, want %d", total, o.sum)
+	}
+	return nil
+}
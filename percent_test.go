@@ -0,0 +1,91 @@
+package weightedrand
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewChooserFromPercentages(t *testing.T) {
+	chooser, err := NewChooserFromPercentages(map[interface{}]uint{"a": 70, "b": 30, "off": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", chooser.Len())
+	}
+	shares := pickShares(t, chooser, 20000)
+	assertShares(t, shares, map[interface{}]float64{"a": 0.7, "b": 0.3})
+	if shares["off"] != 0 {
+		t.Errorf("picked zero entry %.3f of the time", shares["off"])
+	}
+
+	typed, err := NewChooserTFromPercentages(map[string]uint{"x": 100})
+	if err != nil || typed.MustPick() != "x" {
+		t.Errorf("NewChooserTFromPercentages() = %v, %v; want a chooser of x", typed.Choices(), err)
+	}
+}
+
+func TestNewChooserFromPercentagesSum(t *testing.T) {
+	cases := []struct {
+		name string
+		m    map[string]uint
+		opts []PercentageOption
+		want string // error, empty if none
+	}{
+		{"exact", map[string]uint{"a": 50, "b": 25, "c": 25}, nil, ""},
+		{"one under", map[string]uint{"a": 50, "b": 49}, nil, "error: weights sum to 99, want 100"},
+		{"one over", map[string]uint{"a": 50, "b": 51}, nil, "error: weights sum to 101, want 100"},
+		{"only zeros", map[string]uint{"a": 0, "b": 0}, nil, "error: weights sum to 0, want 100"},
+		{"empty", nil, nil, "error: weights sum to 0, want 100"},
+		{"permille", map[string]uint{"a": 999, "b": 1}, []PercentageOption{AllowSum(1000)}, ""},
+		{"basis points", map[string]uint{"a": 2500, "b": 7500, "c": 0}, []PercentageOption{AllowSum(10000)}, ""},
+		{"basis points off", map[string]uint{"a": 2500, "b": 7499}, []PercentageOption{AllowSum(10000)}, "error: weights sum to 9999, want 10000"},
+		{"100 with other sum", map[string]uint{"a": 100}, []PercentageOption{AllowSum(1000)}, "error: weights sum to 100, want 1000"},
+		{"zero sum", map[string]uint{"a": 0}, []PercentageOption{AllowSum(0)}, "error: required sum is zero"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chooser, err := NewChooserTFromPercentages(tc.m, tc.opts...)
+			switch {
+			case tc.want == "" && err != nil:
+				t.Errorf("NewChooserTFromPercentages() error = %v", err)
+			case tc.want == "" && chooser.Err() != nil:
+				t.Errorf("Err() = %v", chooser.Err())
+			case tc.want != "" && (err == nil || err.Error() != tc.want):
+				t.Errorf("NewChooserTFromPercentages() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+
+	if !is64Bit {
+		return
+	}
+	_, err := NewChooserFromPercentages(map[interface{}]uint{"a": ^uint(0), "b": 1})
+	if want := "error: weights sum to more than 18446744073709551615, want 100"; err == nil || err.Error() != want {
+		t.Errorf("NewChooserFromPercentages() error = %v, want %q", err, want)
+	}
+	if _, err := NewChooserTFromPercentages(map[string]uint{"a": ^uint(0)}, AllowSum(^uint(0))); err != nil {
+		t.Errorf("NewChooserTFromPercentages() error = %v for a sum of MaxUint64", err)
+	}
+}
+
+func TestNewChooserTFromPercentageChoices(t *testing.T) {
+	choices := []ChoiceT[string]{{Item: "a", Weight: 60}, {Item: "b", Weight: 0}, {Item: "a", Weight: 40}}
+	chooser, err := NewChooserTFromPercentageChoices(choices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := chooser.Probability("a"); math.Abs(p-1) > 1e-12 {
+		t.Errorf("Probability(a) = %v, want 1", p)
+	}
+	if _, err := NewChooserTFromPercentageChoices(choices[:2]); err == nil || err.Error() != "error: weights sum to 60, want 100" {
+		t.Errorf("NewChooserTFromPercentageChoices() error = %v", err)
+	}
+	if _, err := NewChooserTFromPercentageChoices(choices[:2], AllowSum(60)); err != nil {
+		t.Errorf("NewChooserTFromPercentageChoices() error = %v with AllowSum(60)", err)
+	}
+	untyped, err := NewChooserFromPercentageChoices([]Choice{{Item: "x", Weight: 100}})
+	if err != nil || untyped.Len() != 1 {
+		t.Errorf("NewChooserFromPercentageChoices() = %v, %v; want a chooser of x", untyped.Choices(), err)
+	}
+}
@@ -0,0 +1,197 @@
+package weightedrand
+
+import (
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// A QuotaChooserT picks weighted random items whose weights are budgets, such
+// as ad impressions purchased: each pick of an item uses up one unit of its
+// quota, making it that much less likely to be picked again, and an item whose
+// quota is used up is not picked until it is refilled. Unlike an UrnT, which
+// removes its winner whole, an item survives as many picks as its quota
+// allows. Once every quota is used up, Pick returns ErrQuotaExhausted.
+//
+// Choices holding equal items are merged into one, at the position of the
+// first, with the sum of their quotas, so that an item has a single quota for
+// Remaining and Refill to address. Items are used as map keys, so this panics
+// if T is an interface type holding an item that is not comparable.
+//
+// The quotas are kept in a Fenwick tree, as for an UrnT, so each pick takes
+// O(log n) time to find its winner and to take one unit out of the running
+// sums. A QuotaChooserT guards its quotas with a mutex, so it is safe for
+// concurrent use if its rand source is.
+type QuotaChooserT[T any] struct {
+	mu    sync.Mutex
+	data  []ChoiceT[T]        // each Weight is the quota left
+	index map[interface{}]int // position in data of each item
+	tree  []uint64            // Fenwick tree of the quotas left
+	total uint64
+	rng   source
+}
+
+// A QuotaChooser is a QuotaChooserT over untyped items.
+type QuotaChooser = QuotaChooserT[interface{}]
+
+// NewQuotaChooserT initializes a new QuotaChooserT with the possible
+// ChoiceT[T], each weight being the item's quota, which draws from the global
+// source in math/rand. An error is returned for any set of choices
+// NewChooserTErr would reject, or if the quotas of equal items sum to more
+// than a uint holds.
+func NewQuotaChooserT[T any](cs ...ChoiceT[T]) (*QuotaChooserT[T], error) {
+	return NewQuotaChooserTWithRand(nil, cs...)
+}
+
+// NewQuotaChooserTWithRand initializes a new QuotaChooserT with the possible
+// ChoiceT[T], which draws its random numbers from r. A nil r falls back to the
+// global source in math/rand. See NewQuotaChooserT.
+func NewQuotaChooserTWithRand[T any](r *rand.Rand, cs ...ChoiceT[T]) (*QuotaChooserT[T], error) {
+	if len(cs) == 0 {
+		return nil, ErrNoChoices
+	}
+	data, _, err := dedup(cs, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := &QuotaChooserT[T]{
+		data:  data,
+		index: make(map[interface{}]int, len(data)),
+		tree:  make([]uint64, len(data)+1),
+		rng:   fromRand(r),
+	}
+	for i, c := range data {
+		q.index[c.Item] = i
+		if uint64(c.Weight) > maxTotal-q.total {
+			return nil, ErrWeightOverflow
+		}
+		q.total += uint64(c.Weight)
+	}
+	if q.total == 0 {
+		return nil, ErrAllZeroWeights
+	}
+	q.build()
+	return q, nil
+}
+
+// NewQuotaChooser initializes a new QuotaChooser with the possible Choices.
+// See NewQuotaChooserT.
+func NewQuotaChooser(cs ...Choice) (*QuotaChooser, error) {
+	return NewQuotaChooserT(cs...)
+}
+
+// NewQuotaChooserWithRand initializes a new QuotaChooser with the possible
+// Choices, which draws its random numbers from r. See
+// NewQuotaChooserTWithRand.
+func NewQuotaChooserWithRand(r *rand.Rand, cs ...Choice) (*QuotaChooser, error) {
+	return NewQuotaChooserTWithRand(r, cs...)
+}
+
+// build fills the Fenwick tree from the quotas in q.data.
+func (q *QuotaChooserT[T]) build() {
+	for i := range q.tree {
+		q.tree[i] = 0
+	}
+	for i, c := range q.data {
+		j := i + 1
+		q.tree[j] += uint64(c.Weight)
+		if k := j + j&-j; k < len(q.tree) {
+			q.tree[k] += q.tree[j]
+		}
+	}
+}
+
+// Pick returns a weighted random Choice.Item from among those with quota
+// left, using up one unit of its quota. ErrQuotaExhausted is returned once
+// every quota is used up.
+func (q *QuotaChooserT[T]) Pick() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.total == 0 {
+		var zero T
+		return zero, ErrQuotaExhausted
+	}
+	i := q.find(uint64n(q.rng, q.total))
+	q.data[i].Weight--
+	q.add(i, ^uint64(0))
+	q.total--
+	return q.data[i].Item, nil
+}
+
+// find returns the index into q.data of the choice whose share of the running
+// sums of the quotas left holds r, which must be below q.total.
+func (q *QuotaChooserT[T]) find(r uint64) int {
+	pos := 0
+	for step := 1 << (bits.Len(uint(len(q.data))) - 1); step > 0; step >>= 1 {
+		if next := pos + step; next < len(q.tree) && q.tree[next] <= r {
+			pos = next
+			r -= q.tree[next]
+		}
+	}
+	return pos
+}
+
+// add adds delta, which wraps around to subtract, to the quota at index i in
+// the running sums.
+func (q *QuotaChooserT[T]) add(i int, delta uint64) {
+	for j := i + 1; j < len(q.tree); j += j & -j {
+		q.tree[j] += delta
+	}
+}
+
+// Remaining returns the quota left for item, and whether the QuotaChooser
+// holds item at all.
+func (q *QuotaChooserT[T]) Remaining(item T) (uint, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, ok := q.index[item]
+	if !ok {
+		return 0, false
+	}
+	return q.data[i].Weight, true
+}
+
+// Refill adds n to the quota left for item, adding item as a new choice if the
+// QuotaChooser does not yet hold it, which takes time linear in the number of
+// items to rebuild the running sums. ErrWeightOverflow is returned, and nothing
+// changed, if the quota would no longer fit in a uint or the quotas would sum
+// to more than a uint64 holds.
+func (q *QuotaChooserT[T]) Refill(item T, n uint) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if uint64(n) > maxTotal-q.total {
+		return ErrWeightOverflow
+	}
+	i, ok := q.index[item]
+	if !ok {
+		q.index[item] = len(q.data)
+		q.data = append(q.data, ChoiceT[T]{Item: item, Weight: n})
+		q.tree = append(q.tree, 0)
+		q.build()
+		q.total += uint64(n)
+		return nil
+	}
+	sum, carry := bits.Add(q.data[i].Weight, n, 0)
+	if carry != 0 {
+		return ErrWeightOverflow
+	}
+	q.data[i].Weight = sum
+	q.add(i, uint64(n))
+	q.total += uint64(n)
+	return nil
+}
+
+// TotalRemaining returns the sum of the quotas left.
+func (q *QuotaChooserT[T]) TotalRemaining() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.total
+}
+
+// Len returns the number of items the QuotaChooser holds, whether or not
+// their quotas are used up.
+func (q *QuotaChooserT[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.data)
+}
@@ -0,0 +1,169 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestQuotaChooser(t *testing.T) {
+	choices := []Choice{
+		{Item: "a", Weight: 5},
+		{Item: "b", Weight: 1},
+		{Item: "zero", Weight: 0},
+		{Item: "c", Weight: 30},
+		{Item: "a", Weight: 2}, // merged into the first a
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		q, err := NewQuotaChooserWithRand(rand.New(rand.NewSource(seed)), choices...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Len() != 4 || q.TotalRemaining() != 38 {
+			t.Fatalf("Len(), TotalRemaining() = %d, %d; want 4, 38", q.Len(), q.TotalRemaining())
+		}
+		counts := make(map[interface{}]int)
+		for i := 0; i < 38; i++ {
+			item, err := q.Pick()
+			if err != nil {
+				t.Fatalf("pick %d: %v", i, err)
+			}
+			counts[item]++
+		}
+		if counts["a"] != 7 || counts["b"] != 1 || counts["c"] != 30 || counts["zero"] != 0 {
+			t.Errorf("seed %d: drained counts = %v, want each quota exactly", seed, counts)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := q.Pick(); err != ErrQuotaExhausted {
+				t.Fatalf("Pick() error = %v once drained, want %v", err, ErrQuotaExhausted)
+			}
+		}
+		if left, ok := q.Remaining("a"); left != 0 || !ok {
+			t.Errorf("Remaining(a) = %d, %v; want 0, true", left, ok)
+		}
+	}
+}
+
+func TestQuotaChooserDistribution(t *testing.T) {
+	// The first pick is weighted by the full quotas.
+	counts := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	const n = 20000
+	for i := 0; i < n; i++ {
+		q, _ := NewQuotaChooserTWithRand(r, ChoiceT[int]{Item: 0, Weight: 1}, ChoiceT[int]{Item: 1, Weight: 3})
+		item, _ := q.Pick()
+		counts[item]++
+	}
+	if got := float64(counts[1]) / n; got < 0.74 || got > 0.76 {
+		t.Errorf("first pick of the heavier item %.4f of the time, want 0.75", got)
+	}
+}
+
+func TestQuotaChooserRefill(t *testing.T) {
+	q, err := NewQuotaChooserT(ChoiceT[string]{Item: "a", Weight: 1}, ChoiceT[string]{Item: "b", Weight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Pick()
+	q.Pick()
+	if _, err := q.Pick(); err != ErrQuotaExhausted {
+		t.Fatalf("Pick() error = %v, want %v", err, ErrQuotaExhausted)
+	}
+	if err := q.Refill("b", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Refill("new", 2); err != nil {
+		t.Fatal(err)
+	}
+	if left, ok := q.Remaining("new"); left != 2 || !ok || q.Len() != 3 {
+		t.Errorf("Remaining(new) = %d, %v with Len() %d; want 2, true, 3", left, ok, q.Len())
+	}
+	if _, ok := q.Remaining("missing"); ok {
+		t.Error("Remaining(missing) reported an item never added")
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 5; i++ {
+		item, err := q.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[item]++
+	}
+	if counts["a"] != 0 || counts["b"] != 3 || counts["new"] != 2 {
+		t.Errorf("counts after Refill = %v, want b 3 and new 2", counts)
+	}
+	if _, err := q.Pick(); err != ErrQuotaExhausted {
+		t.Errorf("Pick() error = %v, want %v", err, ErrQuotaExhausted)
+	}
+
+	if err := q.Refill("a", ^uint(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Refill("a", 1); err != ErrWeightOverflow {
+		t.Errorf("Refill() error = %v for a quota past the largest uint, want %v", err, ErrWeightOverflow)
+	}
+	if left, _ := q.Remaining("a"); left != ^uint(0) {
+		t.Errorf("Remaining(a) = %d after a failed Refill", left)
+	}
+}
+
+func TestQuotaChooserErrors(t *testing.T) {
+	if _, err := NewQuotaChooser(); err != ErrNoChoices {
+		t.Errorf("NewQuotaChooser() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewQuotaChooser(Choice{Item: "a"}); err != ErrAllZeroWeights {
+		t.Errorf("NewQuotaChooser() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if _, err := NewQuotaChooser(Choice{Item: "a", Weight: ^uint(0)}, Choice{Item: "a", Weight: 1}); err != ErrWeightOverflow {
+		t.Errorf("NewQuotaChooser() error = %v for merged quotas past the largest uint, want %v", err, ErrWeightOverflow)
+	}
+}
+
+func TestQuotaChooserConcurrent(t *testing.T) {
+	q, err := NewQuotaChooser(mockFrequencies(50)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := int(q.TotalRemaining())
+	var mu sync.Mutex
+	counts := make(map[interface{}]int)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, err := q.Pick()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				counts[item]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	picked := 0
+	for i, c := range mockFrequencies(50) {
+		if counts[c.Item] != int(c.Weight) {
+			t.Errorf("choice %d picked %d times, want %d", i, counts[c.Item], c.Weight)
+		}
+		picked += counts[c.Item]
+	}
+	if picked != total {
+		t.Errorf("picked %d times, want %d", picked, total)
+	}
+}
+
+func BenchmarkQuotaChooserPick(b *testing.B) {
+	q, _ := NewQuotaChooser(mockFrequencies(1000)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := q.Pick(); err != nil {
+			b.StopTimer()
+			q.Refill(0, ^uint(0)>>1)
+			b.StartTimer()
+		}
+	}
+}
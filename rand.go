@@ -0,0 +1,195 @@
+package weightedrand
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// A Source supplies random numbers to a chooser configured with WithSource.
+// The *rand.Rand types of both math/rand and math/rand/v2 satisfy it, as does
+// any generator of uniformly distributed 64-bit values.
+type Source interface {
+	Uint64() uint64
+}
+
+// A source supplies the random numbers for a chooser. A nil source stands for
+// the global source in math/rand.
+type source interface {
+	Intn(n int) int
+	Int63n(n int64) int64
+	Uint64() uint64
+	Float64() float64
+}
+
+// fromRand returns r as a source, keeping a nil r nil rather than wrapping it
+// in a non-nil interface.
+func fromRand(r *rand.Rand) source {
+	if r == nil {
+		return nil
+	}
+	return r
+}
+
+// fromSource returns s as a source. A *rand.Rand from math/rand, or anything
+// else with its methods, is used directly, so that it draws exactly as with
+// WithRand. The methods of a *rand.Rand from math/rand/v2 are used where
+// present, and otherwise every draw is derived from Uint64. A nil s is kept
+// nil.
+func fromSource(s Source) source {
+	switch s := s.(type) {
+	case nil:
+		return nil
+	case *rand.Rand:
+		return fromRand(s)
+	case source:
+		return s
+	case v2Rand:
+		return v2Source{s}
+	default:
+		return uint64Source{s}
+	}
+}
+
+// v2Rand is the subset of the methods of a *rand.Rand from math/rand/v2 used
+// by v2Source.
+type v2Rand interface {
+	IntN(n int) int
+	Int64N(n int64) int64
+	Uint64() uint64
+	Float64() float64
+}
+
+// v2Source adapts a v2Rand to a source.
+type v2Source struct {
+	r v2Rand
+}
+
+func (s v2Source) Intn(n int) int       { return s.r.IntN(n) }
+func (s v2Source) Int63n(n int64) int64 { return s.r.Int64N(n) }
+func (s v2Source) Uint64() uint64       { return s.r.Uint64() }
+func (s v2Source) Float64() float64     { return s.r.Float64() }
+
+// uint64Source adapts any Source to a source.
+type uint64Source struct {
+	s Source
+}
+
+func (s uint64Source) Intn(n int) int       { return int(s.uint64n(uint64(n))) }
+func (s uint64Source) Int63n(n int64) int64 { return int64(s.uint64n(uint64(n))) }
+func (s uint64Source) Uint64() uint64       { return s.s.Uint64() }
+
+// Float64 returns the top 53 bits of a draw as a float64 in [0.0,1.0).
+func (s uint64Source) Float64() float64 {
+	return float64(s.s.Uint64()>>11) / (1 << 53)
+}
+
+// uint64n returns a uniformly distributed uint64 in [0,n), using Lemire's
+// multiply-and-reject method to avoid the bias of reducing modulo n.
+func (s uint64Source) uint64n(n uint64) uint64 {
+	hi, lo := bits.Mul64(s.s.Uint64(), n)
+	if lo < n {
+		thresh := -n % n
+		for lo < thresh {
+			hi, lo = bits.Mul64(s.s.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// intn returns a random int in [0,n) from rs, or from the global source in
+// math/rand when rs is nil.
+func intn(rs source, n int) int {
+	if rs == nil {
+		return rand.Intn(n)
+	}
+	return rs.Intn(n)
+}
+
+// int63n returns a random int64 in [0,n) from rs, or from the global source in
+// math/rand when rs is nil. For n that fits in 32 bits it draws exactly as intn
+// does, so that sequences are the same on every platform and match those
+// drawn before totals were widened to 64 bits.
+func int63n(rs source, n int64) int64 {
+	if n <= math.MaxInt32 {
+		return int64(intn(rs, int(n)))
+	}
+	if rs == nil {
+		return rand.Int63n(n)
+	}
+	return rs.Int63n(n)
+}
+
+// uint64n returns a random uint64 in [0,n) from rs, or from the global source
+// in math/rand when rs is nil. For n up to math.MaxInt64 it draws exactly as
+// int63n does; above that, more than half of all 64-bit values are below n,
+// so it simply draws until one is.
+func uint64n(rs source, n uint64) uint64 {
+	if n <= math.MaxInt64 {
+		return uint64(int63n(rs, int64(n)))
+	}
+	for {
+		var v uint64
+		if rs == nil {
+			v = rand.Uint64()
+		} else {
+			v = rs.Uint64()
+		}
+		if v < n {
+			return v
+		}
+	}
+}
+
+// float64n returns a random float64 in [0.0,1.0) from rs, or from the global
+// source in math/rand when rs is nil.
+func float64n(rs source) float64 {
+	if rs == nil {
+		return rand.Float64()
+	}
+	return rs.Float64()
+}
+
+// poolSource is a source backed by a pool of independently seeded *rand.Rand,
+// so that concurrent callers rarely share one and never contend on the mutex
+// guarding the global source.
+type poolSource struct {
+	pool *sync.Pool
+}
+
+func newPoolSource() poolSource {
+	return poolSource{pool: &sync.Pool{
+		New: func() interface{} {
+			return rand.New(rand.NewSource(rand.Int63()))
+		},
+	}}
+}
+
+func (p poolSource) Intn(n int) int {
+	r := p.pool.Get().(*rand.Rand)
+	v := r.Intn(n)
+	p.pool.Put(r)
+	return v
+}
+
+func (p poolSource) Int63n(n int64) int64 {
+	r := p.pool.Get().(*rand.Rand)
+	v := r.Int63n(n)
+	p.pool.Put(r)
+	return v
+}
+
+func (p poolSource) Uint64() uint64 {
+	r := p.pool.Get().(*rand.Rand)
+	v := r.Uint64()
+	p.pool.Put(r)
+	return v
+}
+
+func (p poolSource) Float64() float64 {
+	r := p.pool.Get().(*rand.Rand)
+	v := r.Float64()
+	p.pool.Put(r)
+	return v
+}
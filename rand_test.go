@@ -0,0 +1,191 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// TestNewChooserParallel picks concurrently from a pool-backed chooser. Run
+// with -race.
+func TestNewChooserParallel(t *testing.T) {
+	chooser := NewChooserParallel(
+		Choice{Item: 0, Weight: 0},
+		Choice{Item: 1, Weight: 1},
+		Choice{Item: 2, Weight: 3},
+	)
+
+	const goroutines, n = 16, 10000
+	counts := make([][3]int, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				item, err := chooser.Pick()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				counts[g][item.(int)]++
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	var total [3]int
+	for _, c := range counts {
+		for i := range c {
+			total[i] += c[i]
+		}
+	}
+	if total[0] != 0 {
+		t.Errorf("zero weight item picked %d times", total[0])
+	}
+	if got := float64(total[2]) / (goroutines * n); got < 0.74 || got > 0.76 {
+		t.Errorf("heavier item picked %.3f of the time, want 0.75", got)
+	}
+}
+
+func BenchmarkPickParallel(b *testing.B) {
+	choices := mockChoices(1000)
+
+	b.Run("global", func(b *testing.B) {
+		chooser := NewChooser(choices...)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				chooser.Pick()
+			}
+		})
+	})
+	b.Run("pool", func(b *testing.B) {
+		chooser := NewChooserParallel(choices...)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				chooser.Pick()
+			}
+		})
+	})
+}
+
+func TestUint64n(t *testing.T) {
+	// Up to math.MaxInt64, draws go through Int63n exactly as before.
+	for _, n := range []uint64{1, 6, math.MaxInt32, math.MaxInt32 + 1, math.MaxInt64} {
+		a, b := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			if got, want := uint64n(a, n), uint64(int63n(b, int64(n))); got != want {
+				t.Fatalf("uint64n(%d) = %d, want %d", n, got, want)
+			}
+		}
+	}
+
+	// Beyond it, whole Uint64 values are drawn, rejecting those past n.
+	const n = math.MaxInt64 + 10
+	s := scriptedUint64{n, math.MaxUint64, n + 1, n - 1, math.MaxInt64 + 1}
+	rs := fromSource(&s)
+	for _, want := range []uint64{n - 1, math.MaxInt64 + 1} {
+		if got := uint64n(rs, n); got != want {
+			t.Errorf("uint64n(%d) = %d, want %d", uint64(n), got, want)
+		}
+	}
+	if len(s) != 0 {
+		t.Errorf("%d draws left unused, want 0", len(s))
+	}
+	if got := uint64n(nil, math.MaxUint64); got == math.MaxUint64 {
+		t.Errorf("uint64n(MaxUint64) from the global source = %d, want less", got)
+	}
+}
+
+// scriptedUint64 is a Source returning its values in order.
+type scriptedUint64 []uint64
+
+func (s *scriptedUint64) Uint64() uint64 {
+	v := (*s)[0]
+	*s = (*s)[1:]
+	return v
+}
+
+// splitMix is a minimal Source offering nothing but Uint64.
+type splitMix uint64
+
+func (s *splitMix) Uint64() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+func TestWithSource(t *testing.T) {
+	choices := []Choice{
+		{Item: "a", Weight: 1},
+		{Item: "b", Weight: 3},
+		{Item: "c", Weight: 6},
+	}
+
+	// A math/rand *rand.Rand draws exactly as with WithRand.
+	viaRand, _ := NewChooserOpts(choices, WithRand(rand.New(rand.NewSource(42))))
+	viaSource, _ := NewChooserOpts(choices, WithSource(rand.New(rand.NewSource(42))))
+	for i := 0; i < 1000; i++ {
+		if a, b := viaRand.MustPick(), viaSource.MustPick(); a != b {
+			t.Fatalf("pick %d: WithSource picked %v, WithRand %v", i, b, a)
+		}
+	}
+
+	var nilRand *rand.Rand
+	if chooser, err := NewChooserOpts(choices, WithSource(nilRand)); err != nil || chooser.rng != nil {
+		t.Errorf("WithSource(nil *rand.Rand) = %v, %v; want the global source", chooser.rng, err)
+	}
+	if _, err := NewChooserOpts(choices, WithSource(new(splitMix)), WithParallelRand()); err == nil {
+		t.Error("expected error combining WithSource and WithParallelRand")
+	}
+
+	// A bare Uint64 generator is adapted.
+	s := splitMix(1)
+	chooser, err := NewChooserOpts(choices, WithSource(&s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[interface{}]float64{"a": 0.1, "b": 0.3, "c": 0.6}
+	assertShares(t, pickShares(t, chooser, 20000), want)
+
+	other := splitMix(2)
+	shares := make(map[interface{}]float64)
+	for i := 0; i < 20000; i++ {
+		item, err := viaRand.PickWith(&other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[item] += 1.0 / 20000
+	}
+	assertShares(t, shares, want)
+}
+
+func TestUint64Source(t *testing.T) {
+	s := splitMix(7)
+	u := uint64Source{&s}
+	const n = 60000
+	var counts [3]int
+	for i := 0; i < n; i++ {
+		v := u.Intn(3)
+		if v < 0 || v >= 3 {
+			t.Fatalf("Intn(3) = %d", v)
+		}
+		counts[v]++
+	}
+	for v, c := range counts {
+		if c < n/3-600 || c > n/3+600 {
+			t.Errorf("Intn(3) returned %d %d times of %d", v, c, n)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		if f := u.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("Float64() = %v", f)
+		}
+		if v := u.Int63n(1 << 40); v < 0 || v >= 1<<40 {
+			t.Fatalf("Int63n(1<<40) = %d", v)
+		}
+	}
+}
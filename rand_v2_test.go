@@ -0,0 +1,52 @@
+//go:build go1.22
+
+package weightedrand
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// TestWithSourceV2 picks through math/rand/v2 generators, which should give
+// the same distribution as math/rand.
+func TestWithSourceV2(t *testing.T) {
+	choices := []Choice{
+		{Item: "a", Weight: 1},
+		{Item: "b", Weight: 3},
+		{Item: "c", Weight: 6},
+	}
+	want := map[interface{}]float64{"a": 0.1, "b": 0.3, "c": 0.6}
+	for name, src := range map[string]rand.Source{
+		"PCG":     rand.NewPCG(1, 2),
+		"ChaCha8": rand.NewChaCha8([32]byte{1}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := rand.New(src)
+			chooser, err := NewChooserOpts(choices, WithSource(r))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := chooser.rng.(v2Source); !ok {
+				t.Errorf("rng is %T, want v2Source", chooser.rng)
+			}
+			assertShares(t, pickShares(t, chooser, 20000), want)
+
+			// A bare v2 Source is adapted through its Uint64.
+			chooser, _ = NewChooserOpts(choices, WithSource(src))
+			assertShares(t, pickShares(t, chooser, 20000), want)
+		})
+	}
+
+	// Large totals draw through Int64N.
+	big := NewChooser(Choice{Item: "x", Weight: 1 << 30}, Choice{Item: "y", Weight: 3 << 30})
+	shares := make(map[interface{}]float64)
+	r := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 20000; i++ {
+		item, err := big.PickWith(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[item] += 1.0 / 20000
+	}
+	assertShares(t, shares, map[interface{}]float64{"x": 0.25, "y": 0.75})
+}
@@ -0,0 +1,72 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// RatChoiceT is a choice of Item whose weight is the fraction Num/Den, for
+// probabilities specified exactly, such as 1/3 and 2/7.
+type RatChoiceT[T any] struct {
+	Item     T
+	Num, Den uint
+}
+
+// RatChoice is a RatChoiceT over untyped items.
+type RatChoice = RatChoiceT[interface{}]
+
+// NewChooserTFromRatios initializes a new ChooserT consisting of the possible
+// RatChoiceT[T], with each fraction scaled to an exact integer weight: every
+// fraction is reduced to lowest terms, and its weight is then Num*L/Den, where
+// L is the least common multiple of the reduced denominators. The weights are
+// thus in exactly the proportions of the fractions, and TotalWeight divided by
+// L is their sum, which need not be 1. As with NewChooserTErr, an error is
+// returned if the Chooser cannot be picked from.
+//
+// A zero denominator is reported as ErrInvalidWeight. ErrWeightOverflow is
+// reported if L or a weight does not fit in a uint, as can happen for many
+// large, coprime denominators; such fractions cannot be weighted exactly, and
+// a FloatChooser over their float64 values, or a common denominator rounded
+// by hand to fewer digits, is the next best thing.
+func NewChooserTFromRatios[T any](rc ...RatChoiceT[T]) (ChooserT[T], error) {
+	nums := make([]uint, len(rc))
+	dens := make([]uint, len(rc))
+	lcm := uint(1)
+	for i, c := range rc {
+		if c.Den == 0 {
+			return ChooserT[T]{}, fmt.Errorf("%w %d/0 for choice %d", ErrInvalidWeight, c.Num, i)
+		}
+		g := gcd(c.Num, c.Den)
+		nums[i], dens[i] = c.Num/g, c.Den/g
+		hi, lo := bits.Mul(lcm/gcd(lcm, dens[i]), dens[i])
+		if hi != 0 {
+			return ChooserT[T]{}, fmt.Errorf("%w: common denominator of choices 0 to %d", ErrWeightOverflow, i)
+		}
+		lcm = lo
+	}
+	cs := make([]ChoiceT[T], len(rc))
+	for i, c := range rc {
+		hi, w := bits.Mul(nums[i], lcm/dens[i])
+		if hi != 0 {
+			return ChooserT[T]{}, fmt.Errorf("%w: weight %d/%d scaled by %d", ErrWeightOverflow, c.Num, c.Den, lcm)
+		}
+		cs[i] = ChoiceT[T]{Item: c.Item, Weight: w}
+	}
+	chs := buildChooser(cs, false)
+	return chs, chs.Err()
+}
+
+// NewChooserFromRatios initializes a new Chooser consisting of the possible
+// RatChoices. See NewChooserTFromRatios.
+func NewChooserFromRatios(rc ...RatChoice) (Chooser, error) {
+	return NewChooserTFromRatios(rc...)
+}
+
+// gcd returns the greatest common divisor of a and b, or the other if either is
+// zero.
+func gcd(a, b uint) uint {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
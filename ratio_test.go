@@ -0,0 +1,73 @@
+package weightedrand
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewChooserFromRatios(t *testing.T) {
+	cases := []struct {
+		name  string
+		rc    []RatChoice
+		want  []uint
+		prob  []float64
+		total uint64
+	}{
+		{"thirds", []RatChoice{{"a", 1, 3}, {"b", 1, 3}, {"c", 1, 3}}, []uint{1, 1, 1}, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, 3},
+		{"halves to sixths", []RatChoice{{"a", 1, 2}, {"b", 1, 3}, {"c", 1, 6}}, []uint{3, 2, 1}, []float64{0.5, 1.0 / 3, 1.0 / 6}, 6},
+		{"unreduced", []RatChoice{{"a", 2, 4}, {"b", 3, 6}}, []uint{1, 1}, []float64{0.5, 0.5}, 2},
+		{"sevenths", []RatChoice{{"a", 2, 7}, {"b", 1, 3}, {"c", 8, 21}}, []uint{6, 7, 8}, []float64{2.0 / 7, 1.0 / 3, 8.0 / 21}, 21},
+		{"zero", []RatChoice{{"a", 0, 5}, {"b", 1, 1}}, []uint{0, 1}, []float64{0, 1}, 1},
+		{"not summing to one", []RatChoice{{"a", 1, 2}, {"b", 1, 4}}, []uint{2, 1}, []float64{2.0 / 3, 1.0 / 3}, 3},
+	}
+	for _, tc := range cases {
+		chooser, err := NewChooserFromRatios(tc.rc...)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := chooser.Weights(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Weights() = %v, want %v", tc.name, got, tc.want)
+		}
+		if got := chooser.TotalWeight(); got != tc.total {
+			t.Errorf("%s: TotalWeight() = %d, want %d", tc.name, got, tc.total)
+		}
+		if got := chooser.NormalizedWeights(); !reflect.DeepEqual(got, tc.prob) {
+			t.Errorf("%s: NormalizedWeights() = %v, want %v", tc.name, got, tc.prob)
+		}
+	}
+
+	typed, err := NewChooserTFromRatios(RatChoiceT[int]{Item: 7, Num: 1, Den: 9})
+	if err != nil || typed.MustPick() != 7 {
+		t.Errorf("NewChooserTFromRatios() = %v, %v; want a chooser of 7", typed.Choices(), err)
+	}
+}
+
+func TestNewChooserFromRatiosErrors(t *testing.T) {
+	primes := []uint{65521, 65519, 65497, 65479, 65449}
+	coprime := make([]RatChoice, len(primes))
+	for i, p := range primes {
+		coprime[i] = RatChoice{Item: i, Num: 1, Den: p}
+	}
+	cases := []struct {
+		name string
+		rc   []RatChoice
+		want error
+		msg  string
+	}{
+		{"zero denominator", []RatChoice{{"a", 1, 2}, {"b", 1, 0}}, ErrInvalidWeight, "error: invalid weight 1/0 for choice 1"},
+		{"coprime denominators", coprime, ErrWeightOverflow, ""},
+		{"scaled weight", []RatChoice{{"a", ^uint(0), 1}, {"b", 1, 2}}, ErrWeightOverflow, "error: total weight overflows: weight 18446744073709551615/1 scaled by 2"},
+		{"empty", nil, ErrNoChoices, ""},
+		{"all zero", []RatChoice{{"a", 0, 3}}, ErrAllZeroWeights, ""},
+	}
+	for _, tc := range cases {
+		_, err := NewChooserFromRatios(tc.rc...)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: NewChooserFromRatios() error = %v, want %v", tc.name, err, tc.want)
+		}
+		if tc.msg != "" && is64Bit && err.Error() != tc.msg {
+			t.Errorf("%s: NewChooserFromRatios() error = %q, want %q", tc.name, err, tc.msg)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package weightedrand
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// A RendezvousChoiceT is a ChoiceT[T] with a stable identity, ID, by which
+// RendezvousChooserT hashes it.
+type RendezvousChoiceT[T any] struct {
+	ID     string
+	Item   T
+	Weight uint
+}
+
+// A RendezvousChoice is a RendezvousChoiceT over untyped items.
+type RendezvousChoice = RendezvousChoiceT[interface{}]
+
+// A RendezvousChooserT assigns keys to items by weighted rendezvous, or
+// highest random weight, hashing. Like PickByKey, it draws no random numbers,
+// so a key always gets the same item, and over many keys items get shares in
+// proportion to their weights. Unlike PickByKey, adding or removing a choice
+// moves only the keys assigned to it: each key is scored against every
+// choice independently and goes to the highest score, so the scores of the
+// other choices, and the order among them, are unaffected. That suits uses
+// such as cache affinity where reshuffling every key is costly.
+//
+// Assignment takes O(n) time for n choices. A RendezvousChooserT is immutable
+// and safe for concurrent use.
+type RendezvousChooserT[T any] struct {
+	choices []RendezvousChoiceT[T]
+	ids     []uint64 // hash of each choice's ID
+}
+
+// A RendezvousChooser is a RendezvousChooserT over untyped items.
+type RendezvousChooser = RendezvousChooserT[interface{}]
+
+// NewRendezvousChooserT initializes a new RendezvousChooserT assigning keys
+// among cs. An error is returned if two choices share an ID, ErrNoChoices if
+// cs is empty, and ErrAllZeroWeights if no choice would ever be assigned.
+// Choices with a weight of zero are kept but never assigned.
+func NewRendezvousChooserT[T any](cs ...RendezvousChoiceT[T]) (RendezvousChooserT[T], error) {
+	if len(cs) == 0 {
+		return RendezvousChooserT[T]{}, ErrNoChoices
+	}
+	rc := RendezvousChooserT[T]{
+		choices: append([]RendezvousChoiceT[T](nil), cs...),
+		ids:     make([]uint64, len(cs)),
+	}
+	seen := make(map[string]bool, len(cs))
+	nonzero := false
+	for i, c := range cs {
+		if seen[c.ID] {
+			return RendezvousChooserT[T]{}, fmt.Errorf("error: duplicate ID %q for choice %d", c.ID, i)
+		}
+		seen[c.ID] = true
+		h := fnv.New64a()
+		h.Write([]byte(c.ID))
+		rc.ids[i] = h.Sum64()
+		nonzero = nonzero || c.Weight > 0
+	}
+	if !nonzero {
+		return RendezvousChooserT[T]{}, ErrAllZeroWeights
+	}
+	return rc, nil
+}
+
+// NewRendezvousChooser initializes a new RendezvousChooser assigning keys
+// among cs. See NewRendezvousChooserT.
+func NewRendezvousChooser(cs ...RendezvousChoice) (RendezvousChooser, error) {
+	return NewRendezvousChooserT(cs...)
+}
+
+// AssignKey returns the Item of the choice that key is assigned to.
+//
+// The assignment is part of the package's compatibility promise, so that keys
+// keep their items across releases and process restarts. The key and each
+// ID are hashed with 64-bit FNV-1a, and for each choice the two hashes,
+// combined by exclusive or, are mixed by the SplitMix64 finalizer into a
+// uniform u in (0,1). The choice scores -weight/ln(u), and the highest score
+// wins, the first in construction order on a tie.
+func (rc RendezvousChooserT[T]) AssignKey(key []byte) (T, error) {
+	var zero T
+	if len(rc.choices) == 0 {
+		return zero, ErrNoChoices
+	}
+	h := fnv.New64a()
+	h.Write(key)
+	k := h.Sum64()
+
+	best, bestScore := -1, 0.0
+	for i, c := range rc.choices {
+		if c.Weight == 0 {
+			continue
+		}
+		u := (float64(mix64(k^rc.ids[i])>>11) + 0.5) / (1 << 53)
+		if score := -float64(c.Weight) / math.Log(u); best < 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return rc.choices[best].Item, nil
+}
+
+// AssignString is like AssignKey, with the key given as a string.
+func (rc RendezvousChooserT[T]) AssignString(key string) (T, error) {
+	return rc.AssignKey([]byte(key))
+}
+
+// Len returns the number of choices held by the RendezvousChooser.
+func (rc RendezvousChooserT[T]) Len() int {
+	return len(rc.choices)
+}
+
+// mix64 is the finalizer of SplitMix64, which spreads every bit of x across
+// all of the bits of the result.
+func mix64(x uint64) uint64 {
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
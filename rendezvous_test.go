@@ -0,0 +1,130 @@
+package weightedrand
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+// servers returns n equally weighted rendezvous choices with IDs s0, s1 and
+// so on, each of whose items is its index.
+func servers(n int) []RendezvousChoiceT[int] {
+	cs := make([]RendezvousChoiceT[int], n)
+	for i := range cs {
+		cs[i] = RendezvousChoiceT[int]{ID: "s" + strconv.Itoa(i), Item: i, Weight: 1}
+	}
+	return cs
+}
+
+func TestRendezvousRemoval(t *testing.T) {
+	all, err := NewRendezvousChooserT(servers(10)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Remove s3 from the middle.
+	fewer, err := NewRendezvousChooserT(append(servers(10)[:3], servers(10)[4:]...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const keys = 100000
+	moved := 0
+	for i := 0; i < keys; i++ {
+		key := "user-" + strconv.Itoa(i)
+		before, _ := all.AssignString(key)
+		after, _ := fewer.AssignString(key)
+		if before == after {
+			continue
+		}
+		moved++
+		if before != 3 {
+			t.Fatalf("key %q moved from %d to %d, though only 3 was removed", key, before, after)
+		}
+	}
+	if got := float64(moved) / keys; math.Abs(got-0.1) > 0.01 {
+		t.Errorf("removing one of ten moved %.3f of the keys, want about 0.1", got)
+	}
+}
+
+func TestRendezvousShares(t *testing.T) {
+	cs := []RendezvousChoice{
+		{ID: "a", Item: "a", Weight: 1},
+		{ID: "b", Item: "b", Weight: 2},
+		{ID: "zero", Item: "zero", Weight: 0},
+		{ID: "c", Item: "c", Weight: 3},
+		{ID: "d", Item: "d", Weight: 4},
+	}
+	chooser, err := NewRendezvousChooser(cs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", chooser.Len())
+	}
+	const keys = 100000
+	counts := make(map[interface{}]int)
+	for i := 0; i < keys; i++ {
+		item, err := chooser.AssignKey([]byte(strconv.Itoa(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[item]++
+	}
+	for _, c := range cs {
+		want := float64(c.Weight) / 10
+		if got := float64(counts[c.Item]) / keys; math.Abs(got-want) > 0.01 {
+			t.Errorf("assigned %v %.3f of the keys, want %.3f", c.Item, got, want)
+		}
+	}
+
+	// Assignments never change, whatever the order of construction.
+	reversed := make([]RendezvousChoice, len(cs))
+	for i, c := range cs {
+		reversed[len(cs)-1-i] = c
+	}
+	other, _ := NewRendezvousChooser(reversed...)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		x, _ := chooser.AssignString(key)
+		y, _ := other.AssignString(key)
+		if x != y {
+			t.Fatalf("key %q assigned %v, then %v in reverse order", key, x, y)
+		}
+	}
+}
+
+// TestRendezvousGolden pins assignments, which are part of the compatibility
+// promise.
+func TestRendezvousGolden(t *testing.T) {
+	chooser, _ := NewRendezvousChooserT(servers(5)...)
+	want := map[string]int{
+		"":        1,
+		"alice":   2,
+		"bob":     0,
+		"carol":   4,
+		"dave":    3,
+		"user-42": 0,
+	}
+	for key, item := range want {
+		if got, _ := chooser.AssignString(key); got != item {
+			t.Errorf("AssignString(%q) = %d, want %d", key, got, item)
+		}
+	}
+}
+
+func TestRendezvousErrors(t *testing.T) {
+	if _, err := NewRendezvousChooser(); err != ErrNoChoices {
+		t.Errorf("error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewRendezvousChooser(RendezvousChoice{ID: "a", Item: 1}); err != ErrAllZeroWeights {
+		t.Errorf("error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	_, err := NewRendezvousChooser(RendezvousChoice{ID: "a", Weight: 1}, RendezvousChoice{ID: "b", Weight: 1}, RendezvousChoice{ID: "a", Weight: 2})
+	if want := `error: duplicate ID "a" for choice 2`; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+	var zero RendezvousChooser
+	if _, err := zero.AssignString("k"); err != ErrNoChoices {
+		t.Errorf("AssignString() error = %v, want %v", err, ErrNoChoices)
+	}
+}
@@ -0,0 +1,84 @@
+package weightedrand
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+)
+
+// A ReservoirT selects k items without replacement from a stream of weighted
+// items too large to hold in memory, using the A-Res weighted reservoir
+// sampling algorithm of Efraimidis and Spirakis. After any number of calls to
+// Add, Result holds k of the items added, selected exactly as PickUniqueN
+// would select them from a Chooser of the same choices, in O(k) memory.
+//
+// A ReservoirT is not safe for concurrent use.
+type ReservoirT[T any] struct {
+	k     int
+	items []T     // items held, indexed by keyedIndex.index
+	keys  keyHeap // keys of items, smallest first
+	rng   source
+}
+
+// A Reservoir is a ReservoirT over untyped items.
+type Reservoir = ReservoirT[interface{}]
+
+// NewReservoirT initializes an empty ReservoirT that holds up to k items, and
+// draws its random numbers as configured by opts. Only the options choosing a
+// rand source apply. An error is returned for a negative k, any other option,
+// or an invalid combination of options.
+func NewReservoirT[T any](k int, opts ...Option) (*ReservoirT[T], error) {
+	if k < 0 {
+		return nil, errors.New("error: negative k")
+	}
+	o, err := buildOptions(opts, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &ReservoirT[T]{
+		k:     k,
+		items: make([]T, 0, k),
+		keys:  make(keyHeap, 0, k),
+		rng:   o.rng,
+	}, nil
+}
+
+// NewReservoir initializes an empty Reservoir that holds up to k items. See
+// NewReservoirT.
+func NewReservoir(k int, opts ...Option) (*Reservoir, error) {
+	return NewReservoirT[interface{}](k, opts...)
+}
+
+// Add offers item with the given weight to the ReservoirT, which keeps it if
+// its random key is among the k largest seen so far. Items with a weight of
+// zero are never kept.
+func (r *ReservoirT[T]) Add(item T, weight uint) {
+	if weight == 0 || r.k == 0 {
+		return
+	}
+	key := sampleKey(r.rng, weight)
+	if len(r.keys) < r.k {
+		r.items = append(r.items, item)
+		heap.Push(&r.keys, keyedIndex{key: key, index: len(r.items) - 1})
+	} else if key > r.keys[0].key {
+		r.items[r.keys[0].index] = item
+		r.keys[0].key = key
+		heap.Fix(&r.keys, 0)
+	}
+}
+
+// Result returns the items held by the ReservoirT, in the order they would
+// have been drawn had each been removed after being picked, like PickUniqueN.
+// Fewer than k items are returned if fewer than k of nonzero weight have been
+// added. The ReservoirT is not modified, so more items may be added after.
+func (r *ReservoirT[T]) Result() []T {
+	keys := append(keyHeap(nil), r.keys...)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].key > keys[j].key
+	})
+	items := make([]T, len(keys))
+	for i, k := range keys {
+		items[i] = r.items[k.index]
+	}
+	return items
+}
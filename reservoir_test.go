@@ -0,0 +1,117 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestReservoir(t *testing.T) {
+	// Inclusion probabilities for k=2 of weights 1, 1, 2 and 0: d is always
+	// excluded, c is included unless a and b are both drawn first.
+	weights := map[string]uint{"a": 1, "b": 1, "c": 2, "d": 0}
+	want := map[string]float64{"a": 7.0 / 12, "b": 7.0 / 12, "c": 5.0 / 6, "d": 0}
+	firsts := map[string]float64{"a": 0.25, "b": 0.25, "c": 0.5, "d": 0}
+
+	rng := rand.New(rand.NewSource(1))
+	const n = 20000
+	included := make(map[string]float64)
+	first := make(map[string]float64)
+	for i := 0; i < n; i++ {
+		r, err := NewReservoirT[string](2, WithRand(rng))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range []string{"a", "b", "c", "d"} {
+			r.Add(item, weights[item])
+		}
+		result := r.Result()
+		if len(result) != 2 || result[0] == result[1] {
+			t.Fatalf("Result() = %v, want two distinct items", result)
+		}
+		for _, item := range result {
+			included[item] += 1.0 / n
+		}
+		first[result[0]] += 1.0 / n
+	}
+	for item, p := range want {
+		if math.Abs(included[item]-p) > 0.02 {
+			t.Errorf("%s included %.3f of the time, want %.3f", item, included[item], p)
+		}
+		if math.Abs(first[item]-firsts[item]) > 0.02 {
+			t.Errorf("%s drawn first %.3f of the time, want %.3f", item, first[item], firsts[item])
+		}
+	}
+}
+
+func TestReservoirSmallStream(t *testing.T) {
+	r, err := NewReservoir(5, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Result(); len(got) != 0 {
+		t.Errorf("Result() = %v for empty stream, want none", got)
+	}
+	r.Add("a", 1)
+	r.Add("zero", 0)
+	r.Add("b", 1000)
+	got := r.Result()
+	if len(got) != 2 {
+		t.Fatalf("Result() = %v, want both nonzero items", got)
+	}
+	if !reflect.DeepEqual(got, []interface{}{"b", "a"}) && !reflect.DeepEqual(got, []interface{}{"a", "b"}) {
+		t.Errorf("Result() = %v, want a and b", got)
+	}
+
+	// Result does not disturb the reservoir.
+	r.Add("c", 1)
+	if got := r.Result(); len(got) != 3 {
+		t.Errorf("Result() = %v after another Add, want three items", got)
+	}
+
+	empty, err := NewReservoir(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty.Add("a", 1)
+	if got := empty.Result(); len(got) != 0 {
+		t.Errorf("Result() = %v for k=0, want none", got)
+	}
+	if _, err := NewReservoir(-1); err == nil {
+		t.Error("expected error for negative k")
+	}
+	if _, err := NewReservoir(1, WithSeed(1), WithParallelRand()); err == nil {
+		t.Error("expected error for conflicting options")
+	}
+}
+
+// TestReservoirMatchesPickUniqueN ensures that a reservoir fed the choices of
+// a Chooser in its sorted order selects exactly as PickUniqueN with the same
+// seed.
+func TestReservoirMatchesPickUniqueN(t *testing.T) {
+	choices := make([]Choice, 100)
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(i % 7)}
+	}
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(42)), choices...)
+	want, err := chooser.PickUniqueN(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := NewReservoir(10, WithSeed(42))
+	for _, c := range chooser.data {
+		r.Add(c.Item, c.Weight)
+	}
+	if got := r.Result(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Result() = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkReservoirAdd(b *testing.B) {
+	r, _ := NewReservoirT[int](100, WithSeed(1))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Add(i, uint(i%1000)+1)
+	}
+}
@@ -0,0 +1,15 @@
+package weightedrand
+
+// Reweight returns a new Chooser holding the same items as chs, in the same
+// order, with each weight replaced by fn(item, weight). The result keeps the
+// rand source of chs, and the options kept when chs is mutated, but shares no
+// storage with it, and chs is untouched. The new weights are validated as by
+// NewChooserTErr, so the error reports weights that are all zero or overflow.
+func (chs ChooserT[T]) Reweight(fn func(item T, w uint) uint) (ChooserT[T], error) {
+	cs := chs.original()
+	for i := range cs {
+		cs[i].Weight = fn(cs[i].Item, cs[i].Weight)
+	}
+	r := chs.derive(buildChooser(cs, false))
+	return r, r.Err()
+}
@@ -0,0 +1,61 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestReweight(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "sports-1", Weight: 1},
+		Choice{Item: "news-1", Weight: 2},
+		Choice{Item: "sports-2", Weight: 3},
+	)
+	want := map[interface{}]float64{"sports-1": 1.0 / 6, "news-1": 2.0 / 6, "sports-2": 3.0 / 6}
+
+	identity, err := chooser.Reweight(func(_ interface{}, w uint) uint { return w })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(identity.Choices(), chooser.Choices()) {
+		t.Errorf("identity Choices() = %v, want %v", identity.Choices(), chooser.Choices())
+	}
+	assertShares(t, pickShares(t, identity, 20000), want)
+
+	noSports, err := chooser.Reweight(func(item interface{}, w uint) uint {
+		if item.(string)[:6] == "sports" {
+			return 0
+		}
+		return w
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if noSports.Len() != 3 {
+		t.Errorf("Len() = %d, want zeroed items kept", noSports.Len())
+	}
+	assertShares(t, pickShares(t, noSports, 10000), map[interface{}]float64{"news-1": 1})
+
+	if _, err := chooser.Reweight(func(interface{}, uint) uint { return 0 }); err != ErrAllZeroWeights {
+		t.Errorf("Reweight(zero) error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if is64Bit {
+		if _, err := chooser.Reweight(func(_ interface{}, w uint) uint { return w * uint(maxInt/2) }); err != ErrWeightOverflow {
+			t.Errorf("Reweight(huge) error = %v, want %v", err, ErrWeightOverflow)
+		}
+	}
+
+	// The original is untouched.
+	if got := chooser.Weights(); !reflect.DeepEqual(got, []uint{1, 2, 3}) {
+		t.Errorf("original Weights() = %v, want [1 2 3]", got)
+	}
+	assertShares(t, pickShares(t, chooser, 20000), want)
+
+	configured := configuredChooser(t, chooser.Choices()...)
+	doubled, err := configured.Reweight(func(_ interface{}, w uint) uint { return 2 * w })
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Reweight()", doubled, configured)
+}
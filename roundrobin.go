@@ -0,0 +1,127 @@
+package weightedrand
+
+import (
+	"math"
+	"sync"
+)
+
+// A RoundRobinChooserT cycles deterministically through its choices using the
+// smooth weighted round-robin algorithm of nginx. In any run of TotalWeight
+// consecutive calls to Next, each choice is returned exactly as many times as
+// its weight, spread as evenly as possible rather than in bursts, which suits
+// load balancing over short windows better than random picks.
+//
+// A RoundRobinChooserT is not safe for concurrent use unless created with
+// NewSyncRoundRobinChooserT.
+type RoundRobinChooserT[T any] struct {
+	mu      *sync.Mutex // nil unless synchronized
+	data    []ChoiceT[T]
+	current []int64
+	total   int64
+	err     error
+}
+
+// A RoundRobinChooser is a RoundRobinChooserT over untyped items.
+type RoundRobinChooser = RoundRobinChooserT[interface{}]
+
+// NewRoundRobinChooserT initializes a new RoundRobinChooserT cycling through
+// the possible ChoiceT[T]. Ties are broken in favor of the earlier choice, so
+// the sequence depends on the order of cs. Problems with the choices are
+// reported by Err and Next, as for NewChooserT, except that the weights may
+// only sum to math.MaxInt64.
+func NewRoundRobinChooserT[T any](cs ...ChoiceT[T]) *RoundRobinChooserT[T] {
+	rr := &RoundRobinChooserT[T]{
+		data:    append([]ChoiceT[T](nil), cs...),
+		current: make([]int64, len(cs)),
+	}
+	var total uint64
+	for _, c := range cs {
+		if uint64(c.Weight) > math.MaxInt64-total {
+			rr.err = ErrWeightOverflow
+			return rr
+		}
+		total += uint64(c.Weight)
+	}
+	rr.total = int64(total)
+	switch {
+	case len(cs) == 0:
+		rr.err = ErrNoChoices
+	case total == 0:
+		rr.err = ErrAllZeroWeights
+	}
+	return rr
+}
+
+// NewRoundRobinChooser initializes a new RoundRobinChooser cycling through the
+// possible Choices. See NewRoundRobinChooserT.
+func NewRoundRobinChooser(cs ...Choice) *RoundRobinChooser {
+	return NewRoundRobinChooserT(cs...)
+}
+
+// NewSyncRoundRobinChooserT is like NewRoundRobinChooserT, but the result
+// guards its state with a mutex so that Next and Reset may be called from
+// many goroutines at once. The calls are then serialized, so together they
+// still follow the sequence exactly.
+func NewSyncRoundRobinChooserT[T any](cs ...ChoiceT[T]) *RoundRobinChooserT[T] {
+	rr := NewRoundRobinChooserT(cs...)
+	rr.mu = new(sync.Mutex)
+	return rr
+}
+
+// NewSyncRoundRobinChooser initializes a new RoundRobinChooser that is safe
+// for concurrent use. See NewSyncRoundRobinChooserT.
+func NewSyncRoundRobinChooser(cs ...Choice) *RoundRobinChooser {
+	return NewSyncRoundRobinChooserT(cs...)
+}
+
+// Next returns the next Choice.Item in the sequence. Each choice's current
+// weight is increased by its weight, the choice with the largest current
+// weight is selected, and its current weight is reduced by the total.
+func (rr *RoundRobinChooserT[T]) Next() (T, error) {
+	if rr.err != nil {
+		var zero T
+		return zero, rr.err
+	}
+	if rr.mu != nil {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+	}
+	best := 0
+	for i, c := range rr.data {
+		rr.current[i] += int64(c.Weight)
+		if rr.current[i] > rr.current[best] {
+			best = i
+		}
+	}
+	rr.current[best] -= rr.total
+	return rr.data[best].Item, nil
+}
+
+// Reset returns the RoundRobinChooserT to its initial state, so that Next
+// starts the sequence over.
+func (rr *RoundRobinChooserT[T]) Reset() {
+	if rr.mu != nil {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+	}
+	for i := range rr.current {
+		rr.current[i] = 0
+	}
+}
+
+// Err returns the reason the RoundRobinChooserT cannot be used, or nil if it
+// is usable. Next returns this same error.
+func (rr *RoundRobinChooserT[T]) Err() error {
+	return rr.err
+}
+
+// Len returns the number of choices held by the RoundRobinChooserT.
+func (rr *RoundRobinChooserT[T]) Len() int {
+	return len(rr.data)
+}
+
+// TotalWeight returns the sum of the weights of the choices, which is the
+// length of the sequence before it repeats.
+func (rr *RoundRobinChooserT[T]) TotalWeight() uint64 {
+	return uint64(rr.total)
+}
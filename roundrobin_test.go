@@ -0,0 +1,134 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// nextN returns the next n items from rr.
+func nextN(t *testing.T, rr *RoundRobinChooserT[string], n int) []string {
+	t.Helper()
+	items := make([]string, n)
+	for i := range items {
+		item, err := rr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		items[i] = item
+	}
+	return items
+}
+
+func TestRoundRobinSequence(t *testing.T) {
+	cases := []struct {
+		weights []uint
+		want    string
+	}{
+		{[]uint{5, 1, 1}, "aabacaa"},
+		{[]uint{1, 1, 1}, "abc"},
+		{[]uint{3, 2}, "ababa"},
+		{[]uint{4, 2, 1}, "abacaba"},
+		{[]uint{2, 0, 1}, "aca"},
+	}
+	for _, tc := range cases {
+		cs := make([]ChoiceT[string], len(tc.weights))
+		for i, w := range tc.weights {
+			cs[i] = ChoiceT[string]{Item: string(rune('a' + i)), Weight: w}
+		}
+		rr := NewRoundRobinChooserT(cs...)
+		for cycle := 0; cycle < 3; cycle++ {
+			seq := nextN(t, rr, len(tc.want))
+			if joined := strings.Join(seq, ""); joined != tc.want {
+				t.Errorf("weights %v cycle %d: sequence %s, want %s", tc.weights, cycle, joined, tc.want)
+			}
+		}
+
+		rr.Reset()
+		if seq := strings.Join(nextN(t, rr, len(tc.want)), ""); seq != tc.want {
+			t.Errorf("weights %v after Reset: sequence %s, want %s", tc.weights, seq, tc.want)
+		}
+	}
+}
+
+// TestRoundRobinWindows checks that every window of TotalWeight consecutive
+// items holds each item exactly its weight times.
+func TestRoundRobinWindows(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	cs := make([]ChoiceT[string], 6)
+	want := make(map[string]int)
+	for i := range cs {
+		cs[i] = ChoiceT[string]{Item: string(rune('a' + i)), Weight: uint(rng.Intn(10))}
+		want[cs[i].Item] = int(cs[i].Weight)
+	}
+	rr := NewRoundRobinChooserT(cs...)
+	total := int(rr.TotalWeight())
+	seq := nextN(t, rr, 3*total)
+	for start := 0; start+total <= len(seq); start++ {
+		got := make(map[string]int)
+		for _, c := range cs {
+			got[c.Item] = 0
+		}
+		for _, item := range seq[start : start+total] {
+			got[item]++
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("window at %d holds %v, want %v", start, got, want)
+		}
+	}
+}
+
+func TestRoundRobinErrors(t *testing.T) {
+	if _, err := NewRoundRobinChooser().Next(); err != ErrNoChoices {
+		t.Errorf("Next() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewRoundRobinChooser(Choice{Item: "a"}).Next(); err != ErrAllZeroWeights {
+		t.Errorf("Next() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if !is64Bit {
+		return
+	}
+	rr := NewRoundRobinChooser(Choice{Weight: uint(maxInt)}, Choice{Weight: 1})
+	if err := rr.Err(); err != ErrWeightOverflow {
+		t.Errorf("Err() = %v, want %v", err, ErrWeightOverflow)
+	}
+}
+
+func TestSyncRoundRobin(t *testing.T) {
+	rr := NewSyncRoundRobinChooserT(
+		ChoiceT[string]{Item: "a", Weight: 5},
+		ChoiceT[string]{Item: "b", Weight: 1},
+		ChoiceT[string]{Item: "c", Weight: 1},
+	)
+	const goroutines, perGoroutine = 8, 7 * 100
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make(map[string]int)
+			for i := 0; i < perGoroutine; i++ {
+				item, err := rr.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				local[item]++
+			}
+			mu.Lock()
+			for item, c := range local {
+				counts[item] += c
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	cycles := goroutines * perGoroutine / 7
+	if want := map[string]int{"a": 5 * cycles, "b": cycles, "c": cycles}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
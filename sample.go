@@ -0,0 +1,207 @@
+package weightedrand
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"sort"
+)
+
+// PickUniqueN returns k distinct weighted random Choice.Items from the
+// Chooser, selected without replacement. Choices with a weight of zero are
+// never selected. The items are returned in the order they would have been
+// drawn had each been removed from the Chooser after being picked, so the
+// first item is distributed exactly as with Pick.
+//
+// The Chooser itself is not modified. Selection uses the Efraimidis-Spirakis
+// algorithm, assigning each choice a random key of u^(1/weight) and keeping
+// the k largest in a heap, which takes O(n log k) time.
+func (chs ChooserT[T]) PickUniqueN(k int) ([]T, error) {
+	if k < 0 {
+		return nil, errors.New("error: negative k")
+	}
+	if err := chs.Err(); err != nil {
+		return nil, err
+	}
+	indices, ok := chs.uniqueIndices(k)
+	if !ok {
+		return nil, errors.New("error: k exceeds number of choices with nonzero weight")
+	}
+	return chs.itemsAt(indices), nil
+}
+
+// uniqueIndices returns the indices into chs.data of k distinct choices of
+// nonzero weight, in the order PickUniqueN returns them. It reports false if
+// there are fewer than k such choices.
+func (chs ChooserT[T]) uniqueIndices(k int) ([]int, bool) {
+	h := make(keyHeap, 0, k)
+	nonzero := 0
+	for i, c := range chs.data {
+		if c.Weight == 0 {
+			continue
+		}
+		nonzero++
+		if k == 0 {
+			continue
+		}
+		key := sampleKey(chs.rng, c.Weight)
+		if len(h) < k {
+			heap.Push(&h, keyedIndex{key: key, index: i})
+		} else if key > h[0].key {
+			h[0] = keyedIndex{key: key, index: i}
+			heap.Fix(&h, 0)
+		}
+	}
+	if k > nonzero {
+		return nil, false
+	}
+
+	indices := make([]int, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		indices[i] = heap.Pop(&h).(keyedIndex).index
+	}
+	return indices, true
+}
+
+// Shuffle returns every Choice.Item from the Chooser in a weighted random
+// order, such that heavier items tend to appear earlier. Each position is
+// distributed as if the items before it had been picked and removed, so the
+// first item is distributed exactly as with Pick. Choices with a weight of zero
+// are placed last, in uniformly random order.
+//
+// The Chooser itself is not modified.
+func (chs ChooserT[T]) Shuffle() ([]T, error) {
+	if err := chs.Err(); err != nil {
+		return nil, err
+	}
+	return chs.itemsAt(chs.shuffleIndices()), nil
+}
+
+// shuffleIndices returns every index into chs.data, in the order Shuffle
+// returns their items.
+func (chs ChooserT[T]) shuffleIndices() []int {
+	keyed := make([]keyedIndex, 0, len(chs.data))
+	var zeros []int
+	for i, c := range chs.data {
+		if c.Weight == 0 {
+			zeros = append(zeros, i)
+			continue
+		}
+		keyed = append(keyed, keyedIndex{key: sampleKey(chs.rng, c.Weight), index: i})
+	}
+	sort.Slice(keyed, func(i, j int) bool {
+		return keyed[i].key > keyed[j].key
+	})
+	for i := len(zeros) - 1; i > 0; i-- {
+		j := intn(chs.rng, i+1)
+		zeros[i], zeros[j] = zeros[j], zeros[i]
+	}
+
+	indices := make([]int, 0, len(chs.data))
+	for _, k := range keyed {
+		indices = append(indices, k.index)
+	}
+	return append(indices, zeros...)
+}
+
+// SampleWithoutReplacement draws k distinct weighted random Choice.Items from
+// the Chooser, as PickUniqueN does, and returns them along with rest, a new
+// Chooser over the choices not drawn, with their original weights and in
+// their original order, so that drawing may continue from everyone else.
+//
+// Where k exceeds the number of choices of nonzero weight, every one of them
+// is drawn, followed by choices of zero weight in uniformly random order, as
+// with Shuffle. A k of Len or more draws every item, leaving rest empty, so
+// that it reports ErrNoChoices; a rest holding only zero weights reports
+// ErrAllZeroWeights.
+//
+// rest is built in O(n) time, since the choices left over are already in the
+// order the Chooser picks in, and keeps the rand source, pick statistics mode,
+// lookup table limit, compact totals mode and pick hook of the Chooser, which
+// is itself not modified.
+func (chs ChooserT[T]) SampleWithoutReplacement(k int) (winners []T, rest ChooserT[T], err error) {
+	if k < 0 {
+		return nil, ChooserT[T]{}, errors.New("error: negative k")
+	}
+	if err := chs.Err(); err != nil {
+		return nil, ChooserT[T]{}, err
+	}
+	indices, ok := chs.uniqueIndices(k)
+	if !ok {
+		indices = chs.shuffleIndices()
+		if k < len(indices) {
+			indices = indices[:k]
+		}
+	}
+
+	drawn := make([]bool, len(chs.data))
+	for _, i := range indices {
+		drawn[i] = true
+	}
+	left := make([]ChoiceT[T], 0, len(chs.data)-len(indices))
+	for i, c := range chs.data {
+		if !drawn[i] {
+			left = append(left, c)
+		}
+	}
+	next := buildChooser(left, true)
+	if chs.indices != nil && len(left) > 0 {
+		// Renumber the original positions of the choices left over, in
+		// their original order, skipping those drawn.
+		kept := make([]int, len(chs.data))
+		for i := range chs.data {
+			if !drawn[i] {
+				kept[chs.indices[i]] = 1
+			}
+		}
+		for i, n := 0, 0; i < len(kept); i++ {
+			n, kept[i] = n+kept[i], n
+		}
+		next.indices = make([]int, 0, len(left))
+		for i := range chs.data {
+			if !drawn[i] {
+				next.indices = append(next.indices, kept[chs.indices[i]])
+			}
+		}
+	}
+	rest = chs
+	rest.replace(next)
+	return chs.itemsAt(indices), rest, nil
+}
+
+// itemsAt returns the items of the choices at indices into chs.data.
+func (chs ChooserT[T]) itemsAt(indices []int) []T {
+	items := make([]T, len(indices))
+	for i, j := range indices {
+		items[i] = chs.data[j].Item
+	}
+	return items
+}
+
+// sampleKey returns a random Efraimidis-Spirakis key for a choice of weight w,
+// drawn from rs. Keys are log(u)/w, which orders identically to u^(1/w)
+// without the precision loss of raising to tiny fractional powers.
+func sampleKey(rs source, w uint) float64 {
+	return math.Log(1-float64n(rs)) / float64(w)
+}
+
+// keyedIndex associates a sampling key with an index into Chooser.data.
+type keyedIndex struct {
+	key   float64
+	index int
+}
+
+// keyHeap is a min-heap of keyedIndex ordered by key, implementing
+// heap.Interface.
+type keyHeap []keyedIndex
+
+func (h keyHeap) Len() int            { return len(h) }
+func (h keyHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h keyHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap) Push(x interface{}) { *h = append(*h, x.(keyedIndex)) }
+func (h *keyHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,255 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPickUniqueN(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 2},
+		Choice{Item: "c", Weight: 4},
+		Choice{Item: "d", Weight: 8},
+	)
+
+	counts := make(map[interface{}]int)
+	for i := 0; i < 10000; i++ {
+		items, err := chooser.PickUniqueN(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 3 {
+			t.Fatalf("got %d items, want 3", len(items))
+		}
+		seen := make(map[interface{}]bool)
+		for _, item := range items {
+			if seen[item] {
+				t.Fatalf("duplicate item %v in %v", item, items)
+			}
+			seen[item] = true
+			counts[item]++
+		}
+	}
+	if counts["zero"] != 0 {
+		t.Errorf("zero weight item selected %d times", counts["zero"])
+	}
+	order := []string{"a", "b", "c", "d"}
+	for i := 1; i < len(order); i++ {
+		if !(counts[order[i-1]] < counts[order[i]]) {
+			t.Errorf("%s selected no more often than %s: %v", order[i], order[i-1], counts)
+		}
+	}
+
+	// Drawing every nonzero choice must return each of them exactly once.
+	items, err := chooser.PickUniqueN(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 {
+		t.Errorf("got %v, want all four nonzero items", items)
+	}
+
+	if items, err := chooser.PickUniqueN(0); err != nil || len(items) != 0 {
+		t.Errorf("PickUniqueN(0) = %v, %v; want empty slice", items, err)
+	}
+	if _, err := chooser.PickUniqueN(5); err == nil {
+		t.Error("expected error when k exceeds nonzero choices")
+	}
+	if _, err := chooser.PickUniqueN(-1); err == nil {
+		t.Error("expected error for negative k")
+	}
+	if _, err := NewChooser().PickUniqueN(1); err == nil {
+		t.Error("expected error picking from empty chooser")
+	}
+}
+
+// TestPickUniqueNFirst checks the first item of each draw is distributed in
+// proportion to the weights, as with a single Pick.
+func TestPickUniqueNFirst(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(2)),
+		Choice{Item: 0, Weight: 1},
+		Choice{Item: 1, Weight: 3},
+	)
+	const n = 20000
+	var first [2]int
+	for i := 0; i < n; i++ {
+		items, err := chooser.PickUniqueN(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first[items[0].(int)]++
+	}
+	if got := float64(first[1]) / n; got < 0.73 || got > 0.77 {
+		t.Errorf("heavier item drawn first %.3f of the time, want ~0.75", got)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	choices := []Choice{
+		{Item: "z1", Weight: 0},
+		{Item: "a", Weight: 1},
+		{Item: "b", Weight: 2},
+		{Item: "b2", Weight: 2},
+		{Item: "c", Weight: 5},
+		{Item: "z2", Weight: 0},
+	}
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(3)), choices...)
+	totals := append([]uint64(nil), chooser.totals...)
+
+	const n = 20000
+	first := make(map[interface{}]int)
+	zeroLast := make(map[interface{}]int)
+	for i := 0; i < n; i++ {
+		items, err := chooser.Shuffle()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != len(choices) {
+			t.Fatalf("got %d items, want %d", len(items), len(choices))
+		}
+		seen := make(map[interface{}]bool)
+		for _, item := range items {
+			if seen[item] {
+				t.Fatalf("duplicate item %v in %v", item, items)
+			}
+			seen[item] = true
+		}
+		for _, item := range items[len(items)-2:] {
+			if item != "z1" && item != "z2" {
+				t.Fatalf("nonzero item %v among the last two: %v", item, items)
+			}
+		}
+		first[items[0]]++
+		zeroLast[items[len(items)-1]]++
+	}
+
+	// The first position should match the normalized weights.
+	for _, c := range choices {
+		want := float64(c.Weight) / 10
+		if got := float64(first[c.Item]) / n; got < want-0.015 || got > want+0.015 {
+			t.Errorf("%v first %.3f of the time, want %.3f", c.Item, got, want)
+		}
+	}
+	// Zero weight items are ordered uniformly among themselves.
+	if got := float64(zeroLast["z1"]) / n; got < 0.48 || got > 0.52 {
+		t.Errorf("z1 last %.3f of the time, want 0.5", got)
+	}
+
+	for i := range totals {
+		if totals[i] != chooser.totals[i] {
+			t.Fatalf("Shuffle modified chooser totals: %v, want %v", chooser.totals, totals)
+		}
+	}
+	if _, err := NewChooser().Shuffle(); err == nil {
+		t.Error("expected error shuffling empty chooser")
+	}
+}
+
+func TestSampleWithoutReplacement(t *testing.T) {
+	var choices []Choice
+	for i := 0; i < 50; i++ {
+		choices = append(choices, Choice{Item: i, Weight: uint(i%5 + 1)})
+	}
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(4)), choices...)
+
+	// Round after round, the winners are new items and rest holds exactly
+	// the others, in their original order with their original weights.
+	seen := make(map[interface{}]bool)
+	rest := chooser
+	for round := 0; round < 6; round++ {
+		winners, next, err := rest.SampleWithoutReplacement(7)
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if len(winners) != 7 {
+			t.Fatalf("round %d: %d winners, want 7", round, len(winners))
+		}
+		for _, w := range winners {
+			if seen[w] {
+				t.Fatalf("round %d: %v drawn again", round, w)
+			}
+			seen[w] = true
+		}
+		if want := len(choices) - len(seen); next.Len() != want {
+			t.Fatalf("round %d: rest has Len() %d, want %d", round, next.Len(), want)
+		}
+		var want []Choice
+		for _, c := range choices {
+			if !seen[c.Item] {
+				want = append(want, c)
+			}
+		}
+		if !reflect.DeepEqual(next.Choices(), want) {
+			t.Fatalf("round %d: rest holds %v, want %v", round, next.Choices(), want)
+		}
+		if err := next.Validate(); err != nil {
+			t.Fatalf("round %d: rest is not healthy: %v", round, err)
+		}
+		rest = next
+	}
+	if chooser.Len() != len(choices) {
+		t.Errorf("Len() = %d after sampling, want the Chooser unchanged", chooser.Len())
+	}
+
+	// Drawing everyone left empties rest.
+	winners, empty, err := rest.SampleWithoutReplacement(rest.Len() + 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(winners) != len(choices)-len(seen) {
+		t.Errorf("%d winners, want the %d left", len(winners), len(choices)-len(seen))
+	}
+	if empty.Len() != 0 || empty.Err() != ErrNoChoices {
+		t.Errorf("rest has Len() %d, Err() %v; want empty", empty.Len(), empty.Err())
+	}
+
+	if winners, rest, err := chooser.SampleWithoutReplacement(0); err != nil || len(winners) != 0 || rest.Len() != len(choices) {
+		t.Errorf("SampleWithoutReplacement(0) = %v, %d left, %v", winners, rest.Len(), err)
+	}
+	if _, _, err := chooser.SampleWithoutReplacement(-1); err == nil {
+		t.Error("expected error for negative k")
+	}
+	if _, _, err := NewChooser().SampleWithoutReplacement(1); err != ErrNoChoices {
+		t.Errorf("SampleWithoutReplacement() error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func TestSampleWithoutReplacementZeros(t *testing.T) {
+	chooser := NewChooserTWithRand(rand.New(rand.NewSource(5)),
+		ChoiceT[string]{Item: "z1", Weight: 0},
+		ChoiceT[string]{Item: "a", Weight: 3},
+		ChoiceT[string]{Item: "z2", Weight: 0},
+		ChoiceT[string]{Item: "b", Weight: 1},
+	)
+	// Nonzero choices are always drawn before any of zero weight.
+	winners, rest, err := chooser.SampleWithoutReplacement(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(winners) != 3 || winners[0] == "z1" || winners[0] == "z2" || winners[1] == "z1" || winners[1] == "z2" {
+		t.Errorf("winners = %q, want a and b first", winners)
+	}
+	if rest.Len() != 1 || rest.Err() != ErrAllZeroWeights {
+		t.Errorf("rest has Len() %d, Err() %v; want one zero weight", rest.Len(), rest.Err())
+	}
+
+	// The heavier choice wins the first draw three times in four, and rest
+	// then keeps drawing from whoever is left.
+	const n = 8000
+	first := 0
+	for i := 0; i < n; i++ {
+		winners, rest, _ := chooser.SampleWithoutReplacement(1)
+		if winners[0] == "a" {
+			first++
+			if item := rest.MustPick(); item != "b" {
+				t.Fatalf("rest picked %q, want b", item)
+			}
+		}
+	}
+	if got := float64(first) / n; got < 0.72 || got > 0.78 {
+		t.Errorf("heavier item drawn first %.3f of the time, want ~0.75", got)
+	}
+}
@@ -0,0 +1,112 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math"
+)
+
+// A ScriptedSource is a test double for a chooser's source of random numbers,
+// supplying a fixed sequence of values given by the test in place of random
+// ones. Together with ValueForItem, it lets a test of code that embeds a
+// Chooser force a particular sequence of picks:
+//
+//	b, _ := weightedrand.ValueForItem(chs, "b")
+//	a, _ := weightedrand.ValueForItem(chs, "a")
+//	src := weightedrand.NewScriptedSource(b, a)
+//	chs.PickWith(src) // "b"
+//	chs.PickWith(src) // "a"
+//
+// The same values serve a Chooser built from the same choices and options with
+// WithSource(src), as the code under test may do.
+//
+// Each draw of a value below n returns the next value of the script as it
+// stands, panicking if it is not below n, so that a Pick uses exactly one
+// value, which is the raw value r that selects the choice whose cumulative
+// range [total-weight, total) holds it. Once the script is used up, every draw
+// panics with ErrScriptExhausted.
+//
+// A ScriptedSource is not safe for concurrent use.
+type ScriptedSource struct {
+	values []int
+}
+
+// NewScriptedSource returns a ScriptedSource supplying values in order, each
+// of which must be nonnegative.
+func NewScriptedSource(values ...int) *ScriptedSource {
+	for _, v := range values {
+		if v < 0 {
+			panic(fmt.Sprintf("error: negative scripted value %d", v))
+		}
+	}
+	return &ScriptedSource{values: append([]int(nil), values...)}
+}
+
+// Remaining returns the number of values left in the script.
+func (s *ScriptedSource) Remaining() int {
+	return len(s.values)
+}
+
+// Intn returns the next value, which must be below n.
+func (s *ScriptedSource) Intn(n int) int {
+	return int(s.below(uint64(n)))
+}
+
+// Int63n returns the next value, which must be below n.
+func (s *ScriptedSource) Int63n(n int64) int64 {
+	return int64(s.below(uint64(n)))
+}
+
+// Uint64 returns the next value.
+func (s *ScriptedSource) Uint64() uint64 {
+	return s.below(math.MaxUint64)
+}
+
+// Float64 returns the next value divided by 2⁵³, which must be below 1.
+func (s *ScriptedSource) Float64() float64 {
+	return float64(s.below(1<<53)) / (1 << 53)
+}
+
+// below removes and returns the next value, panicking if there is none or it
+// is not below n.
+func (s *ScriptedSource) below(n uint64) uint64 {
+	if len(s.values) == 0 {
+		panic(ErrScriptExhausted)
+	}
+	v := uint64(s.values[0])
+	if v >= n {
+		panic(fmt.Sprintf("error: scripted value %d out of range [0,%d)", v, n))
+	}
+	s.values = s.values[1:]
+	return v
+}
+
+// ValueForItemT returns the smallest raw value which makes a Pick from chs,
+// drawing from a ScriptedSource, return item: the cumulative weight of the
+// choices before the first of item in the order chs picks in. An error is
+// returned if chs cannot be picked from, if item is not among its choices of
+// nonzero weight, or if the value does not fit in an int.
+//
+// Items are matched by ==, so this panics if T is an interface type holding an
+// item that is not comparable.
+func ValueForItemT[T any](chs ChooserT[T], item T) (int, error) {
+	if err := chs.Err(); err != nil {
+		return 0, err
+	}
+	for i, c := range chs.data {
+		if c.Weight == 0 || !equalItems(c.Item, item) {
+			continue
+		}
+		v := chs.total(i) - uint64(c.Weight)
+		if v > uint64(maxInt) {
+			return 0, fmt.Errorf("error: value %d for item %v overflows int", v, item)
+		}
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("error: no choice of nonzero weight holds item %v", item)
+}
+
+// ValueForItem returns the smallest raw value which makes a Pick from chs,
+// drawing from a ScriptedSource, return item. See ValueForItemT.
+func ValueForItem(chs Chooser, item interface{}) (int, error) {
+	return ValueForItemT(chs, item)
+}
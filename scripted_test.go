@@ -0,0 +1,128 @@
+package weightedrand
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleScriptedSource() {
+	choices := []Choice{
+		{Item: "a", Weight: 1},
+		{Item: "b", Weight: 2},
+		{Item: "c", Weight: 3},
+	}
+	chooser := NewChooser(choices...)
+
+	// Script the picks B, then A, then C.
+	var script []int
+	for _, item := range []string{"b", "a", "c"} {
+		v, err := ValueForItem(chooser, item)
+		if err != nil {
+			panic(err)
+		}
+		script = append(script, v)
+	}
+	// Code under test would be handed a chooser configured like this one.
+	scripted, _ := NewChooserOpts(choices, WithSource(NewScriptedSource(script...)))
+	for i := 0; i < 3; i++ {
+		fmt.Println(scripted.MustPick())
+	}
+	// Output:
+	// b
+	// a
+	// c
+}
+
+func TestScriptedSource(t *testing.T) {
+	choices := []ChoiceT[string]{
+		{Item: "a", Weight: 1},
+		{Item: "zero", Weight: 0},
+		{Item: "b", Weight: 5},
+		{Item: "c", Weight: 2},
+		{Item: "a", Weight: 3}, // a second range for a
+	}
+	chooser := NewChooserT(choices...)
+
+	// Every item of nonzero weight can be forced, in any order, and its value
+	// is the smallest that picks it.
+	for _, item := range []string{"c", "a", "b", "a", "c"} {
+		v, err := ValueForItemT(chooser, item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := NewScriptedSource(v)
+		if got, err := chooser.PickWith(src); err != nil || got != item {
+			t.Errorf("PickWith(script %d) = %v, %v; want %v", v, got, err, item)
+		}
+		if src.Remaining() != 0 {
+			t.Errorf("Pick used %d values, want 1", 1-src.Remaining())
+		}
+		if v > 0 {
+			if got, _ := chooser.PickWith(NewScriptedSource(v - 1)); got == item {
+				t.Errorf("value %d also picks %v, so %d is not the smallest", v-1, item, v)
+			}
+		}
+	}
+
+	// Every value below the total weight picks some item, in order of the
+	// item's range.
+	counts := make(map[string]int)
+	for v := 0; v < int(chooser.TotalWeight()); v++ {
+		item, err := chooser.PickWith(NewScriptedSource(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[item]++
+	}
+	if want := map[string]int{"a": 4, "b": 5, "c": 2}; fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("values picked %v, want %v", counts, want)
+	}
+}
+
+func TestScriptedSourceWide(t *testing.T) {
+	if !is64Bit {
+		t.Skip("weights too large for a 32-bit uint")
+	}
+	big := ^uint(0) >> 2
+	chooser := NewChooser(Choice{Item: "a", Weight: big >> 20}, Choice{Item: "b", Weight: big})
+	for _, item := range []string{"a", "b"} {
+		v, err := ValueForItem(chooser, item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := chooser.PickWith(NewScriptedSource(v)); got != item {
+			t.Errorf("PickWith(script %d) = %v, want %v", v, got, item)
+		}
+	}
+}
+
+func TestScriptedSourceErrors(t *testing.T) {
+	chooser := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "zero", Weight: 0})
+	for _, item := range []interface{}{"zero", "missing"} {
+		if _, err := ValueForItem(chooser, item); err == nil {
+			t.Errorf("ValueForItem(%v) succeeded", item)
+		}
+	}
+	if _, err := ValueForItem(NewChooser(), "a"); err != ErrNoChoices {
+		t.Errorf("ValueForItem() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	src := NewScriptedSource(0)
+	chooser.PickWith(src)
+	assertPanics(t, "exhausted", ErrScriptExhausted, func() { chooser.PickWith(src) })
+	assertPanics(t, "out of range", "error: scripted value 1 out of range [0,1)", func() {
+		chooser.PickWith(NewScriptedSource(1))
+	})
+	assertPanics(t, "negative", "error: negative scripted value -1", func() { NewScriptedSource(-1) })
+}
+
+// assertPanics checks that f panics with want.
+func assertPanics(t *testing.T, name string, want interface{}, f func()) {
+	t.Helper()
+	defer func() {
+		if got := recover(); got != want {
+			t.Errorf("%s: panicked with %v, want %v", name, got, want)
+		}
+	}()
+	f()
+}
@@ -0,0 +1,50 @@
+package weightedrand
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"io"
+)
+
+// secureReader is the source of randomness for PickSecure. It is only ever
+// replaced in tests.
+var secureReader io.Reader = crand.Reader
+
+// PickSecure returns a single weighted random Choice.Item from the Chooser,
+// drawing from crypto/rand rather than math/rand. It is considerably slower
+// than Pick, but its output cannot be predicted from previous selections,
+// making it suitable for lotteries, giveaways and the like.
+//
+// An error is returned if the Chooser cannot be picked from, or if reading
+// from the secure source fails.
+func (chs ChooserT[T]) PickSecure() (T, error) {
+	var zero T
+	if err := chs.Err(); err != nil {
+		return chs.pickFallback(err)
+	}
+	r, err := secureIntn(secureReader, chs.max)
+	if err != nil {
+		return zero, err
+	}
+	i := chs.search(r + 1)
+	chs.observe(i)
+	return chs.data[i].Item, nil
+}
+
+// secureIntn returns a uniformly distributed uint64 in [0,n) read from rd.
+//
+// Reducing a random uint64 modulo n would favor the lower values whenever n
+// does not evenly divide 2^64, so values below 2^64 mod n are rejected and
+// drawn again, leaving a range whose size is an exact multiple of n.
+func secureIntn(rd io.Reader, n uint64) (uint64, error) {
+	min := -n % n // 2^64 mod n
+	var b [8]byte
+	for {
+		if _, err := io.ReadFull(rd, b[:]); err != nil {
+			return 0, err
+		}
+		if v := binary.BigEndian.Uint64(b[:]); v >= min {
+			return v % n, nil
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package weightedrand
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// uint64Stream returns a reader yielding each of vs as 8 big-endian bytes.
+func uint64Stream(vs ...uint64) *bytes.Reader {
+	b := make([]byte, 8*len(vs))
+	for i, v := range vs {
+		binary.BigEndian.PutUint64(b[8*i:], v)
+	}
+	return bytes.NewReader(b)
+}
+
+func withSecureReader(t *testing.T, r io.Reader) {
+	orig := secureReader
+	secureReader = r
+	t.Cleanup(func() { secureReader = orig })
+}
+
+func TestSecureIntn(t *testing.T) {
+	// 2^64 mod 10 == 6, so 0 through 5 must be rejected.
+	rd := uint64Stream(0, 5, 6, 17)
+	got, err := secureIntn(rd, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 6 {
+		t.Errorf("got %d, want 6", got)
+	}
+	if rd.Len() != 8 {
+		t.Errorf("consumed %d values, want 3", (32-rd.Len())/8)
+	}
+
+	// A power of two divides 2^64, so nothing is ever rejected.
+	rd = uint64Stream(0, 1)
+	if got, _ := secureIntn(rd, 8); got != 0 || rd.Len() != 8 {
+		t.Errorf("got %d with %d bytes left, want 0 with 8", got, rd.Len())
+	}
+}
+
+func TestPickSecure(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "b", Weight: 2},
+		Choice{Item: "c", Weight: 3},
+		Choice{Item: "d", Weight: 4},
+	) // totals: 1, 3, 6, 10
+
+	cases := []struct {
+		v    uint64
+		want string
+	}{
+		{10, "a"}, // r = 0
+		{11, "b"}, // r = 1
+		{12, "b"}, // r = 2
+		{13, "c"}, // r = 3
+		{15, "c"}, // r = 5
+		{16, "d"}, // r = 6
+		{19, "d"}, // r = 9
+		{20, "a"}, // r = 0 again
+	}
+	for _, tc := range cases {
+		withSecureReader(t, uint64Stream(tc.v))
+		got, err := chooser.PickSecure()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("PickSecure() with %d = %v, want %v", tc.v, got, tc.want)
+		}
+	}
+
+	withSecureReader(t, uint64Stream())
+	if _, err := chooser.PickSecure(); err == nil {
+		t.Error("expected error from exhausted reader")
+	}
+	withSecureReader(t, failingReader{})
+	if _, err := chooser.PickSecure(); !errors.Is(err, errFailingReader) {
+		t.Errorf("got %v, want %v", err, errFailingReader)
+	}
+	if _, err := NewChooser().PickSecure(); err == nil {
+		t.Error("expected error picking from empty chooser")
+	}
+}
+
+func TestPickSecureCrypto(t *testing.T) {
+	chooser := NewChooser(
+		Choice{Item: "never", Weight: 0},
+		Choice{Item: "always", Weight: 1},
+	)
+	for i := 0; i < 1000; i++ {
+		got, err := chooser.PickSecure()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "always" {
+			t.Fatalf("got %v, want always", got)
+		}
+	}
+}
+
+var errFailingReader = errors.New("failing reader")
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errFailingReader }
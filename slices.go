@@ -0,0 +1,80 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// A SliceChooser picks weighted random items from a slice, each weighted by
+// the weight at the same index of a parallel slice, as when both are loaded
+// from columnar storage. It keeps the caller's items slice rather than
+// copying it, and builds its cumulative totals straight from the weights, so
+// construction allocates nothing per item: no ChoiceT is built and no item is
+// boxed in an interface.
+//
+// Picks follow the order of the slices, as for an IndexChooser, so for a given
+// seed they differ from those of a Chooser over the same choices.
+// Concurrency follows the same rules as for a Chooser.
+type SliceChooser[T any] struct {
+	items []T
+	ic    IndexChooser
+}
+
+// NewChooserFromSlices initializes a new SliceChooser picking from items,
+// weighted by weights, configured by opts; only the options choosing a rand
+// source apply. The SliceChooser retains items, which must not be modified
+// while it is in use, but not weights. An error is returned if the slices
+// differ in length, and otherwise for any problem NewIndexChooser would
+// report, in which case Pick reports it too.
+func NewChooserFromSlices[T any](items []T, weights []uint, opts ...Option) (SliceChooser[T], error) {
+	if len(items) != len(weights) {
+		return SliceChooser[T]{}, fmt.Errorf("error: %d items but %d weights", len(items), len(weights))
+	}
+	ic, err := NewIndexChooser(weights, opts...)
+	return SliceChooser[T]{items: items, ic: ic}, err
+}
+
+// Pick returns a single weighted random item.
+func (sc SliceChooser[T]) Pick() (T, error) {
+	return sc.pickFrom(sc.ic.rng)
+}
+
+// PickSource returns a single weighted random item, drawing from rs rather
+// than the SliceChooser's own source. A nil rs falls back to the global
+// source in math/rand.
+func (sc SliceChooser[T]) PickSource(rs *rand.Rand) (T, error) {
+	return sc.pickFrom(fromRand(rs))
+}
+
+// pickFrom returns a single weighted random item drawn from rs.
+func (sc SliceChooser[T]) pickFrom(rs source) (T, error) {
+	var zero T
+	if err := sc.Err(); err != nil {
+		return zero, err
+	}
+	i, err := sc.ic.pickFrom(rs)
+	if err != nil {
+		return zero, err
+	}
+	return sc.items[i], nil
+}
+
+// Err returns the reason the SliceChooser cannot be picked from, or nil if it
+// is usable. Pick returns this same error.
+func (sc SliceChooser[T]) Err() error {
+	if len(sc.items) == 0 {
+		return ErrNoChoices
+	}
+	return sc.ic.Err()
+}
+
+// Len returns the number of items the SliceChooser picks among.
+func (sc SliceChooser[T]) Len() int {
+	return len(sc.items)
+}
+
+// TotalWeight returns the sum of the weights, or 0 if the SliceChooser cannot
+// be picked from.
+func (sc SliceChooser[T]) TotalWeight() uint64 {
+	return sc.ic.TotalWeight()
+}
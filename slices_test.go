@@ -0,0 +1,101 @@
+package weightedrand
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestNewChooserFromSlices(t *testing.T) {
+	items := []string{"a", "zero", "b", "c"}
+	weights := []uint{1, 0, 3, 4}
+	chooser, err := NewChooserFromSlices(items, weights, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chooser.Len() != 4 || chooser.TotalWeight() != 8 || chooser.Err() != nil {
+		t.Errorf("Len() = %d, TotalWeight() = %d, Err() = %v", chooser.Len(), chooser.TotalWeight(), chooser.Err())
+	}
+	counts := make(map[string]int)
+	const n = 40000
+	for i := 0; i < n; i++ {
+		item, err := chooser.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[item]++
+	}
+	for i, item := range items {
+		want := float64(weights[i]) / 8
+		if got := float64(counts[item]) / n; got < want-0.01 || got > want+0.01 {
+			t.Errorf("picked %q %.3f of the time, want %.3f", item, got, want)
+		}
+	}
+
+	// Picks follow the order of the slices, as for an IndexChooser.
+	ic, _ := NewIndexChooser(weights)
+	for i := 0; i < 100; i++ {
+		j, _ := ic.PickSource(rand.New(rand.NewSource(int64(i))))
+		if item, _ := chooser.PickSource(rand.New(rand.NewSource(int64(i)))); item != items[j] {
+			t.Fatalf("seed %d: picked %q, IndexChooser picked %q", i, item, items[j])
+		}
+	}
+}
+
+func TestNewChooserFromSlicesErrors(t *testing.T) {
+	if _, err := NewChooserFromSlices([]int{1, 2}, []uint{1}); err == nil || err.Error() != "error: 2 items but 1 weights" {
+		t.Errorf("error = %v for mismatched lengths", err)
+	}
+	chooser, err := NewChooserFromSlices([]int{}, []uint{})
+	if err != ErrNoChoices {
+		t.Errorf("error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := chooser.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+	var zero SliceChooser[int]
+	if _, err := zero.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v from the zero value, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewChooserFromSlices([]int{1}, []uint{0}); err != ErrAllZeroWeights {
+		t.Errorf("error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if is64Bit {
+		if _, err := NewChooserFromSlices([]int{1, 2}, []uint{^uint(0), 1}); err != ErrWeightOverflow {
+			t.Errorf("error = %v, want %v", err, ErrWeightOverflow)
+		}
+	}
+}
+
+// BenchmarkNewChooserFromSlices compares building a SliceChooser from
+// parallel slices of a million items and weights against first wrapping each
+// pair in a Choice for NewChooser.
+func BenchmarkNewChooserFromSlices(b *testing.B) {
+	const n = 1000000
+	items := make([]string, n)
+	weights := make([]uint, n)
+	for i := range items {
+		items[i] = "item"
+		weights[i] = uint(rand.Intn(1000))
+	}
+	b.Run("Slices", func(b *testing.B) {
+		b.ReportAllocs()
+		var sc SliceChooser[string]
+		for i := 0; i < b.N; i++ {
+			sc, _ = NewChooserFromSlices(items, weights)
+		}
+		runtime.KeepAlive(sc)
+	})
+	b.Run("Choices", func(b *testing.B) {
+		b.ReportAllocs()
+		var chs Chooser
+		for i := 0; i < b.N; i++ {
+			choices := make([]Choice, n)
+			for j := range choices {
+				choices[j] = Choice{Item: items[j], Weight: weights[j]}
+			}
+			chs, _ = NewChooserOpts(choices, WithBorrowedInput())
+		}
+		runtime.KeepAlive(chs)
+	})
+}
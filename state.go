@@ -0,0 +1,149 @@
+package weightedrand
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/rand"
+)
+
+// stateMagic prefixes every saved chooser state, followed by a single format
+// version byte.
+const stateMagic = "WRS"
+
+// stateVersion is the current saved state format version. It must be
+// incremented whenever the layout of savedState changes.
+const stateVersion = 1
+
+// savedState is the gob encoded body of a saved chooser state, followed in
+// the encoding by its CRC-32 checksum.
+type savedState struct {
+	Seed    int64
+	Draws   uint64
+	Chooser []byte // as encoded by MarshalBinary
+}
+
+// countingSource is a rand.Source64 that counts how many values it has
+// supplied since it was seeded, which together with the seed fixes its place
+// in the sequence.
+type countingSource struct {
+	src   rand.Source64
+	seed  int64
+	draws uint64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed, s.draws = seed, 0
+}
+
+// seededRand is the source of a chooser built WithSeed: a *rand.Rand drawing
+// exactly as one made by rand.New(rand.NewSource(seed)) would, but whose
+// position can be saved.
+type seededRand struct {
+	*rand.Rand
+	counter *countingSource
+}
+
+// newSeededRand returns a seededRand seeded with seed, advanced past its first
+// draws values.
+func newSeededRand(seed int64, draws uint64) seededRand {
+	src := rand.NewSource(seed).(rand.Source64)
+	for i := uint64(0); i < draws; i++ {
+		src.Uint64()
+	}
+	counter := &countingSource{src: src, seed: seed, draws: draws}
+	return seededRand{Rand: rand.New(counter), counter: counter}
+}
+
+// SaveState writes the Chooser's choices and totals, as MarshalBinary
+// encodes them, together with the position of its random sequence, so that
+// LoadState can restore a Chooser whose next picks continue exactly where
+// this one's leave off, as when a batch job checkpoints and later resumes in
+// another process. The Chooser must draw from a source of its own, built with
+// WithSeed; an error is returned otherwise. Pick statistics, hooks and other
+// options are not saved.
+func (chs ChooserT[T]) SaveState(w io.Writer) error {
+	rs, ok := chs.rng.(seededRand)
+	if !ok {
+		return errors.New("error: chooser state can only be saved with a source set by WithSeed")
+	}
+	encoded, err := chs.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	if err := gob.NewEncoder(&body).Encode(savedState{
+		Seed:    rs.counter.seed,
+		Draws:   rs.counter.draws,
+		Chooser: encoded,
+	}); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(stateMagic)
+	buf.WriteByte(stateVersion)
+	buf.Write(body.Bytes())
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(body.Bytes()))
+	buf.Write(sum[:])
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// LoadStateT reads a ChooserT saved by SaveState, whose source resumes the
+// saved random sequence at the point it was saved. Restoring replays the
+// sequence up to that point, taking time in proportion to the number of
+// values drawn before saving. An error is returned for state that is
+// truncated, corrupted or of an unknown format version.
+func LoadStateT[T any](r io.Reader) (ChooserT[T], error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ChooserT[T]{}, err
+	}
+	if len(data) < len(stateMagic)+1 || string(data[:len(stateMagic)]) != stateMagic {
+		return ChooserT[T]{}, errors.New("error: not a saved chooser state")
+	}
+	if v := data[len(stateMagic)]; v != stateVersion {
+		return ChooserT[T]{}, fmt.Errorf("error: unsupported chooser state version %d", v)
+	}
+	body := data[len(stateMagic)+1:]
+	if len(body) < 4 {
+		return ChooserT[T]{}, errors.New("error: corrupt chooser state: truncated")
+	}
+	body, sum := body[:len(body)-4], body[len(body)-4:]
+	if binary.BigEndian.Uint32(sum) != crc32.ChecksumIEEE(body) {
+		return ChooserT[T]{}, errors.New("error: corrupt chooser state: checksum mismatch")
+	}
+	var state savedState
+	if err := gob.NewDecoder(bytes.NewReader(body)).Decode(&state); err != nil {
+		return ChooserT[T]{}, fmt.Errorf("error: decoding chooser state: %w", err)
+	}
+
+	var chs ChooserT[T]
+	if err := chs.UnmarshalBinary(state.Chooser); err != nil {
+		return ChooserT[T]{}, err
+	}
+	chs.rng = newSeededRand(state.Seed, state.Draws)
+	return chs, nil
+}
+
+// LoadState reads a Chooser saved by SaveState. See LoadStateT.
+func LoadState(r io.Reader) (Chooser, error) {
+	return LoadStateT[interface{}](r)
+}
@@ -0,0 +1,139 @@
+package weightedrand
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveState(t *testing.T) {
+	choices := mockFrequencies(50)
+	uninterrupted, _ := NewChooserOpts(choices, WithSeed(11))
+	want := make([]interface{}, 2000)
+	for i := range want {
+		want[i] = uninterrupted.MustPick()
+	}
+
+	// Pick the first half, save, and resume in a fresh value, as another
+	// process would.
+	chooser, _ := NewChooserOpts(choices, WithSeed(11))
+	got := make([]interface{}, 0, len(want))
+	for i := 0; i < len(want)/2; i++ {
+		got = append(got, chooser.MustPick())
+	}
+	var buf bytes.Buffer
+	if err := chooser.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := LoadState(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Choices(), chooser.Choices()) {
+		t.Errorf("restored Choices() = %v, want %v", restored.Choices(), chooser.Choices())
+	}
+	for len(got) < len(want) {
+		got = append(got, restored.MustPick())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("resumed sequence differs from an uninterrupted run")
+	}
+
+	// A seeded chooser draws exactly as one given its own seeded *rand.Rand.
+	seeded, _ := NewChooserOpts(choices, WithSeed(12))
+	plain := NewChooserWithRand(rand.New(rand.NewSource(12)), choices...)
+	for i := 0; i < 1000; i++ {
+		if x, y := seeded.MustPick(), plain.MustPick(); x != y {
+			t.Fatalf("pick %d: WithSeed picked %v, rand.NewSource %v", i, x, y)
+		}
+	}
+}
+
+// TestSaveStateMixedDraws resumes after picks that draw differing numbers of
+// values each, from a typed chooser.
+func TestSaveStateMixedDraws(t *testing.T) {
+	choices := []ChoiceT[string]{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}, {Item: "c", Weight: 3}}
+	run := func(chs ChooserT[string]) []string {
+		items, _ := chs.PickN(5)
+		unique, _ := chs.PickUniqueN(2)
+		return append(items, unique...)
+	}
+	a, _ := NewChooserTOpts(choices, WithSeed(3))
+	b, _ := NewChooserTOpts(choices, WithSeed(3))
+	run(a)
+	run(b)
+	b.Shuffle()
+	a.Shuffle()
+
+	var buf bytes.Buffer
+	if err := b.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := LoadStateT[string](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if x, y := run(a), run(restored); !reflect.DeepEqual(x, y) {
+			t.Fatalf("round %d: %v, restored %v", i, x, y)
+		}
+	}
+}
+
+func TestSaveStateErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewChooser(Choice{Item: "a", Weight: 1}).SaveState(&buf); err == nil {
+		t.Error("SaveState() succeeded with the global source")
+	}
+	if err := NewChooserWithRand(rand.New(rand.NewSource(1)), Choice{Item: "a", Weight: 1}).SaveState(&buf); err == nil {
+		t.Error("SaveState() succeeded with a *rand.Rand")
+	}
+	zeros, _ := NewChooserOpts([]Choice{{Item: "a", Weight: 0}}, WithSeed(1))
+	if err := zeros.SaveState(&buf); err != ErrAllZeroWeights {
+		t.Errorf("SaveState() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	seeded := mustChooser(NewChooserOpts([]Choice{{Item: "a", Weight: 1}}, WithSeed(1)))
+	if err := seeded.SaveState(&failingWriter{}); err != errShortWrite {
+		t.Errorf("SaveState() error = %v, want %v", err, errShortWrite)
+	}
+
+	buf.Reset()
+	seeded.MustPick()
+	if err := seeded.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.Bytes()
+	flipped := append([]byte(nil), good...)
+	flipped[len(flipped)/2] ^= 0x40
+	version := append([]byte(nil), good...)
+	version[len(stateMagic)] = stateVersion + 1
+
+	cases := []struct {
+		name string
+		data []byte
+		want string // error prefix
+	}{
+		{"empty", nil, "error: not a saved chooser state"},
+		{"magic", append([]byte("WRC"), good[3:]...), "error: not a saved chooser state"},
+		{"version", version, "error: unsupported chooser state version 2"},
+		{"truncated header", good[:len(stateMagic)+3], "error: corrupt chooser state: truncated"},
+		{"truncated", good[:len(good)-10], "error: corrupt chooser state: checksum mismatch"},
+		{"corrupted", flipped, "error: corrupt chooser state: checksum mismatch"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadState(bytes.NewReader(tc.data))
+			if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("LoadState() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+	if _, err := LoadStateT[int](bytes.NewReader(good)); err == nil {
+		t.Error("LoadStateT[int]() succeeded for a chooser of other items")
+	}
+	if _, err := LoadState(iotestErrReader{}); err == nil {
+		t.Error("LoadState() succeeded for a failing reader")
+	}
+}
@@ -0,0 +1,59 @@
+package weightedrand
+
+import "sync/atomic"
+
+// pickStats counts the picks of each choice of a Chooser, indexed like its
+// data. A nil *pickStats counts nothing.
+type pickStats struct {
+	counts []uint64
+}
+
+func newPickStats(n int) *pickStats {
+	return &pickStats{counts: make([]uint64, n)}
+}
+
+// record counts a pick of the choice at index i, if s is not nil.
+func (s *pickStats) record(i int) {
+	if s != nil {
+		atomic.AddUint64(&s.counts[i], 1)
+	}
+}
+
+// load returns the count of picks of the choice at index i.
+func (s *pickStats) load(i int) uint64 {
+	return atomic.LoadUint64(&s.counts[i])
+}
+
+// reset sets every count back to zero, if s is not nil.
+func (s *pickStats) reset() {
+	if s != nil {
+		for i := range s.counts {
+			atomic.StoreUint64(&s.counts[i], 0)
+		}
+	}
+}
+
+// Stats returns how many times each item has been picked since the Chooser
+// was built, or since ResetStats, if it was built with WithStats; otherwise it
+// returns nil. Choices that have never been picked are included with a count
+// of zero, and the counts of choices holding equal items are summed. Mutating
+// the Chooser, or unmarshaling into it, restarts the counts.
+//
+// Items are used as map keys, so this panics if T is an interface type holding
+// an item that is not comparable.
+func (chs ChooserT[T]) Stats() map[interface{}]uint64 {
+	if chs.stats == nil {
+		return nil
+	}
+	stats := make(map[interface{}]uint64, len(chs.data))
+	for i, c := range chs.data {
+		stats[c.Item] += chs.stats.load(i)
+	}
+	return stats
+}
+
+// ResetStats sets every pick count reported by Stats back to zero. Picks made
+// concurrently may or may not be counted.
+func (chs ChooserT[T]) ResetStats() {
+	chs.stats.reset()
+}
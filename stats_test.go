@@ -0,0 +1,111 @@
+package weightedrand
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	chooser, err := NewChooserOpts([]Choice{
+		{Item: "a", Weight: 1},
+		{Item: "b", Weight: 2},
+		{Item: "c", Weight: 7},
+		{Item: "never", Weight: 0},
+	}, WithStats(), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := chooser.Stats(); len(stats) != 4 || stats["a"] != 0 {
+		t.Errorf("Stats() = %v before picking, want all zero", stats)
+	}
+
+	const n = 300000
+	for i := 0; i < n; i++ {
+		chooser.MustPick()
+	}
+	stats := chooser.Stats()
+	for item, p := range map[interface{}]float64{"a": 0.1, "b": 0.2, "c": 0.7, "never": 0} {
+		mean, sd := n*p, math.Sqrt(n*p*(1-p))
+		if got := float64(stats[item]); math.Abs(got-mean) > 4*sd {
+			t.Errorf("%v counted %v times, want %v ± %.0f", item, got, mean, 4*sd)
+		}
+	}
+
+	// Every counting pick method is recorded, and copies share the counters.
+	chooser.ResetStats()
+	chooser.PickN(10)
+	chooser.PickIndex()
+	chooser.PickSource(nil)
+	copied := chooser
+	copied.PickSecure()
+	if total := sumStats(chooser.Stats()); total != 13 {
+		t.Errorf("Stats() total %d after 13 picks, want 13", total)
+	}
+
+	chooser.Add(Choice{Item: "d", Weight: 1})
+	if stats := chooser.Stats(); len(stats) != 5 || sumStats(stats) != 0 {
+		t.Errorf("Stats() = %v after Add, want restarted counts", stats)
+	}
+
+	if stats := NewChooser(Choice{Item: "a", Weight: 1}).Stats(); stats != nil {
+		t.Errorf("Stats() = %v without WithStats, want nil", stats)
+	}
+}
+
+func sumStats(stats map[interface{}]uint64) uint64 {
+	var total uint64
+	for _, c := range stats {
+		total += c
+	}
+	return total
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	chooser, err := NewChooserOpts(mockFrequencies(10), WithStats(), WithParallelRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const goroutines, perGoroutine = 8, 5000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				chooser.MustPick()
+			}
+		}()
+	}
+	wg.Wait()
+	if total := sumStats(chooser.Stats()); total != goroutines*perGoroutine {
+		t.Errorf("Stats() total %d, want %d", total, goroutines*perGoroutine)
+	}
+}
+
+// mockFrequencies returns n choices of item i with weight i+1.
+func mockFrequencies(n int) []Choice {
+	choices := make([]Choice, n)
+	for i := range choices {
+		choices[i] = Choice{Item: i, Weight: uint(i + 1)}
+	}
+	return choices
+}
+
+func BenchmarkPickStats(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		name := "disabled"
+		var opts []Option
+		if enabled {
+			name = "enabled"
+			opts = append(opts, WithStats())
+		}
+		chooser, _ := NewChooserOpts(mockFrequencies(1000), opts...)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				chooser.Pick()
+			}
+		})
+	}
+}
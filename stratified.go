@@ -0,0 +1,150 @@
+package weightedrand
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// A StratifiedChooserT picks weighted random items like a ChooserT, except
+// that it guarantees every choice of nonzero weight a minimum exposure: each
+// is returned at least once in any window of perN consecutive picks, as set by
+// WithMinimumShare, so that no light choice is starved for long stretches.
+//
+// Each choice has a deadline, the last pick by which it must next be returned.
+// Most picks are weighted as usual. Only when the choices due soonest would
+// otherwise miss their deadlines, say j of them due within the next j picks,
+// is the pick forced to be among those j, still weighted among themselves.
+// Forced picks are rare when perN is large compared to the number of choices,
+// and the overall distribution then stays close to the weights; as perN falls
+// toward the number of choices, picks tend toward a rotation through all of
+// them. Choices of zero weight are never picked and have no deadline.
+//
+// Each pick takes O(n) time for n choices, to find the choices that are due.
+// A StratifiedChooserT guards its deadlines with a mutex, so it is safe for
+// concurrent use if its rand source is.
+type StratifiedChooserT[T any] struct {
+	mu    sync.Mutex
+	chs   ChooserT[T]
+	perN  int
+	step  int   // number of picks made
+	order []int // positions in chs.data of nonzero choices, by deadline
+	due   []int // deadline of each choice, indexed like chs.data
+}
+
+// A StratifiedChooser is a StratifiedChooserT over untyped items.
+type StratifiedChooser = StratifiedChooserT[interface{}]
+
+// NewStratifiedChooserT initializes a new StratifiedChooserT consisting of the
+// possible ChoiceT[T], configured by opts as for NewChooserTOpts, with
+// WithMinimumShare, which is required, setting the window. The guarantee
+// cannot be met if there are more choices of nonzero weight than picks in the
+// window, in which case an error is returned, as it is for any problem
+// NewChooserTOpts would report.
+func NewStratifiedChooserT[T any](cs []ChoiceT[T], opts ...Option) (*StratifiedChooserT[T], error) {
+	o, err := buildOptions(opts, chooserScope|stratifiedScope)
+	if err != nil {
+		return nil, err
+	}
+	if !o.perNSet {
+		return nil, errors.New("error: WithMinimumShare is required")
+	}
+	chs := newChooser(cs, o)
+	if err := chs.Err(); err != nil {
+		return nil, err
+	}
+	sc := &StratifiedChooserT[T]{chs: chs, perN: o.perN, due: make([]int, len(chs.data))}
+	for i, c := range chs.data {
+		if c.Weight > 0 {
+			sc.order = append(sc.order, i)
+		}
+	}
+	if o.perN < len(sc.order) {
+		return nil, fmt.Errorf("error: cannot return %d choices in every %d picks", len(sc.order), o.perN)
+	}
+	sc.reset()
+	return sc, nil
+}
+
+// NewStratifiedChooser initializes a new StratifiedChooser consisting of the
+// possible Choices. See NewStratifiedChooserT.
+func NewStratifiedChooser(cs []Choice, opts ...Option) (*StratifiedChooser, error) {
+	return NewStratifiedChooserT(cs, opts...)
+}
+
+// Pick returns a single weighted random Choice.Item, unless some choices must
+// be returned now to meet their deadlines, in which case it returns one of
+// those.
+func (sc *StratifiedChooserT[T]) Pick() (T, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	chs := sc.chs
+	var i int
+	if j := sc.forced(); j == 0 {
+		i = chs.pick(chs.rng)
+	} else {
+		var total uint64
+		for _, k := range sc.order[:j] {
+			total += uint64(chs.data[k].Weight)
+		}
+		i, _ = chs.pickAmong(sc.order[:j], total)
+	}
+	sc.advance(i)
+	chs.observe(i)
+	return chs.data[i].Item, nil
+}
+
+// forced returns the number of choices, soonest due, among which the next pick
+// must be made for all to meet their deadlines, or 0 if it is free. The j
+// choices due soonest must all be picked within the next j picks if the last
+// of them is due by then. For the smallest such j, picking any one of the j
+// keeps every deadline reachable, as does any pick if there is none.
+func (sc *StratifiedChooserT[T]) forced() int {
+	for j, i := range sc.order {
+		if sc.due[i] == sc.step+j {
+			return j + 1
+		}
+	}
+	return 0
+}
+
+// advance records a pick of the choice at position i in sc.chs.data, which is
+// next due perN picks from now, later than any other, and so moves to the end
+// of the order.
+func (sc *StratifiedChooserT[T]) advance(i int) {
+	for j, k := range sc.order {
+		if k == i {
+			copy(sc.order[j:], sc.order[j+1:])
+			sc.order[len(sc.order)-1] = i
+			break
+		}
+	}
+	sc.due[i] = sc.step + sc.perN
+	sc.step++
+}
+
+// Reset restarts the window, so that every choice is next due within perN
+// picks from now.
+func (sc *StratifiedChooserT[T]) Reset() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.reset()
+}
+
+func (sc *StratifiedChooserT[T]) reset() {
+	sc.step = 0
+	for _, i := range sc.order {
+		sc.due[i] = sc.perN - 1
+	}
+}
+
+// Window returns the number of consecutive picks in which every choice of
+// nonzero weight is returned at least once.
+func (sc *StratifiedChooserT[T]) Window() int {
+	return sc.perN
+}
+
+// Len returns the number of choices held by the StratifiedChooser.
+func (sc *StratifiedChooserT[T]) Len() int {
+	return sc.chs.Len()
+}
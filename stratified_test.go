@@ -0,0 +1,160 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// assertMinimumShare fails t unless every one of items appears in each window
+// of perN consecutive picks.
+func assertMinimumShare(t *testing.T, picks []interface{}, items []interface{}, perN int) {
+	t.Helper()
+	counts := make(map[interface{}]int)
+	for i, item := range picks {
+		counts[item]++
+		if i >= perN {
+			counts[picks[i-perN]]--
+		}
+		if i < perN-1 {
+			continue
+		}
+		for _, want := range items {
+			if counts[want] == 0 {
+				t.Fatalf("%v missing from picks %d to %d", want, i-perN+1, i)
+			}
+		}
+	}
+}
+
+func TestStratifiedChooser(t *testing.T) {
+	choices := []Choice{
+		{Item: "big", Weight: 900},
+		{Item: "mid", Weight: 95},
+		{Item: "small", Weight: 4},
+		{Item: "tiny", Weight: 1},
+		{Item: "off", Weight: 0},
+	}
+	items := []interface{}{"big", "mid", "small", "tiny"}
+	for _, perN := range []int{4, 5, 10, 100} {
+		sc, err := NewStratifiedChooser(choices, WithMinimumShare(perN), WithRand(rand.New(rand.NewSource(int64(perN)))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sc.Window() != perN || sc.Len() != 5 {
+			t.Errorf("Window(), Len() = %d, %d; want %d, 5", sc.Window(), sc.Len(), perN)
+		}
+		picks := make([]interface{}, 100000)
+		for i := range picks {
+			if picks[i], err = sc.Pick(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		assertMinimumShare(t, picks, items, perN)
+		for _, item := range picks {
+			if item == "off" {
+				t.Fatal("picked a choice of zero weight")
+			}
+		}
+		if perN == 4 {
+			// As many choices as picks in the window: a strict rotation.
+			for i := 4; i < len(picks); i++ {
+				if picks[i] != picks[i-4] {
+					t.Fatalf("picks %d and %d differ with a window of 4: %v, %v", i-4, i, picks[i-4], picks[i])
+				}
+			}
+		}
+	}
+}
+
+func TestStratifiedChooserDistribution(t *testing.T) {
+	choices := []Choice{{Item: "a", Weight: 60}, {Item: "b", Weight: 30}, {Item: "c", Weight: 9}, {Item: "d", Weight: 1}}
+	const perN = 200
+	sc, err := NewStratifiedChooser(choices, WithMinimumShare(perN), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 200000
+	picks := make([]interface{}, n)
+	counts := make(map[interface{}]int)
+	for i := range picks {
+		picks[i], _ = sc.Pick()
+		counts[picks[i]]++
+	}
+	assertMinimumShare(t, picks, []interface{}{"a", "b", "c", "d"}, perN)
+	for _, c := range choices {
+		want := float64(c.Weight) / 100
+		if got := float64(counts[c.Item]) / n; math.Abs(got-want) > 0.01 {
+			t.Errorf("%v picked %.4f of the time, want %.2f", c.Item, got, want)
+		}
+	}
+}
+
+func TestStratifiedChooserReset(t *testing.T) {
+	sc, err := NewStratifiedChooser([]Choice{{Item: "a", Weight: 1000}, {Item: "b", Weight: 1}}, WithMinimumShare(3), WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var picks []interface{}
+	for round := 0; round < 100; round++ {
+		for i := 0; i < 2; i++ {
+			item, _ := sc.Pick()
+			picks = append(picks, item)
+		}
+		sc.Reset()
+	}
+	// After each Reset, b is due within 3 picks, not 2, so the guarantee
+	// restarts rather than carrying over.
+	b := 0
+	for _, item := range picks {
+		if item == "b" {
+			b++
+		}
+	}
+	if b > 20 {
+		t.Errorf("b picked %d of %d times across resets, want rarely", b, len(picks))
+	}
+}
+
+func TestStratifiedChooserErrors(t *testing.T) {
+	choices := []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 1}, {Item: "c", Weight: 1}, {Item: "off", Weight: 0}}
+	cases := []struct {
+		name string
+		cs   []Choice
+		opts []Option
+		want string
+	}{
+		{"no share", choices, nil, "error: WithMinimumShare is required"},
+		{"too small", choices, []Option{WithMinimumShare(2)}, "error: cannot return 3 choices in every 2 picks"},
+		{"negative", choices, []Option{WithMinimumShare(-1)}, "error: cannot return 3 choices in every -1 picks"},
+		{"empty", nil, []Option{WithMinimumShare(1)}, ErrNoChoices.Error()},
+		{"all zero", choices[3:], []Option{WithMinimumShare(1)}, ErrAllZeroWeights.Error()},
+	}
+	for _, tc := range cases {
+		if _, err := NewStratifiedChooser(tc.cs, tc.opts...); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: NewStratifiedChooser() error = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+	if _, err := NewStratifiedChooser(choices, WithMinimumShare(3)); err != nil {
+		t.Errorf("NewStratifiedChooser() error = %v with a window of exactly 3", err)
+	}
+}
+
+func TestStratifiedChooserConcurrent(t *testing.T) {
+	sc, err := NewStratifiedChooserT([]ChoiceT[int]{{Item: 0, Weight: 10}, {Item: 1, Weight: 1}}, WithMinimumShare(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				sc.Pick()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,76 @@
+package weightedrand
+
+import "sync"
+
+// A SyncChooserT is a ChooserT guarded by a read-write mutex, so that it may be
+// picked from by many goroutines while its choices are being replaced or
+// mutated. Picks draw from the global source in math/rand, which is itself
+// safe for concurrent use, and so only ever take a read lock.
+//
+// The zero value is an empty SyncChooserT ready to use. A SyncChooserT must not
+// be copied after first use.
+type SyncChooserT[T any] struct {
+	mu  sync.RWMutex
+	chs ChooserT[T]
+}
+
+// A SyncChooser is a SyncChooserT over untyped items.
+type SyncChooser = SyncChooserT[interface{}]
+
+// NewSyncChooserT initializes a new SyncChooserT consisting of the possible
+// ChoiceT[T].
+func NewSyncChooserT[T any](cs ...ChoiceT[T]) *SyncChooserT[T] {
+	return &SyncChooserT[T]{chs: NewChooserT(cs...)}
+}
+
+// NewSyncChooser initializes a new SyncChooser consisting of the possible
+// Choices.
+func NewSyncChooser(cs ...Choice) *SyncChooser {
+	return NewSyncChooserT(cs...)
+}
+
+// Pick returns a single weighted random Choice.Item from the SyncChooser.
+func (s *SyncChooserT[T]) Pick() (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chs.PickSource(nil)
+}
+
+// Replace atomically replaces all the possible choices of the SyncChooser with
+// cs.
+func (s *SyncChooserT[T]) Replace(cs ...ChoiceT[T]) {
+	chs := NewChooserT(cs...)
+	s.mu.Lock()
+	s.chs = chs
+	s.mu.Unlock()
+}
+
+// Add adds c to the possible choices of the SyncChooser.
+func (s *SyncChooserT[T]) Add(c ChoiceT[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chs.Add(c)
+}
+
+// Remove removes the first choice whose Item equals item, and reports whether
+// one was found.
+func (s *SyncChooserT[T]) Remove(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chs.Remove(item)
+}
+
+// SetWeight sets the weight of the first choice whose Item equals item to w,
+// and reports whether one was found.
+func (s *SyncChooserT[T]) SetWeight(item T, w uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chs.SetWeight(item, w)
+}
+
+// Len returns the number of choices held by the SyncChooser.
+func (s *SyncChooserT[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chs.Len()
+}
@@ -0,0 +1,62 @@
+package weightedrand
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestSyncChooser picks from many goroutines while another replaces and mutates
+// the choices. Run with -race.
+func TestSyncChooser(t *testing.T) {
+	chooser := NewSyncChooser(Choice{Item: 0, Weight: 1})
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				item, err := chooser.Pick()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n := item.(int); n < 0 || n > 3 {
+					t.Errorf("picked unexpected item %v", item)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		switch i % 4 {
+		case 0:
+			chooser.Replace(Choice{Item: 0, Weight: 1}, Choice{Item: 1, Weight: 2})
+		case 1:
+			chooser.Add(Choice{Item: 2, Weight: 3})
+		case 2:
+			chooser.SetWeight(1, 5)
+		case 3:
+			chooser.Remove(2)
+			chooser.Add(Choice{Item: 3, Weight: 1})
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if n := chooser.Len(); n != 3 {
+		t.Errorf("Len() = %d, want 3", n)
+	}
+
+	var zero SyncChooser
+	if _, err := zero.Pick(); err != ErrNoChoices {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+}
@@ -0,0 +1,56 @@
+package weightedrand
+
+import (
+	"fmt"
+	"math"
+)
+
+// temperedResolution is the largest weight Tempered gives the heaviest choice,
+// the precision of a float64 mantissa.
+const temperedResolution = 1 << 53
+
+// Tempered returns a new Chooser holding the choices of chs, in their original
+// order, with each weight w replaced in proportion to w^(1/t). A temperature
+// of 1 keeps the distribution as it is; lower temperatures sharpen it towards
+// always picking the heaviest choice, and higher ones flatten it towards
+// uniform. Choices with a weight of zero stay at zero. The result keeps the
+// rand source of chs, and the options kept when chs is mutated.
+//
+// The powers are computed in log space, relative to the heaviest choice, and
+// rescaled to integers per choice: the heaviest gets a weight of 2^53 and the
+// others their share of that, so the total can be as much as 2^53 times the
+// number of choices. The scale is lowered where needed for that total to fit
+// in a uint64 and each weight in a uint, so that nothing overflows. A choice
+// whose share falls below that resolution gets a weight of zero. An error is
+// returned if chs cannot be picked from, or if t is not a positive, finite
+// number.
+func (chs ChooserT[T]) Tempered(t float64) (ChooserT[T], error) {
+	if err := chs.Err(); err != nil {
+		return ChooserT[T]{}, err
+	}
+	if !(t > 0) || math.IsInf(t, 1) {
+		return ChooserT[T]{}, fmt.Errorf("error: temperature %v is not positive and finite", t)
+	}
+	cs := chs.original()
+	maxLog := math.Inf(-1)
+	for _, c := range cs {
+		if c.Weight > 0 {
+			maxLog = math.Max(maxLog, math.Log(float64(c.Weight))/t)
+		}
+	}
+	scale := float64(temperedResolution)
+	if limit := float64(maxTotal / uint64(len(cs))); limit < scale {
+		scale = limit
+	}
+	if limit := float64(^uint(0)); limit < scale {
+		scale = limit
+	}
+	for i, c := range cs {
+		if c.Weight > 0 {
+			rel := math.Exp(math.Log(float64(c.Weight))/t - maxLog)
+			cs[i].Weight = uint(math.Round(rel * scale))
+		}
+	}
+	r := chs.derive(buildChooser(cs, false))
+	return r, r.Err()
+}
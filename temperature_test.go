@@ -0,0 +1,103 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTempered(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: 1, Weight: 1},
+		Choice{Item: 2, Weight: 2},
+		Choice{Item: 3, Weight: 3},
+		Choice{Item: 4, Weight: 4},
+		Choice{Item: "zero", Weight: 0},
+	)
+	const n = 20000
+	prevShare := 0.0
+	for _, temp := range []float64{100, 4, 2, 1, 0.5, 0.25, 0.01} {
+		tempered, err := chooser.Tempered(temp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tempered.rng != chooser.rng {
+			t.Error("Tempered() did not keep the rand source")
+		}
+		// Expected shares are proportional to w^(1/temp).
+		var sum float64
+		for w := 1; w <= 4; w++ {
+			sum += math.Pow(float64(w), 1/temp)
+		}
+		want := make(map[interface{}]float64)
+		for w := 1; w <= 4; w++ {
+			want[w] = math.Pow(float64(w), 1/temp) / sum
+		}
+		shares := pickShares(t, tempered, n)
+		assertShares(t, shares, want)
+		if shares[4] <= prevShare {
+			t.Errorf("temperature %v: heaviest share %.3f, want more than %.3f at higher temperature", temp, shares[4], prevShare)
+		}
+		prevShare = shares[4]
+	}
+
+	// Temperature 1 keeps the proportions, up to rounding.
+	one, _ := chooser.Tempered(1)
+	w := one.Weights()
+	for i := 1; i < 4; i++ {
+		if ratio := float64(w[i]) / float64(w[0]); math.Abs(ratio-float64(i+1)) > 1e-9 {
+			t.Errorf("Tempered(1) weights %v, want proportional to 1:2:3:4", w)
+			break
+		}
+	}
+
+	// The scale applies to each choice, not to the total.
+	if is64Bit {
+		if got := uint64(one.Weights()[3]); got != temperedResolution {
+			t.Errorf("Tempered(1) heaviest weight = %d, want %d", got, uint64(temperedResolution))
+		}
+		if total := one.TotalWeight(); total <= temperedResolution {
+			t.Errorf("Tempered(1) total weight = %d, want more than %d", total, uint64(temperedResolution))
+		}
+	}
+
+	configured := configuredChooser(t, chooser.Choices()...)
+	tempered, err := configured.Tempered(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDerived(t, "Tempered()", tempered, configured)
+}
+
+func TestTemperedExtremes(t *testing.T) {
+	// Huge weights with a tiny temperature neither overflow nor lose the
+	// heaviest choice.
+	big := NewChooser(
+		Choice{Item: "a", Weight: uint(maxInt) / 2},
+		Choice{Item: "b", Weight: uint(maxInt)/2 - 1},
+		Choice{Item: "c", Weight: 1},
+	)
+	cold, err := big.Tempered(1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := cold.Weights(); w[0] == 0 || w[2] != 0 {
+		t.Errorf("Tempered(1e-6) weights = %v, want the heaviest only", w)
+	}
+	hot, err := big.Tempered(1e9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := hot.Weights(); float64(w[2]) < 0.9*float64(w[0]) {
+		t.Errorf("Tempered(1e9) weights = %v, want nearly uniform", w)
+	}
+
+	for _, temp := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := big.Tempered(temp); err == nil {
+			t.Errorf("Tempered(%v): expected error", temp)
+		}
+	}
+	if _, err := NewChooser().Tempered(1); err != ErrNoChoices {
+		t.Errorf("Tempered() error = %v, want %v", err, ErrNoChoices)
+	}
+}
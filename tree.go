@@ -0,0 +1,66 @@
+package weightedrand
+
+import "fmt"
+
+// A TreeChooser makes a two-level weighted random selection: first a group,
+// keyed by K, by the group weights, and then an item of type T from that
+// group's own Chooser by its weights. An item is therefore picked with the
+// probability of its group times its probability within the group.
+//
+// Each group Chooser draws from its own rand source, and the groups from the
+// global source in math/rand, so a TreeChooser is safe for concurrent use
+// under the same conditions as its Choosers.
+type TreeChooser[K comparable, T any] struct {
+	groups ChooserT[K]
+	inner  map[K]ChooserT[T]
+}
+
+// NewTreeChooser initializes a new TreeChooser picking among groups, weighted
+// by groupWeights, which are ordered as by NewChooserFromMap. Groups missing
+// from groupWeights are never picked.
+//
+// An error is returned if a group of nonzero weight has no Chooser, or for any
+// problem NewChooserTErr would report about the group weights. A group Chooser
+// that cannot be picked from, for instance because it is empty, is only
+// reported when Pick selects it.
+func NewTreeChooser[K comparable, T any](groups map[K]ChooserT[T], groupWeights map[K]uint) (TreeChooser[K, T], error) {
+	for k, w := range groupWeights {
+		if _, ok := groups[k]; !ok && w > 0 {
+			return TreeChooser[K, T]{}, fmt.Errorf("error: no chooser for group %v", k)
+		}
+	}
+	inner := make(map[K]ChooserT[T], len(groups))
+	for k, chs := range groups {
+		inner[k] = chs
+	}
+	tree := TreeChooser[K, T]{groups: NewChooserFromMap(groupWeights), inner: inner}
+	return tree, tree.groups.Err()
+}
+
+// Pick returns a single weighted random item from a weighted random group of
+// the TreeChooser. An error from the group Chooser is returned wrapped with
+// the group's key, so errors.Is still matches it.
+func (tree TreeChooser[K, T]) Pick() (T, error) {
+	_, item, err := tree.PickGroup()
+	return item, err
+}
+
+// PickGroup is like Pick, but also returns the key of the group the item was
+// picked from.
+func (tree TreeChooser[K, T]) PickGroup() (K, T, error) {
+	var zero T
+	k, err := tree.groups.Pick()
+	if err != nil {
+		return k, zero, err
+	}
+	item, err := tree.inner[k].Pick()
+	if err != nil {
+		return k, zero, fmt.Errorf("group %v: %w", k, err)
+	}
+	return k, item, nil
+}
+
+// Len returns the number of groups held by the TreeChooser.
+func (tree TreeChooser[K, T]) Len() int {
+	return tree.groups.Len()
+}
@@ -0,0 +1,70 @@
+package weightedrand
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTreeChooser(t *testing.T) {
+	rand.Seed(1)
+	tree, err := NewTreeChooser(map[string]ChooserT[string]{
+		"acme": NewChooserT(
+			ChoiceT[string]{Item: "acme-1", Weight: 1},
+			ChoiceT[string]{Item: "acme-2", Weight: 3},
+		),
+		"globex": NewChooserT(ChoiceT[string]{Item: "globex-1", Weight: 5}),
+		"unused": NewChooserT(ChoiceT[string]{Item: "unused-1", Weight: 5}),
+	}, map[string]uint{"acme": 2, "globex": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", tree.Len())
+	}
+
+	want := map[string]float64{
+		"acme-1":   2.0 / 3 * 1 / 4,
+		"acme-2":   2.0 / 3 * 3 / 4,
+		"globex-1": 1.0 / 3,
+	}
+	const n = 30000
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		group, item, err := tree.PickGroup()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item[:len(group)] != group {
+			t.Fatalf("PickGroup() = %v, %v; item not in group", group, item)
+		}
+		counts[item]++
+	}
+	for item, c := range counts {
+		if _, ok := want[item]; !ok {
+			t.Errorf("picked %v, which is in no weighted group", item)
+			continue
+		}
+		if got := float64(c) / n; math.Abs(got-want[item]) > 0.01 {
+			t.Errorf("%v picked %.3f of the time, want %.3f", item, got, want[item])
+		}
+	}
+}
+
+func TestTreeChooserErrors(t *testing.T) {
+	if _, err := NewTreeChooser(map[string]Chooser{}, map[string]uint{"missing": 1}); err == nil {
+		t.Error("expected error for a weighted group without a chooser")
+	}
+	if _, err := NewTreeChooser(map[string]Chooser{"a": NewChooser()}, nil); err != ErrNoChoices {
+		t.Errorf("NewTreeChooser() error = %v, want %v", err, ErrNoChoices)
+	}
+
+	tree, err := NewTreeChooser(map[string]Chooser{"empty": NewChooser()}, map[string]uint{"empty": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.Pick(); !errors.Is(err, ErrNoChoices) {
+		t.Errorf("Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+}
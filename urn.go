@@ -0,0 +1,125 @@
+package weightedrand
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// An UrnT draws weighted random items without replacement, like a raffle
+// drum: each Draw removes its winner, so that it cannot be drawn again, until
+// the urn is empty. Choices with a weight of zero are never drawn.
+//
+// The weights are kept in a Fenwick tree, so each Draw takes O(log n) time to
+// find its winner and to take its weight out of the running sums, rather than
+// rebuilding the totals.
+//
+// An UrnT is not safe for concurrent use.
+type UrnT[T any] struct {
+	data      []ChoiceT[T]
+	tree      []uint64 // Fenwick tree of the weights still in the urn
+	total     uint64
+	remaining int
+	rng       source
+}
+
+// An Urn is an UrnT over untyped items.
+type Urn = UrnT[interface{}]
+
+// NewUrnT initializes a new UrnT holding the possible ChoiceT[T], which draws
+// from the global source in math/rand. An error is returned for any set of
+// choices NewChooserTErr would reject.
+func NewUrnT[T any](cs ...ChoiceT[T]) (*UrnT[T], error) {
+	return NewUrnTWithRand(nil, cs...)
+}
+
+// NewUrnTWithRand initializes a new UrnT holding the possible ChoiceT[T],
+// which draws its random numbers from r. A nil r falls back to the global
+// source in math/rand.
+func NewUrnTWithRand[T any](r *rand.Rand, cs ...ChoiceT[T]) (*UrnT[T], error) {
+	if len(cs) == 0 {
+		return nil, ErrNoChoices
+	}
+	u := &UrnT[T]{
+		data: append([]ChoiceT[T](nil), cs...),
+		tree: make([]uint64, len(cs)+1),
+		rng:  fromRand(r),
+	}
+	var total uint64
+	for _, c := range cs {
+		if uint64(c.Weight) > maxTotal-total {
+			return nil, ErrWeightOverflow
+		}
+		total += uint64(c.Weight)
+	}
+	if total == 0 {
+		return nil, ErrAllZeroWeights
+	}
+	u.Reset()
+	return u, nil
+}
+
+// NewUrn initializes a new Urn holding the possible Choices. See NewUrnT.
+func NewUrn(cs ...Choice) (*Urn, error) {
+	return NewUrnT(cs...)
+}
+
+// NewUrnWithRand initializes a new Urn holding the possible Choices, which
+// draws its random numbers from r. See NewUrnTWithRand.
+func NewUrnWithRand(r *rand.Rand, cs ...Choice) (*Urn, error) {
+	return NewUrnTWithRand(r, cs...)
+}
+
+// Draw removes a weighted random Choice.Item from among those left in the urn
+// and returns it. ErrUrnEmpty is returned once every choice of nonzero weight
+// has been drawn.
+func (u *UrnT[T]) Draw() (T, error) {
+	if u.remaining == 0 {
+		var zero T
+		return zero, ErrUrnEmpty
+	}
+	i := u.find(uint64n(u.rng, u.total))
+	w := uint64(u.data[i].Weight)
+	for j := i + 1; j < len(u.tree); j += j & -j {
+		u.tree[j] -= w
+	}
+	u.total -= w
+	u.remaining--
+	return u.data[i].Item, nil
+}
+
+// find returns the index into u.data of the choice whose share of the running
+// sums of the weights left in the urn holds r, which must be below u.total.
+func (u *UrnT[T]) find(r uint64) int {
+	pos := 0
+	for step := 1 << (bits.Len(uint(len(u.data))) - 1); step > 0; step >>= 1 {
+		if next := pos + step; next < len(u.tree) && u.tree[next] <= r {
+			pos = next
+			r -= u.tree[next]
+		}
+	}
+	return pos
+}
+
+// Remaining returns the number of choices of nonzero weight not yet drawn.
+func (u *UrnT[T]) Remaining() int {
+	return u.remaining
+}
+
+// Reset puts every drawn choice back into the urn.
+func (u *UrnT[T]) Reset() {
+	for i := range u.tree {
+		u.tree[i] = 0
+	}
+	u.total, u.remaining = 0, 0
+	for i, c := range u.data {
+		if c.Weight > 0 {
+			u.remaining++
+		}
+		u.total += uint64(c.Weight)
+		j := i + 1
+		u.tree[j] += uint64(c.Weight)
+		if k := j + j&-j; k < len(u.tree) {
+			u.tree[k] += u.tree[j]
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package weightedrand
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestUrnDrain(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 8, 100, 1000} {
+		choices := make([]ChoiceT[int], n)
+		nonzero := 0
+		for i := range choices {
+			w := uint(rand.Intn(5))
+			if i == 0 {
+				w = 1
+			}
+			if w > 0 {
+				nonzero++
+			}
+			choices[i] = ChoiceT[int]{Item: i, Weight: w}
+		}
+		urn, err := NewUrnTWithRand(rand.New(rand.NewSource(int64(n))), choices...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for round := 0; round < 2; round++ {
+			if urn.Remaining() != nonzero {
+				t.Fatalf("n=%d: Remaining() = %d, want %d", n, urn.Remaining(), nonzero)
+			}
+			seen := make(map[int]bool)
+			for urn.Remaining() > 0 {
+				item, err := urn.Draw()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if seen[item] {
+					t.Fatalf("n=%d: drew %d twice", n, item)
+				}
+				if choices[item].Weight == 0 {
+					t.Fatalf("n=%d: drew zero weight item %d", n, item)
+				}
+				seen[item] = true
+			}
+			if len(seen) != nonzero {
+				t.Errorf("n=%d: drew %d items, want %d", n, len(seen), nonzero)
+			}
+			if _, err := urn.Draw(); err != ErrUrnEmpty {
+				t.Errorf("n=%d: Draw() error = %v on empty urn, want %v", n, err, ErrUrnEmpty)
+			}
+			urn.Reset()
+		}
+	}
+}
+
+func TestUrnFirstDraw(t *testing.T) {
+	choices := []Choice{
+		{Item: "a", Weight: 1},
+		{Item: "zero", Weight: 0},
+		{Item: "b", Weight: 2},
+		{Item: "c", Weight: 3},
+		{Item: "d", Weight: 4},
+	}
+	urn, err := NewUrnWithRand(rand.New(rand.NewSource(1)), choices...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 100000
+	firsts := make(map[interface{}]int)
+	seconds := make(map[interface{}]int)
+	for i := 0; i < n; i++ {
+		first, _ := urn.Draw()
+		second, _ := urn.Draw()
+		firsts[first]++
+		seconds[second]++
+		urn.Reset()
+	}
+	for _, c := range choices {
+		p := float64(c.Weight) / 10
+		if got := float64(firsts[c.Item]) / n; math.Abs(got-p) > 0.01 {
+			t.Errorf("%v drawn first %.3f of the time, want %.3f", c.Item, got, p)
+		}
+	}
+	// The second draw is distributed as if the first had been removed.
+	var p float64
+	for _, f := range choices {
+		if f.Weight > 0 && f.Item != "a" {
+			p += float64(f.Weight) / 10 * 1 / float64(10-f.Weight)
+		}
+	}
+	if got := float64(seconds["a"]) / n; math.Abs(got-p) > 0.01 {
+		t.Errorf("a drawn second %.3f of the time, want %.3f", got, p)
+	}
+}
+
+func TestUrnErrors(t *testing.T) {
+	if _, err := NewUrn(); err != ErrNoChoices {
+		t.Errorf("NewUrn() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewUrn(Choice{Item: "a"}, Choice{Item: "b"}); err != ErrAllZeroWeights {
+		t.Errorf("NewUrn() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if is64Bit {
+		if _, err := NewUrn(Choice{Weight: ^uint(0)}, Choice{Weight: 1}); err != ErrWeightOverflow {
+			t.Errorf("NewUrn() error = %v, want %v", err, ErrWeightOverflow)
+		}
+	}
+
+	// The choices given are copied.
+	choices := []Choice{{Item: "a", Weight: 1}}
+	urn, err := NewUrn(choices...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	choices[0].Item = "mutated"
+	if item, err := urn.Draw(); err != nil || item != "a" {
+		t.Errorf("Draw() = %v, %v; want a", item, err)
+	}
+}
+
+func BenchmarkUrnDrain(b *testing.B) {
+	for _, n := range []int{10, 1000, 100000} {
+		choices := make([]Choice, n)
+		for i := range choices {
+			choices[i] = Choice{Item: i, Weight: uint(i%10 + 1)}
+		}
+		urn, _ := NewUrnWithRand(rand.New(rand.NewSource(1)), choices...)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if urn.Remaining() == 0 {
+					urn.Reset()
+				}
+				urn.Draw()
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package weightedrand
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A ProblemKind categorizes a Problem found by ValidateChoices.
+type ProblemKind int
+
+const (
+	// ZeroWeight marks a choice with a weight of zero, which is kept but can
+	// never be picked.
+	ZeroWeight ProblemKind = iota
+	// DuplicateItem marks a choice whose item equals that of an earlier
+	// choice, so that the two are picked as separate entries.
+	DuplicateItem
+	// WouldOverflow marks the choice whose weight takes the running total past
+	// math.MaxUint64, so that a chooser built from the choices is unusable with
+	// ErrWeightOverflow.
+	WouldOverflow
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case ZeroWeight:
+		return "zero weight"
+	case DuplicateItem:
+		return "duplicate item"
+	case WouldOverflow:
+		return "would overflow"
+	default:
+		return fmt.Sprintf("ProblemKind(%d)", int(k))
+	}
+}
+
+// A Problem describes a questionable choice found by ValidateChoices.
+type Problem struct {
+	Index  int // position of the choice in the input
+	Weight uint
+	Kind   ProblemKind
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("choice %d (weight %d): %v", p.Index, p.Weight, p.Kind)
+}
+
+// ValidateChoicesT inspects cs, in input order, for choices that would make a
+// chooser built from them behave other than a caller likely intends, and
+// returns one Problem for each, ordered by index and then by Kind. It returns
+// nil if there are none.
+//
+// Every choice with a weight of zero is reported, as is every choice whose item
+// equals, by ==, that of an earlier choice; items whose type cannot be
+// compared are never reported as duplicates. Only the first choice at which
+// the weights overflow is reported.
+//
+// An empty cs, or one whose weights are all zero, has no Problem specific to
+// any one choice; NewChooserTErr reports it as ErrNoChoices or
+// ErrAllZeroWeights.
+func ValidateChoicesT[T any](cs []ChoiceT[T]) []Problem {
+	var problems []Problem
+	seen := make(map[interface{}]bool, len(cs))
+	var total uint64
+	overflowed := false
+	for i, c := range cs {
+		if c.Weight == 0 {
+			problems = append(problems, Problem{Index: i, Weight: c.Weight, Kind: ZeroWeight})
+		}
+		var item interface{} = c.Item
+		if t := reflect.TypeOf(item); t == nil || t.Comparable() {
+			if seen[item] {
+				problems = append(problems, Problem{Index: i, Weight: c.Weight, Kind: DuplicateItem})
+			}
+			seen[item] = true
+		}
+		if w := uint64(c.Weight); !overflowed {
+			if w > maxTotal-total {
+				overflowed = true
+				problems = append(problems, Problem{Index: i, Weight: c.Weight, Kind: WouldOverflow})
+			}
+			total += w
+		}
+	}
+	return problems
+}
+
+// ValidateChoices inspects cs for questionable choices. See ValidateChoicesT.
+func ValidateChoices(cs []Choice) []Problem {
+	return ValidateChoicesT(cs)
+}
+
+// Validate checks that the Chooser can be picked from and that its internal
+// state is consistent with its choices. It returns nil for a healthy Chooser,
+// and ErrNoChoices or ErrAllZeroWeights for one that is empty or has only
+// zero weights. ErrWeightOverflow is wrapped with the original index of the
+// choice at which the weights overflow.
+//
+// Zero weights and duplicate items do not make a Chooser unhealthy; use
+// ValidateChoicesT to find them.
+func (chs ChooserT[T]) Validate() error {
+	if err := chs.Err(); err != nil {
+		if err == ErrWeightOverflow {
+			for _, p := range ValidateChoicesT(chs.original()) {
+				if p.Kind == WouldOverflow {
+					return fmt.Errorf("choice %d: %w", p.Index, err)
+				}
+			}
+		}
+		return err
+	}
+	if chs.numTotals() != len(chs.data) {
+		return fmt.Errorf("error: %d totals for %d choices", chs.numTotals(), len(chs.data))
+	}
+	var total uint64
+	for i, c := range chs.data {
+		total += uint64(c.Weight)
+		if chs.total(i) != total {
+			return fmt.Errorf("error: cumulative total %d of choice %d does not match its weights", chs.total(i), chs.index(i))
+		}
+	}
+	if total != chs.max {
+		return fmt.Errorf("error: total weight %d does not match the weights summing to %d", chs.max, total)
+	}
+	return nil
+}
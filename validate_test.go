@@ -0,0 +1,121 @@
+package weightedrand
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateChoices(t *testing.T) {
+	big := ^uint(0)/2 + 1
+	cases := []struct {
+		name    string
+		choices []Choice
+		want    []Problem
+	}{
+		{"empty", nil, nil},
+		{"healthy", []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}}, nil},
+		{"zero weight", []Choice{{Item: "a", Weight: 1}, {Item: "b"}}, []Problem{
+			{Index: 1, Weight: 0, Kind: ZeroWeight},
+		}},
+		{"all zero", []Choice{{Item: "a"}, {Item: "b"}}, []Problem{
+			{Index: 0, Weight: 0, Kind: ZeroWeight},
+			{Index: 1, Weight: 0, Kind: ZeroWeight},
+		}},
+		{"duplicate item", []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: 2}, {Item: "a", Weight: 3}, {Item: "a", Weight: 4}}, []Problem{
+			{Index: 2, Weight: 3, Kind: DuplicateItem},
+			{Index: 3, Weight: 4, Kind: DuplicateItem},
+		}},
+		{"duplicate nil items", []Choice{{Weight: 1}, {Weight: 1}}, []Problem{
+			{Index: 1, Weight: 1, Kind: DuplicateItem},
+		}},
+		{"uncomparable items", []Choice{{Item: []int{1}, Weight: 1}, {Item: []int{1}, Weight: 1}}, nil},
+		{"would overflow", []Choice{{Item: "a", Weight: 1}, {Item: "b", Weight: ^uint(0)}, {Item: "c", Weight: 1}}, wouldOverflow(1, ^uint(0))},
+		{"zero duplicate", []Choice{{Item: "a", Weight: 1}, {Item: "a"}}, []Problem{
+			{Index: 1, Weight: 0, Kind: ZeroWeight},
+			{Index: 1, Weight: 0, Kind: DuplicateItem},
+		}},
+		{"duplicate overflow", []Choice{{Item: "a", Weight: big}, {Item: "a", Weight: big}, {Item: "a"}}, append(
+			[]Problem{{Index: 1, Weight: big, Kind: DuplicateItem}},
+			append(wouldOverflow(1, big),
+				Problem{Index: 2, Weight: 0, Kind: ZeroWeight},
+				Problem{Index: 2, Weight: 0, Kind: DuplicateItem})...,
+		)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ValidateChoices(tc.choices); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ValidateChoices() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// wouldOverflow returns the WouldOverflow problem expected at choice i of
+// weight w, which only a 64-bit uint can cause.
+func wouldOverflow(i int, w uint) []Problem {
+	if !is64Bit {
+		return nil
+	}
+	return []Problem{{Index: i, Weight: w, Kind: WouldOverflow}}
+}
+
+func TestValidateChoicesT(t *testing.T) {
+	got := ValidateChoicesT([]ChoiceT[int]{{Item: 1, Weight: 1}, {Item: 2}, {Item: 1, Weight: 5}})
+	want := []Problem{{Index: 1, Kind: ZeroWeight}, {Index: 2, Weight: 5, Kind: DuplicateItem}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateChoicesT() = %v, want %v", got, want)
+	}
+	if s := got[1].String(); s != "choice 2 (weight 5): duplicate item" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		chooser Chooser
+		want    error
+	}{
+		{"healthy", NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2}), nil},
+		{"zero and duplicate", NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "a"}), nil},
+		{"unsorted", mustChooser(NewChooserOpts([]Choice{{Weight: 3}, {Weight: 1}}, WithoutSort())), nil},
+		{"empty", NewChooser(), ErrNoChoices},
+		{"all zero", NewChooser(Choice{Item: "a"}, Choice{Item: "b"}), ErrAllZeroWeights},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.chooser.Validate(); err != tc.want {
+				t.Errorf("Validate() = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateOverflow(t *testing.T) {
+	if !is64Bit {
+		t.Skip("uint weights cannot overflow uint64 on 32-bit platforms")
+	}
+	chooser := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2}, Choice{Item: "c", Weight: ^uint(0)})
+	err := chooser.Validate()
+	if !errors.Is(err, ErrWeightOverflow) {
+		t.Fatalf("Validate() = %v, want %v", err, ErrWeightOverflow)
+	}
+	if want := "choice 2: " + ErrWeightOverflow.Error(); err.Error() != want {
+		t.Errorf("Validate() = %q, want %q", err, want)
+	}
+}
+
+func TestValidateInconsistent(t *testing.T) {
+	chooser := NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2})
+	chooser.data[0].Weight = 5
+	if err := chooser.Validate(); err == nil {
+		t.Error("Validate() = nil for a weight changed behind the chooser's back")
+	}
+
+	chooser = NewChooser(Choice{Item: "a", Weight: 1}, Choice{Item: "b", Weight: 2})
+	chooser.max = 4
+	if err := chooser.Validate(); err == nil {
+		t.Error("Validate() = nil for a stale total weight")
+	}
+}
@@ -12,55 +12,514 @@ package weightedrand
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"math/bits"
 	"math/rand"
+	"reflect"
 	"sort"
 )
 
-// Choice is a generic wrapper that can be used to add weights for any object
-type Choice struct {
-	Item   interface{}
+// maxInt is the largest value representable by an int on this platform.
+const maxInt = int(^uint(0) >> 1)
+
+// maxTotal is the largest sum of weights a Chooser can represent, the same on
+// every platform.
+const maxTotal = uint64(math.MaxUint64)
+
+// Errors returned by the choosers in this package, possibly wrapped with more
+// context. Test for them with errors.Is.
+var (
+	// ErrNoChoices is returned when picking from a chooser with no choices.
+	ErrNoChoices = errors.New("error: no choices")
+	// ErrAllZeroWeights is returned when picking from a chooser whose choices
+	// all have a weight of zero.
+	ErrAllZeroWeights = errors.New("error: all choices have zero weight")
+	// ErrWeightOverflow is returned when the weights of a chooser sum to more
+	// than it can represent.
+	ErrWeightOverflow = errors.New("error: total weight overflows")
+	// ErrInvalidWeight is returned for a weight that can never be valid, such
+	// as a negative or NaN float.
+	ErrInvalidWeight = errors.New("error: invalid weight")
+	// ErrUrnEmpty is returned when drawing from an Urn with nothing left in
+	// it.
+	ErrUrnEmpty = errors.New("error: urn is empty")
+	// ErrScriptExhausted is the value a ScriptedSource panics with when asked
+	// for more values than it was given.
+	ErrScriptExhausted = errors.New("error: scripted source exhausted")
+	// ErrQuotaExhausted is returned when picking from a QuotaChooser whose
+	// quotas have all been used up.
+	ErrQuotaExhausted = errors.New("error: all quotas exhausted")
+)
+
+// ChoiceT is a generic wrapper that can be used to add weights for any object
+// of type T.
+type ChoiceT[T any] struct {
+	Item   T
 	Weight uint
 }
 
+// Choice is a wrapper that can be used to add weights for any object
+type Choice = ChoiceT[interface{}]
+
+// A ChooserT caches many possible ChoiceT[T] in a structure designed to improve
+// performance on repeated calls for weighted random selection. Unlike Chooser,
+// the selected item is returned as a T, so callers need no type assertion.
+type ChooserT[T any] struct {
+	data     []ChoiceT[T]
+	indices  []int // original position of each of data, nil if unsorted
+	totals   []uint64
+	totals32 []uint32 // totals narrowed by WithCompactTotals, replacing totals
+	compact  bool     // set by WithCompactTotals
+	max      uint64
+	valid    bool
+	err      error
+	rng      source
+	stats    *pickStats // nil unless enabled by WithStats
+	lookup   lookupTable
+	merged   int // choices merged away by WithDedup
+	onPick   func(item interface{}, index int)
+	backup   *T // item set by WithFallback, nil if none
+}
+
 // A Chooser caches many possible Choices in a structure designed to improve
 // performance on repeated calls for weighted random selection.
-type Chooser struct {
-	data   []Choice
-	totals []int
-	max    int
-	valid  bool
+//
+// A Chooser using the global source in math/rand, or created with
+// NewChooserParallel, is safe for concurrent Pick calls, as long as it is not
+// mutated at the same time. One drawing from its own *rand.Rand is not, and
+// neither is any Chooser while Add, Remove or SetWeight is called; use a
+// SyncChooser for that.
+type Chooser = ChooserT[interface{}]
+
+// NewChooserT initializes a new ChooserT consisting of the possible ChoiceT[T].
+//
+// Choices with a weight of zero are kept, and counted by Len, but will never be
+// picked. If every weight is zero, or the weights sum to more than
+// math.MaxUint64, the ChooserT is unusable: Err and every Pick will report the
+// problem.
+//
+// The ChooserT works on its own copy of cs, so the caller's slice is neither
+// reordered nor retained. See WithBorrowedInput to avoid the copy.
+//
+// The selection algorithm is part of the package's compatibility promise, so
+// that a given seed and input order always produce the same picks. The choices
+// are stably sorted by ascending weight, keeping equal weights in input order,
+// and their cumulative totals computed in 64 bits. Each pick then draws
+// r = Int63n(total)+1 from the source, using Intn instead when the total fits
+// in 32 bits, and for a total above math.MaxInt64 taking Uint64 draws until
+// one falls below it. The first choice whose cumulative total is at least r is
+// selected.
+func NewChooserT[T any](cs ...ChoiceT[T]) ChooserT[T] {
+	chs, _ := NewChooserTOpts(cs)
+	return chs
 }
 
-// NewChooser initializes a new Chooser consisting of the possible Choices.
-func NewChooser(cs ...Choice) Chooser {
-	totals := make([]int, len(cs))
+// newChooser builds a ChooserT from cs as configured by o, working on a copy of
+// cs unless o says otherwise.
+func newChooser[T any](cs []ChoiceT[T], o options) ChooserT[T] {
+	merged := 0
+	switch {
+	case o.dedup:
+		var err error
+		if cs, merged, err = dedup(cs, o.dedupKey); err != nil {
+			return ChooserT[T]{err: err, rng: o.rng}
+		}
+	case !o.borrowInput:
+		cs = append([]ChoiceT[T](nil), cs...)
+	}
+	if o.invert {
+		invertWeights(cs)
+	}
+	chs := buildChooser(cs, o.presorted)
+	chs.merged = merged
+	chs.rng = o.rng
+	if o.stats {
+		chs.stats = newPickStats(len(chs.data))
+	}
+	chs.buildLookup(o.lookupLimit)
+	chs.compact = o.compact
+	chs.compactTotals()
+	chs.onPick = o.onPick
+	if o.hasFallback {
+		var item T
+		if o.fallback != nil {
+			var ok bool
+			if item, ok = o.fallback.(T); !ok {
+				return ChooserT[T]{data: chs.data, indices: chs.indices, err: fmt.Errorf("error: fallback %v is not a %v", o.fallback, reflect.TypeOf(&item).Elem())}
+			}
+		}
+		chs.backup = &item
+	}
+	return chs
+}
+
+// buildChooser computes the cumulative totals of cs, first sorting it in place
+// by weight unless presorted is set, while recording the original positions.
+func buildChooser[T any](cs []ChoiceT[T], presorted bool) ChooserT[T] {
+	totals := make([]uint64, len(cs))
+	var indices []int
 
 	if len(cs) > 0 {
-		sort.Slice(cs, func(i, j int) bool {
-			return cs[i].Weight < cs[j].Weight
-		})
-		runningTotal := 0
-		for i, c := range cs {
-			runningTotal += int(c.Weight)
-			totals[i] = runningTotal
+		if !presorted {
+			indices = make([]int, len(cs))
+			for i := range indices {
+				indices[i] = i
+			}
+			sort.Stable(byWeight[T]{cs, indices})
+		}
+		max, err := accumulate(totals, func(i int) uint { return cs[i].Weight })
+		switch err {
+		case nil:
+			return ChooserT[T]{data: cs, indices: indices, totals: totals, max: max, valid: true}
+		case ErrWeightOverflow:
+			return ChooserT[T]{data: cs, indices: indices, err: err}
+		default:
+			return ChooserT[T]{data: cs, indices: indices, totals: totals, err: err}
 		}
-		return Chooser{data: cs, totals: totals, max: runningTotal, valid: true}
 	} else {
-		return Chooser{data: cs, totals: totals, max: 0, valid: false}
+		return ChooserT[T]{data: cs, totals: totals, max: 0, valid: false}
+	}
+}
+
+// accumulate sets each of totals to the running sum of the weights up to and
+// including weight(i), and returns their sum. It reports ErrWeightOverflow if
+// the sum exceeds maxTotal, or ErrAllZeroWeights if it is zero.
+func accumulate(totals []uint64, weight func(i int) uint) (uint64, error) {
+	runningTotal := uint64(0)
+	for i := range totals {
+		w := uint64(weight(i))
+		if w > maxTotal-runningTotal {
+			return 0, ErrWeightOverflow
+		}
+		runningTotal += w
+		totals[i] = runningTotal
+	}
+	if runningTotal == 0 {
+		return 0, ErrAllZeroWeights
+	}
+	return runningTotal, nil
+}
+
+// invertWeights replaces each weight w of cs with max-w+1, where max is the
+// largest of them, saturating at the largest uint.
+func invertWeights[T any](cs []ChoiceT[T]) {
+	var max uint
+	for _, c := range cs {
+		if c.Weight > max {
+			max = c.Weight
+		}
+	}
+	for i, c := range cs {
+		if d := max - c.Weight; d < ^uint(0) {
+			cs[i].Weight = d + 1
+		} else {
+			cs[i].Weight = d
+		}
 	}
 }
 
+// byWeight sorts choices by ascending weight, carrying their original
+// positions along with them.
+type byWeight[T any] struct {
+	cs      []ChoiceT[T]
+	indices []int
+}
+
+func (b byWeight[T]) Len() int           { return len(b.cs) }
+func (b byWeight[T]) Less(i, j int) bool { return b.cs[i].Weight < b.cs[j].Weight }
+func (b byWeight[T]) Swap(i, j int) {
+	b.cs[i], b.cs[j] = b.cs[j], b.cs[i]
+	b.indices[i], b.indices[j] = b.indices[j], b.indices[i]
+}
+
+// NewChooserTErr initializes a new ChooserT consisting of the possible
+// ChoiceT[T], like NewChooserT, but reports up front any problem that would
+// otherwise only surface from Pick: no choices, every weight zero, or weights
+// summing to more than math.MaxUint64.
+func NewChooserTErr[T any](cs ...ChoiceT[T]) (ChooserT[T], error) {
+	chs := NewChooserT(cs...)
+	return chs, chs.Err()
+}
+
+// NewChooserTWithRand initializes a new ChooserT consisting of the possible
+// ChoiceT[T], which draws its random numbers from r. A nil r falls back to the
+// global source in math/rand.
+func NewChooserTWithRand[T any](r *rand.Rand, cs ...ChoiceT[T]) ChooserT[T] {
+	chs, _ := NewChooserTOpts(cs, WithRand(r))
+	return chs
+}
+
+// NewChooserTParallel initializes a new ChooserT consisting of the possible
+// ChoiceT[T], which draws its random numbers from a pool of independently
+// seeded sources rather than the global source in math/rand.
+//
+// The global source is guarded by a mutex, which limits the throughput of many
+// goroutines picking at once. Picks from the pool scale with the number of
+// cores instead, but are not reproducible by seeding math/rand.
+func NewChooserTParallel[T any](cs ...ChoiceT[T]) ChooserT[T] {
+	chs, _ := NewChooserTOpts(cs, WithParallelRand())
+	return chs
+}
+
+// NewChooser initializes a new Chooser consisting of the possible Choices.
+func NewChooser(cs ...Choice) Chooser {
+	return NewChooserT(cs...)
+}
+
+// NewChooserErr initializes a new Chooser consisting of the possible Choices,
+// reporting up front any problem that would otherwise only surface from Pick.
+// See NewChooserTErr.
+func NewChooserErr(cs ...Choice) (Chooser, error) {
+	return NewChooserTErr(cs...)
+}
+
+// NewChooserWithRand initializes a new Chooser consisting of the possible
+// Choices, which draws its random numbers from r. A nil r falls back to the
+// global source in math/rand.
+//
+// Note that a *rand.Rand is not safe for concurrent use, so neither is the
+// resulting Chooser.
+func NewChooserWithRand(r *rand.Rand, cs ...Choice) Chooser {
+	return NewChooserTWithRand(r, cs...)
+}
+
+// NewChooserParallel initializes a new Chooser consisting of the possible
+// Choices, which is safe for concurrent use and scales to many goroutines
+// picking at once. See NewChooserTParallel.
+func NewChooserParallel(cs ...Choice) Chooser {
+	return NewChooserTParallel(cs...)
+}
+
 // Pick returns a single weighted random Choice.Item from the Chooser.
-func (chs Chooser) Pick() (interface{}, error) {
-	if !chs.valid {
-		return nil, errors.New("error: no choices")
+func (chs ChooserT[T]) Pick() (T, error) {
+	return chs.pickFrom(chs.rng)
+}
+
+// MustPick is like Pick but panics if the Chooser cannot be picked from. It is
+// intended for Choosers whose construction was already checked, for instance
+// with NewChooserErr.
+func (chs ChooserT[T]) MustPick() T {
+	item, err := chs.Pick()
+	if err != nil {
+		panic(err)
+	}
+	return item
+}
+
+// PickSource returns a single weighted random Choice.Item from the Chooser,
+// drawing from rs rather than the Chooser's own source. A nil rs falls back to
+// the global source in math/rand.
+func (chs ChooserT[T]) PickSource(rs *rand.Rand) (T, error) {
+	return chs.pickFrom(fromRand(rs))
+}
+
+// PickWith returns a single weighted random Choice.Item from the Chooser,
+// drawing from s, which may be a *rand.Rand from math/rand/v2, rather than the
+// Chooser's own source. See WithSource.
+func (chs ChooserT[T]) PickWith(s Source) (T, error) {
+	return chs.pickFrom(fromSource(s))
+}
+
+// pickFrom returns a single weighted random Choice.Item drawn from rs.
+func (chs ChooserT[T]) pickFrom(rs source) (T, error) {
+	if err := chs.Err(); err != nil {
+		return chs.pickFallback(err)
 	}
-	r := rand.Intn(chs.max) + 1
-	i := sort.SearchInts(chs.totals, r)
+	i := chs.pick(rs)
+	chs.observe(i)
 	return chs.data[i].Item, nil
 }
 
-// Len ...
-func (chs Chooser) Len() int {
+// PickIndex returns the index of a single weighted random choice, as a
+// position in the list of choices the Chooser was constructed with (before it
+// sorted them) rather than the Chooser's internal order. After Add, the new
+// choice comes last; after Remove, later choices shift down by one.
+func (chs ChooserT[T]) PickIndex() (int, error) {
+	if err := chs.Err(); err != nil {
+		return -1, err
+	}
+	i := chs.pick(chs.rng)
+	chs.observe(i)
+	return chs.index(i), nil
+}
+
+// PickN returns n weighted random Choice.Items from the Chooser, selected with
+// replacement, so the same item may appear many times. It is equivalent to
+// calling Pick n times, but avoids the per-call overhead.
+func (chs ChooserT[T]) PickN(n int) ([]T, error) {
+	if n < 0 {
+		return nil, errors.New("error: negative n")
+	}
+	if err := chs.Err(); err != nil {
+		if _, ok := chs.fallbackFor(err); !ok {
+			return nil, err
+		}
+	}
+	items := make([]T, n)
+	chs.FillPicks(items)
+	return items, nil
+}
+
+// FillPicks fills dst with len(dst) weighted random Choice.Items from the
+// Chooser, selected with replacement as by PickN but without allocating.
+// dst is left untouched if the Chooser cannot be picked from.
+func (chs ChooserT[T]) FillPicks(dst []T) error {
+	if err := chs.Err(); err != nil {
+		item, ok := chs.fallbackFor(err)
+		if !ok {
+			return err
+		}
+		for i := range dst {
+			chs.observeFallback()
+			dst[i] = item
+		}
+		return nil
+	}
+	for i := range dst {
+		j := chs.pick(chs.rng)
+		chs.observe(j)
+		dst[i] = chs.data[j].Item
+	}
+	return nil
+}
+
+// FillPickIndices fills dst with the indices of len(dst) weighted random
+// choices, as reported by PickIndex, without allocating. dst is left
+// untouched if the Chooser cannot be picked from.
+func (chs ChooserT[T]) FillPickIndices(dst []int) error {
+	if err := chs.Err(); err != nil {
+		return err
+	}
+	for i := range dst {
+		j := chs.pick(chs.rng)
+		chs.observe(j)
+		dst[i] = chs.index(j)
+	}
+	return nil
+}
+
+// pick returns the index into chs.data of a weighted random choice drawn from
+// rs. The Chooser must be valid.
+func (chs ChooserT[T]) pick(rs source) int {
+	r := uint64n(rs, chs.max)
+	if i, ok := chs.lookup.find(r); ok {
+		return i
+	}
+	return chs.search(r + 1)
+}
+
+// search returns the index of the first choice whose cumulative total is at
+// least r, searching whichever of the wide and compact totals is held.
+func (chs ChooserT[T]) search(r uint64) int {
+	if chs.totals32 != nil {
+		return searchTotals(chs.totals32, uint32(r))
+	}
+	return searchTotals(chs.totals, r)
+}
+
+// searchTotals returns the index of the first of the nondecreasing totals that
+// is at least r, or len(totals) if there is none, like sort.SearchInts.
+//
+// Rather than narrowing a range from both ends, it halves the length of the
+// range left to search and moves its base by the borrow of a subtraction,
+// which is 1 exactly when the total compared is below r. The loop then runs
+// exactly log2(n) times with no branch on the comparison, which matters more
+// than any comparison saved by stopping early, as random picks defeat the
+// branch predictor.
+func searchTotals[U uint32 | uint64](totals []U, r U) int {
+	n := len(totals)
+	if n == 0 {
+		return 0
+	}
+	base := 0
+	for n > 1 {
+		half := n >> 1
+		_, less := bits.Sub64(uint64(totals[base+half-1]), uint64(r), 0)
+		base += half * int(less)
+		n -= half
+	}
+	_, less := bits.Sub64(uint64(totals[base]), uint64(r), 0)
+	return base + int(less)
+}
+
+// observe records a pick of the choice at position i in chs.data in the pick
+// statistics, and reports it to the hook set by WithOnPick, if either is
+// enabled.
+func (chs ChooserT[T]) observe(i int) {
+	chs.stats.record(i)
+	if chs.onPick != nil {
+		chs.onPick(chs.data[i].Item, chs.index(i))
+	}
+}
+
+// fallbackFor returns the item set by WithFallback, reporting whether it stands
+// in for a pick that failed with err.
+func (chs ChooserT[T]) fallbackFor(err error) (T, bool) {
+	if chs.backup == nil || (err != ErrNoChoices && err != ErrAllZeroWeights) {
+		var zero T
+		return zero, false
+	}
+	return *chs.backup, true
+}
+
+// pickFallback returns the item set by WithFallback in place of a pick that
+// failed with err, reporting it to the WithOnPick hook, or else err.
+func (chs ChooserT[T]) pickFallback(err error) (T, error) {
+	item, ok := chs.fallbackFor(err)
+	if !ok {
+		return item, err
+	}
+	chs.observeFallback()
+	return item, nil
+}
+
+// observeFallback reports a pick of the item set by WithFallback to the hook
+// set by WithOnPick, if any.
+func (chs ChooserT[T]) observeFallback() {
+	if chs.onPick != nil {
+		chs.onPick(*chs.backup, -1)
+	}
+}
+
+// index maps a position in chs.data to the original position of that choice.
+func (chs ChooserT[T]) index(i int) int {
+	if chs.indices == nil {
+		return i
+	}
+	return chs.indices[i]
+}
+
+// original returns a copy of the choices of chs in their original order.
+func (chs ChooserT[T]) original() []ChoiceT[T] {
+	return chs.appendOriginal(make([]ChoiceT[T], 0, len(chs.data)))
+}
+
+// appendOriginal appends the choices of chs to cs in their original order.
+func (chs ChooserT[T]) appendOriginal(cs []ChoiceT[T]) []ChoiceT[T] {
+	n := len(cs)
+	cs = append(cs, chs.data...)
+	for i, c := range chs.data {
+		cs[n+chs.index(i)] = c
+	}
+	return cs
+}
+
+// Err returns the reason the Chooser cannot be picked from, because it has no
+// choices, they all have zero weight, or their weights overflowed; or nil if it
+// is usable. Every Pick method returns this same error, unless WithFallback
+// supplies an item in its place.
+func (chs ChooserT[T]) Err() error {
+	if chs.err != nil {
+		return chs.err
+	}
+	if !chs.valid {
+		return ErrNoChoices
+	}
+	return nil
+}
+
+// Len returns the number of choices held by the Chooser.
+func (chs ChooserT[T]) Len() int {
 	return len(chs.data)
 }
@@ -1,7 +1,11 @@
 package weightedrand
 
 import (
+	"errors"
+	"math"
 	"math/rand"
+	"reflect"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -45,7 +49,10 @@ func TestWeightedChoice(t *testing.T) {
 	chooser := NewChooser(choices...)
 	t.Log("values in chooser", chooser.totals)
 	for i := 0; i < 1000000; i++ {
-		c := chooser.Pick()
+		c, err := chooser.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
 		chosenCount[c.(int)]++
 	}
 
@@ -55,9 +62,11 @@ func TestWeightedChoice(t *testing.T) {
 	}
 
 	// Test that higher weighted choices were chosen more often than their lower
-	// weighted peers.
-	for i, c := range choices[0 : len(choices)-1] {
-		next := choices[i+1]
+	// weighted peers. NewChooser leaves choices unsorted, so walk the sorted
+	// copy it keeps internally.
+	sorted := chooser.data
+	for i, c := range sorted[0 : len(sorted)-1] {
+		next := sorted[i+1]
 		cw, nw := c.Weight, next.Weight
 		if !(chosenCount[int(cw)] < chosenCount[int(nw)]) {
 			t.Error("Value not lesser", cw, nw, chosenCount[int(cw)], chosenCount[int(nw)])
@@ -66,6 +75,613 @@ func TestWeightedChoice(t *testing.T) {
 
 }
 
+type fruit struct {
+	name  string
+	price int
+}
+
+// TestChooserT ensures the generic ChooserT returns items of the concrete type
+// it was constructed with, for value, pointer and struct item types.
+func TestChooserT(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		chooser := NewChooserT(
+			ChoiceT[string]{Item: "a", Weight: 0},
+			ChoiceT[string]{Item: "b", Weight: 1},
+		)
+		for i := 0; i < 100; i++ {
+			s, err := chooser.Pick()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != "b" {
+				t.Fatalf("got %q, want %q", s, "b")
+			}
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		want := fruit{name: "🍉", price: 3}
+		chooser := NewChooserT(ChoiceT[fruit]{Item: want, Weight: 1})
+		f, err := chooser.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f != want {
+			t.Errorf("got %v, want %v", f, want)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		want := &fruit{name: "🥑", price: 5}
+		chooser := NewChooserT(
+			ChoiceT[*fruit]{Item: &fruit{name: "🍆"}, Weight: 0},
+			ChoiceT[*fruit]{Item: want, Weight: 5},
+		)
+		f, err := chooser.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f != want {
+			t.Errorf("got %p, want %p", f, want)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		chooser := NewChooserT[*fruit]()
+		f, err := chooser.Pick()
+		if err == nil {
+			t.Error("expected error picking from empty chooser")
+		}
+		if f != nil {
+			t.Errorf("got %v, want zero value", f)
+		}
+	})
+}
+
+// TestNewChooserWithRand ensures choosers drawing from identically seeded
+// sources produce identical pick sequences.
+func TestNewChooserWithRand(t *testing.T) {
+	choices := mockChoices(100)
+	for i := range choices {
+		choices[i].Item = i
+	}
+	c1 := NewChooserWithRand(rand.New(rand.NewSource(42)), choices...)
+	c2 := NewChooserWithRand(rand.New(rand.NewSource(42)), choices...)
+	c3 := NewChooser(choices...)
+	rs := rand.New(rand.NewSource(42))
+	for i := 0; i < 1000; i++ {
+		a, err := c1.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := c2.Pick()
+		c, _ := c3.PickSource(rs)
+		if a != b || a != c {
+			t.Fatalf("pick %d diverged: %v, %v, %v", i, a, b, c)
+		}
+	}
+
+	// A nil source falls back to the global one rather than panicking.
+	if _, err := NewChooserWithRand(nil, choices...).Pick(); err != nil {
+		t.Error(err)
+	}
+	if _, err := c1.PickSource(nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPickN(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 0},
+		Choice{Item: "b", Weight: 1},
+		Choice{Item: "c", Weight: 3},
+	)
+	items, err := chooser.PickN(10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 10000 {
+		t.Fatalf("got %d items, want 10000", len(items))
+	}
+	counts := make(map[interface{}]int)
+	for _, item := range items {
+		counts[item]++
+	}
+	if counts["a"] != 0 {
+		t.Errorf("zero weight item picked %d times", counts["a"])
+	}
+	if !(counts["b"] < counts["c"]) {
+		t.Errorf("lighter item picked more often: %v", counts)
+	}
+
+	// Same seed, same sequence as repeated Pick calls.
+	chooser.rng = rand.New(rand.NewSource(1))
+	for i, want := range items[:100] {
+		if got, _ := chooser.Pick(); got != want {
+			t.Fatalf("pick %d: got %v, want %v", i, got, want)
+		}
+	}
+
+	if items, err := chooser.PickN(0); err != nil || len(items) != 0 {
+		t.Errorf("PickN(0) = %v, %v; want empty slice", items, err)
+	}
+	if _, err := chooser.PickN(-1); err == nil {
+		t.Error("expected error for negative n")
+	}
+	if _, err := NewChooser().PickN(1); err == nil {
+		t.Error("expected error picking from empty chooser")
+	}
+}
+
+// TestNewChooserOverflow ensures weights summing past math.MaxUint64 are
+// reported rather than wrapping around. Only a 64-bit uint can hold weights
+// that large.
+func TestNewChooserOverflow(t *testing.T) {
+	cases := map[string][]uint{
+		"maximum uint plus one": {^uint(0), 1},
+		"two halves":            {^uint(0)/2 + 1, ^uint(0)/2 + 1},
+		"many small":            append(make([]uint, 100), ^uint(0)/3, ^uint(0)/3, ^uint(0)/3, 2),
+	}
+	for name, weights := range cases {
+		t.Run(name, func(t *testing.T) {
+			if !is64Bit {
+				t.Skip("weights cannot overflow int64 with a 32-bit uint")
+			}
+			choices := make([]Choice, len(weights))
+			for i, w := range weights {
+				choices[i] = Choice{Item: i, Weight: w}
+			}
+			chooser := NewChooser(choices...)
+			if err := chooser.Err(); err != ErrWeightOverflow {
+				t.Errorf("Err() = %v, want %v", err, ErrWeightOverflow)
+			}
+			if _, err := chooser.Pick(); err != ErrWeightOverflow {
+				t.Errorf("Pick() error = %v, want %v", err, ErrWeightOverflow)
+			}
+		})
+	}
+
+	// Summing exactly to the largest uint is fine, and to math.MaxUint64 on
+	// 64-bit platforms.
+	chooser := NewChooser(Choice{Item: 1, Weight: ^uint(0) - 1}, Choice{Item: 2, Weight: 1})
+	if err := chooser.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+	if _, err := chooser.Pick(); err != nil {
+		t.Error(err)
+	}
+	if err := NewChooser().Err(); err != ErrNoChoices {
+		t.Errorf("Err() = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+// TestNewChooserWideTotals covers totals past math.MaxInt64, up to
+// math.MaxUint64, which only a 64-bit uint can reach.
+func TestNewChooserWideTotals(t *testing.T) {
+	if !is64Bit {
+		t.Skip("uint cannot hold weights past math.MaxUint32")
+	}
+	half := uint(maxInt) + 1
+	cases := map[string][]uint{
+		"total maxInt64/2 plus one": {uint(maxInt) / 2, 1},
+		"total maxInt64 plus one":   {uint(maxInt), 1},
+		"total maxUint64":           {half, uint(maxInt)},
+		"maximum uint alone":        {^uint(0)},
+		"straddling maxInt64":       {half / 2, half / 2, half / 2, 1},
+	}
+	for name, weights := range cases {
+		t.Run(name, func(t *testing.T) {
+			choices := make([]ChoiceT[int], len(weights))
+			var want uint64
+			for i, w := range weights {
+				choices[i] = ChoiceT[int]{Item: i, Weight: w}
+				want += uint64(w)
+			}
+			chooser, err := NewChooserTOpts(choices, WithSeed(1))
+			if err != nil {
+				t.Fatalf("NewChooserTOpts() error = %v", err)
+			}
+			if got := chooser.TotalWeight(); got != want {
+				t.Errorf("TotalWeight() = %d, want %d", got, want)
+			}
+
+			// Each bucket ends exactly at its cumulative total.
+			for i, total := range chooser.totals {
+				item := chooser.data[i].Item
+				if got, err := chooser.PickFromInt(total - 1); err != nil || got != item {
+					t.Errorf("PickFromInt(%d) = %v, %v; want %v", total-1, got, err, item)
+				}
+				if total == want {
+					continue
+				}
+				if got, err := chooser.PickFromInt(total); err != nil || got == item {
+					t.Errorf("PickFromInt(%d) = %v, %v; want a later choice than %v", total, got, err, item)
+				}
+			}
+
+			const n = 100000
+			counts := make([]int, len(weights))
+			for i := 0; i < n; i++ {
+				counts[chooser.MustPick()]++
+			}
+			for i, w := range weights {
+				p := float64(w) / float64(want)
+				if got := float64(counts[i]) / n; math.Abs(got-p) > 0.01 {
+					t.Errorf("choice %d picked %.3f of the time, want %.3f", i, got, p)
+				}
+			}
+		})
+	}
+}
+
+// TestNewChooser32BitBoundary covers weights and totals around the limits of
+// 32-bit integers, which used to wrap around where int is 32 bits wide.
+func TestNewChooser32BitBoundary(t *testing.T) {
+	cases := map[string][]uint{
+		"total maxInt32":          {math.MaxInt32 - 1, 1},
+		"total maxInt32 plus one": {math.MaxInt32, 1},
+		"single weight 2^31":      {1 << 31, 1},
+		"maximum uint32 twice":    {math.MaxUint32, math.MaxUint32},
+		"halves above maxInt32":   {1 << 31, 1 << 31, 1 << 31},
+	}
+	for name, weights := range cases {
+		t.Run(name, func(t *testing.T) {
+			choices := make([]ChoiceT[int], len(weights))
+			var want uint64
+			for i, w := range weights {
+				choices[i] = ChoiceT[int]{Item: i, Weight: w}
+				want += uint64(w)
+			}
+			chooser, err := NewChooserTOpts(choices, WithSeed(1))
+			if err != nil {
+				t.Fatalf("NewChooserTOpts() error = %v", err)
+			}
+			if got := chooser.TotalWeight(); got != want {
+				t.Errorf("TotalWeight() = %d, want %d", got, want)
+			}
+			if got := chooser.totals[len(chooser.totals)-1]; got != want {
+				t.Errorf("last total = %d, want %d", got, want)
+			}
+
+			const n = 100000
+			counts := make([]int, len(weights))
+			for i := 0; i < n; i++ {
+				counts[chooser.MustPick()]++
+			}
+			for i, w := range weights {
+				p := float64(w) / float64(want)
+				if got := float64(counts[i]) / n; math.Abs(got-p) > 0.01 {
+					t.Errorf("choice %d picked %.3f of the time, want %.3f", i, got, p)
+				}
+			}
+		})
+	}
+}
+
+// TestZeroWeights covers choosers where some or all choices have zero weight.
+func TestZeroWeights(t *testing.T) {
+	// A single zero weight choice used to panic inside rand.Intn(0).
+	chooser := NewChooser(Choice{Item: "a", Weight: 0})
+	if _, err := chooser.Pick(); err != ErrAllZeroWeights {
+		t.Errorf("Pick() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if chooser.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", chooser.Len())
+	}
+
+	chooser = NewChooser(
+		Choice{Item: "zero1", Weight: 0},
+		Choice{Item: "one", Weight: 1},
+		Choice{Item: "zero2", Weight: 0},
+		Choice{Item: "two", Weight: 2},
+		Choice{Item: "zero3", Weight: 0},
+	)
+	if chooser.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", chooser.Len())
+	}
+	for i := 0; i < 10000; i++ {
+		item, err := chooser.Pick()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item != "one" && item != "two" {
+			t.Fatalf("picked zero weight item %v", item)
+		}
+	}
+}
+
+// TestZeroWeightsNeverPanic checks that no way of picking from a chooser
+// without a nonzero weight panics, however it was built, reporting
+// ErrAllZeroWeights instead.
+func TestZeroWeightsNeverPanic(t *testing.T) {
+	cases := map[string][]Choice{
+		"single zero":   {{Item: "a"}},
+		"multiple zero": {{Item: "a"}, {Item: "b"}, {Item: "c"}},
+	}
+	for name, choices := range cases {
+		t.Run(name, func(t *testing.T) {
+			chooser, err := NewChooserErr(choices...)
+			if err != ErrAllZeroWeights {
+				t.Fatalf("NewChooserErr() error = %v, want %v", err, ErrAllZeroWeights)
+			}
+			assertAllZero(t, chooser)
+
+			// Zeroing the last nonzero weight must leave the chooser
+			// equally unusable rather than primed to panic.
+			mixed := NewChooser(append([]Choice{{Item: "live", Weight: 5}}, choices...)...)
+			if _, err := mixed.Pick(); err != nil {
+				t.Fatalf("Pick() error = %v before zeroing", err)
+			}
+			mixed.SetWeight("live", 0)
+			assertAllZero(t, mixed)
+
+			seeded, err := NewChooserOpts(choices, WithSeed(1), WithLookupTable(100))
+			if err != ErrAllZeroWeights {
+				t.Fatalf("NewChooserOpts() error = %v, want %v", err, ErrAllZeroWeights)
+			}
+			assertAllZero(t, seeded)
+		})
+	}
+}
+
+// assertAllZero checks that every pick method of chooser reports
+// ErrAllZeroWeights.
+func assertAllZero(t *testing.T, chooser Chooser) {
+	t.Helper()
+	picks := map[string]func() error{
+		"Pick":          func() error { _, err := chooser.Pick(); return err },
+		"PickSource":    func() error { _, err := chooser.PickSource(rand.New(rand.NewSource(1))); return err },
+		"PickWith":      func() error { s := splitMix(1); _, err := chooser.PickWith(&s); return err },
+		"PickIndex":     func() error { _, err := chooser.PickIndex(); return err },
+		"PickN":         func() error { _, err := chooser.PickN(3); return err },
+		"FillPicks":     func() error { return chooser.FillPicks(make([]interface{}, 3)) },
+		"PickSecure":    func() error { _, err := chooser.PickSecure(); return err },
+		"PickFromInt":   func() error { _, err := chooser.PickFromInt(0); return err },
+		"PickByString":  func() error { _, err := chooser.PickByString("key"); return err },
+		"PickWhere":     func() error { _, err := chooser.PickWhere(func(Choice) bool { return true }); return err },
+		"PickExcluding": func() error { _, err := chooser.PickExcluding(); return err },
+	}
+	for name, pick := range picks {
+		if err := pick(); err != ErrAllZeroWeights {
+			t.Errorf("%s() error = %v, want %v", name, err, ErrAllZeroWeights)
+		}
+	}
+	if err := chooser.Err(); err != ErrAllZeroWeights {
+		t.Errorf("Err() = %v, want %v", err, ErrAllZeroWeights)
+	}
+}
+
+func TestNewChooserErr(t *testing.T) {
+	cases := []struct {
+		name    string
+		choices []Choice
+		err     error
+	}{
+		{"valid", []Choice{{Item: 1, Weight: 1}}, nil},
+		{"empty", nil, ErrNoChoices},
+		{"all zero", []Choice{{Item: 1}, {Item: 2}}, ErrAllZeroWeights},
+		{"overflow", []Choice{{Item: 1, Weight: ^uint(0)}, {Item: 2, Weight: 1}}, ErrWeightOverflow},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == ErrWeightOverflow && !is64Bit {
+				t.Skip("weights cannot overflow int64 with a 32-bit uint")
+			}
+			chooser, err := NewChooserErr(tc.choices...)
+			if err != tc.err {
+				t.Fatalf("NewChooserErr() error = %v, want %v", err, tc.err)
+			}
+			if _, err := chooser.Pick(); err != tc.err {
+				t.Errorf("Pick() error = %v, want %v", err, tc.err)
+			}
+
+			defer func() {
+				if r := recover(); r != tc.err {
+					t.Errorf("MustPick() panicked with %v, want %v", r, tc.err)
+				}
+			}()
+			if item := chooser.MustPick(); item != 1 {
+				t.Errorf("MustPick() = %v, want 1", item)
+			}
+		})
+	}
+}
+
+// TestSentinelErrors ensures every error path reports a sentinel that callers
+// can match with errors.Is.
+func TestSentinelErrors(t *testing.T) {
+	empty := NewChooser()
+	zero := NewChooser(Choice{Item: 1})
+	overflow := NewChooser(Choice{Weight: ^uint(0)}, Choice{Weight: 1})
+	for _, tc := range []struct {
+		chooser Chooser
+		want    error
+	}{
+		{empty, ErrNoChoices},
+		{zero, ErrAllZeroWeights},
+		{overflow, ErrWeightOverflow},
+	} {
+		if tc.want == ErrWeightOverflow && !is64Bit {
+			continue // weights cannot overflow int64 with a 32-bit uint
+		}
+		errs := make([]error, 0, 6)
+		_, err := tc.chooser.Pick()
+		errs = append(errs, err)
+		_, err = tc.chooser.PickN(1)
+		errs = append(errs, err)
+		_, err = tc.chooser.PickSecure()
+		errs = append(errs, err)
+		_, err = tc.chooser.PickUniqueN(1)
+		errs = append(errs, err)
+		_, err = tc.chooser.Shuffle()
+		errs = append(errs, err)
+		_, err = NewChooserErr(tc.chooser.data...)
+		errs = append(errs, err)
+		for i, err := range errs {
+			if !errors.Is(err, tc.want) {
+				t.Errorf("case %v, call %d: got %v, want %v", tc.want, i, err, tc.want)
+			}
+		}
+	}
+
+	_, err := NewFloatChooser(FloatChoice{Weight: math.NaN()})
+	if !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("NewFloatChooser(NaN) error = %v, want %v", err, ErrInvalidWeight)
+	}
+	_, err = NewFloatChooser(FloatChoice{Weight: math.MaxFloat64}, FloatChoice{Weight: math.MaxFloat64})
+	if !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("NewFloatChooser(overflow) error = %v, want %v", err, ErrWeightOverflow)
+	}
+	floats, _ := NewFloatChooser()
+	if _, err := floats.Pick(); !errors.Is(err, ErrNoChoices) {
+		t.Errorf("FloatChooser.Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+	zeroFloats, _ := NewFloatChooser(FloatChoice{Item: 1}, FloatChoice{Item: 2})
+	if _, err := zeroFloats.Pick(); !errors.Is(err, ErrAllZeroWeights) {
+		t.Errorf("FloatChooser.Pick() error = %v for zero weights, want %v", err, ErrAllZeroWeights)
+	}
+	if _, err := NewAliasChooser().Pick(); !errors.Is(err, ErrNoChoices) {
+		t.Errorf("AliasChooser.Pick() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewAliasChooser(Choice{Item: 1}, Choice{Item: 2}).Pick(); !errors.Is(err, ErrAllZeroWeights) {
+		t.Errorf("AliasChooser.Pick() error = %v for zero weights, want %v", err, ErrAllZeroWeights)
+	}
+	dynamic, _ := NewDynamicChooser(Choice{Item: 1})
+	if _, err := dynamic.Pick(); !errors.Is(err, ErrAllZeroWeights) {
+		t.Errorf("DynamicChooser.Pick() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	dynamic, _ = NewDynamicChooser(Choice{Item: 1, Weight: 1}, Choice{Item: 2})
+	if err := dynamic.UpdateWeight(1, ^uint(0)); is64Bit && !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("DynamicChooser.UpdateWeight() error = %v, want %v", err, ErrWeightOverflow)
+	}
+}
+
+// TestGoldenPicks pins the exact pick sequence for fixed seeds and inputs, as
+// promised by NewChooserT. If this fails, the selection algorithm has changed
+// in a way that breaks reproducibility for existing users.
+func TestGoldenPicks(t *testing.T) {
+	choices := []ChoiceT[string]{
+		{Item: "a", Weight: 3},
+		{Item: "b", Weight: 1},
+		{Item: "c", Weight: 0},
+		{Item: "d", Weight: 3},
+		{Item: "e", Weight: 10},
+		{Item: "f", Weight: 1},
+	}
+	golden := map[int64][]string{
+		1:  {"d", "e", "e", "e", "e", "d", "d", "e", "a", "d", "e", "d", "e", "e", "a", "e", "e", "e", "e", "e"},
+		42: {"e", "e", "e", "e", "e", "d", "e", "e", "a", "d", "f", "e", "a", "a", "e", "e", "e", "d", "e", "a"},
+	}
+	for seed, want := range golden {
+		chooser, err := NewChooserTOpts(choices, WithInputCopy(), WithSeed(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Equal weights keep their input order.
+		var order []string
+		for _, c := range chooser.data {
+			order = append(order, c.Item)
+		}
+		if want := []string{"c", "b", "f", "a", "d", "e"}; !reflect.DeepEqual(order, want) {
+			t.Errorf("sorted order = %q, want %q", order, want)
+		}
+
+		got := make([]string, len(want))
+		for i := range got {
+			got[i] = chooser.MustPick()
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("seed %d:\ngot  %q\nwant %q", seed, got, want)
+		}
+	}
+}
+
+func TestPickIndex(t *testing.T) {
+	// Each item is its own original index, so PickIndex must agree with Pick
+	// from an identically seeded chooser.
+	weights := make([]uint, 50)
+	choices := make([]Choice, len(weights))
+	for i := range choices {
+		weights[i] = uint(rand.Intn(10))
+		choices[i] = Choice{Item: i, Weight: weights[i]}
+	}
+	chooser, err := NewChooserOpts(choices, WithInputCopy(), WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, _ := NewChooserOpts(choices, WithInputCopy(), WithSeed(1))
+
+	for i := 0; i < 1000; i++ {
+		idx, err := chooser.PickIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item := same.MustPick(); idx != item.(int) {
+			t.Fatalf("PickIndex() = %d, but Pick() returned item %v", idx, item)
+		}
+		if weights[idx] == 0 {
+			t.Fatalf("PickIndex() = %d, which has zero weight", idx)
+		}
+	}
+
+	// Indices follow the original order through mutations.
+	chooser = NewChooser(
+		Choice{Item: "heavy", Weight: 9},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "light", Weight: 1},
+	)
+	chooser.SetWeight("heavy", 0)
+	if idx, _ := chooser.PickIndex(); idx != 2 {
+		t.Errorf("PickIndex() = %d, want 2 (light)", idx)
+	}
+	chooser.Remove("zero")
+	if idx, _ := chooser.PickIndex(); idx != 1 {
+		t.Errorf("PickIndex() = %d, want 1 (light) after Remove", idx)
+	}
+	chooser.SetWeight("light", 0)
+	chooser.Add(Choice{Item: "new", Weight: 1})
+	if idx, _ := chooser.PickIndex(); idx != 2 {
+		t.Errorf("PickIndex() = %d, want 2 (new) after Add", idx)
+	}
+
+	if idx, err := NewChooser().PickIndex(); err != ErrNoChoices || idx != -1 {
+		t.Errorf("PickIndex() = %d, %v; want -1, %v", idx, err, ErrNoChoices)
+	}
+}
+
+// TestNewChooserInputUnchanged ensures NewChooser neither reorders nor
+// retains the caller's slice.
+func TestNewChooserInputUnchanged(t *testing.T) {
+	choices := []Choice{{Item: "c", Weight: 3}, {Item: "a", Weight: 1}, {Item: "b", Weight: 2}}
+	orig := append([]Choice(nil), choices...)
+	chooser := NewChooser(choices...)
+	if !reflect.DeepEqual(choices, orig) {
+		t.Errorf("input modified to %v, want %v", choices, orig)
+	}
+
+	// Reusing the slice with adjusted weights must not affect the first
+	// chooser.
+	choices[0].Weight = 0
+	choices[1].Weight = 100
+	second := NewChooser(choices...)
+	if p, _ := chooser.Probability("c"); p != 0.5 {
+		t.Errorf("first chooser Probability(c) = %v, want 0.5", p)
+	}
+	if p, _ := second.Probability("a"); p != 100.0/102 {
+		t.Errorf("second chooser Probability(a) = %v, want %v", p, 100.0/102)
+	}
+
+	// Borrowing sorts and keeps the caller's slice.
+	borrowed, err := NewChooserOpts(choices, WithBorrowedInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if choices[0].Item != "c" || &borrowed.data[0] != &choices[0] {
+		t.Errorf("WithBorrowedInput did not sort the input in place: %v", choices)
+	}
+}
+
 const BMminChoices = 10
 const BMmaxChoices = 1000000
 
@@ -82,6 +698,26 @@ func BenchmarkNewChooser(b *testing.B) {
 	}
 }
 
+// BenchmarkNewChooserBorrowed measures construction without the defensive
+// copy NewChooser makes of its input, for comparison with BenchmarkNewChooser.
+func BenchmarkNewChooserBorrowed(b *testing.B) {
+	for n := BMminChoices; n <= BMmaxChoices; n *= 10 {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			choices := mockChoices(n)
+			scratch := make([]Choice, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				copy(scratch, choices)
+				b.StartTimer()
+				_, _ = NewChooserOpts(scratch, WithBorrowedInput())
+			}
+		})
+	}
+}
+
 func BenchmarkPick(b *testing.B) {
 	for n := BMminChoices; n <= BMmaxChoices; n *= 10 {
 		b.Run(strconv.Itoa(n), func(b *testing.B) {
@@ -96,6 +732,42 @@ func BenchmarkPick(b *testing.B) {
 	}
 }
 
+func BenchmarkPickT(b *testing.B) {
+	for n := BMminChoices; n <= BMmaxChoices; n *= 10 {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			choices := make([]ChoiceT[string], 0, n)
+			for _, c := range mockChoices(n) {
+				choices = append(choices, ChoiceT[string]{Item: c.Item.(string), Weight: c.Weight})
+			}
+			chooser := NewChooserT(choices...)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				chooser.Pick()
+			}
+		})
+	}
+}
+
+func BenchmarkPickN(b *testing.B) {
+	const n = 10000
+	chooser := NewChooser(mockChoices(1000)...)
+
+	b.Run("Pick", func(b *testing.B) {
+		items := make([]interface{}, n)
+		for i := 0; i < b.N; i++ {
+			for j := range items {
+				items[j], _ = chooser.Pick()
+			}
+		}
+	})
+	b.Run("PickN", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			chooser.PickN(n)
+		}
+	})
+}
+
 // This following is a historic artifact from comparative benchmarking with
 // randutil, however it is not critical to ongoing development.
 
@@ -119,3 +791,147 @@ func BenchmarkPick(b *testing.B) {
 // 		})
 // 	}
 // }
+
+func TestFillPicks(t *testing.T) {
+	chooser := NewChooserWithRand(rand.New(rand.NewSource(1)),
+		Choice{Item: "a", Weight: 1},
+		Choice{Item: "zero", Weight: 0},
+		Choice{Item: "b", Weight: 3},
+	)
+	dst := make([]interface{}, 20000)
+	if err := chooser.FillPicks(dst); err != nil {
+		t.Fatal(err)
+	}
+	shares := make(map[interface{}]float64)
+	for _, item := range dst {
+		shares[item] += 1.0 / float64(len(dst))
+	}
+	assertShares(t, shares, map[interface{}]float64{"a": 0.25, "b": 0.75})
+
+	indices := make([]int, 20000)
+	if err := chooser.FillPickIndices(indices); err != nil {
+		t.Fatal(err)
+	}
+	var counts [3]int
+	for _, i := range indices {
+		counts[i]++
+	}
+	if counts[1] != 0 || math.Abs(float64(counts[2])/20000-0.75) > 0.02 {
+		t.Errorf("FillPickIndices() counts = %v, want about 1:0:3", counts)
+	}
+
+	if err := chooser.FillPicks(nil); err != nil {
+		t.Errorf("FillPicks(nil) error = %v", err)
+	}
+	empty := NewChooser()
+	if err := empty.FillPicks(dst); err != ErrNoChoices {
+		t.Errorf("FillPicks() error = %v, want %v", err, ErrNoChoices)
+	}
+	if err := empty.FillPickIndices(indices[:0]); err != ErrNoChoices {
+		t.Errorf("FillPickIndices() error = %v, want %v", err, ErrNoChoices)
+	}
+}
+
+func BenchmarkFillPickIndices(b *testing.B) {
+	chooser := NewChooser(mockFrequencies(1000)...)
+	dst := make([]int, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		chooser.FillPickIndices(dst)
+	}
+}
+
+func BenchmarkFillPicks(b *testing.B) {
+	chooser := NewChooserT(ChoiceT[int]{Item: 1, Weight: 1}, ChoiceT[int]{Item: 2, Weight: 3})
+	dst := make([]int, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		chooser.FillPicks(dst)
+	}
+}
+
+// searchTotalsReference is the textbook binary search searchTotals replaced,
+// narrowing a range from both ends.
+func searchTotalsReference(totals []uint64, r uint64) int {
+	i, j := 0, len(totals)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if totals[h] < r {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
+func TestSearchTotals(t *testing.T) {
+	if got := searchTotals([]uint64(nil), 1); got != 0 {
+		t.Errorf("searchTotals(nil, 1) = %d, want 0", got)
+	}
+	// Every length up to 70 covers each shape of the halving, with runs of
+	// equal totals standing in for zero weights.
+	for n := 1; n <= 70; n++ {
+		totals := make([]uint64, n)
+		var sum uint64
+		for i := range totals {
+			if i%7 != 3 {
+				sum += uint64(i%5 + 1)
+			}
+			totals[i] = sum
+		}
+		check := func(r uint64) {
+			want := searchTotalsReference(totals, r)
+			if got := searchTotals(totals, r); got != want {
+				t.Fatalf("n=%d: searchTotals(%d) = %d, want %d", n, r, got, want)
+			}
+			if want < n && (totals[want] < r || want > 0 && totals[want-1] >= r) {
+				t.Fatalf("n=%d: %d is not the first total of at least %d", n, want, r)
+			}
+		}
+		check(0)
+		check(1)
+		check(sum)
+		check(sum + 1)
+		for _, total := range totals {
+			check(total - 1)
+			check(total)
+			check(total + 1)
+		}
+	}
+
+	// Totals up to math.MaxUint64.
+	wide := []uint64{1, math.MaxInt64, math.MaxInt64 + 1, math.MaxUint64 - 1, math.MaxUint64}
+	for i, total := range wide {
+		if got := searchTotals(wide, total); got != i {
+			t.Errorf("searchTotals(%d) = %d, want %d", total, got, i)
+		}
+	}
+}
+
+func BenchmarkSearchTotals(b *testing.B) {
+	for _, n := range []int{10, 1000, 100000, 1000000} {
+		chooser := NewChooser(mockFrequencies(n)...)
+		rs := rand.New(rand.NewSource(1))
+		rs2 := make([]uint64, 1<<16)
+		for i := range rs2 {
+			rs2[i] = uint64(rs.Int63n(int64(chooser.max))) + 1
+		}
+		searches := map[string]func(totals []uint64, r uint64) int{
+			"sort.Search": func(totals []uint64, r uint64) int {
+				return sort.Search(len(totals), func(i int) bool { return totals[i] >= r })
+			},
+			"reference": searchTotalsReference,
+			"tuned":     searchTotals[uint64],
+		}
+		for name, search := range searches {
+			b.Run(name+"/"+strconv.Itoa(n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					search(chooser.totals, rs2[i&(len(rs2)-1)])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package weightedrandtest provides helpers for testing code built on the
+// weightedrand package, so that the weightedrand package itself need not
+// import testing.
+package weightedrandtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/yrh79/weightedrand"
+)
+
+// TestingT is the subset of testing.TB used by AssertDistribution. Both
+// *testing.T and *testing.B satisfy it.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// A Row is one item's line in a distribution report.
+type Row struct {
+	Item     interface{}
+	Expected float64 // expected number of picks
+	Observed uint64  // actual number of picks
+}
+
+// ChiSquare makes n picks from chs, drawing from rs, and returns Pearson's
+// chi-squared statistic comparing the observed counts of each item against
+// those expected from the weights, along with the per-item rows it was
+// computed from. Choices holding equal items are combined. An item picked
+// despite an expected count of zero makes the statistic infinite.
+//
+// Items are used as map keys, so this panics if T is an interface type holding
+// an item that is not comparable.
+func ChiSquare[T any](chs weightedrand.ChooserT[T], rs *rand.Rand, n int) (float64, []Row, error) {
+	var rows []Row
+	byItem := make(map[interface{}]int)
+	total := float64(chs.TotalWeight())
+	for _, c := range chs.Choices() {
+		i, ok := byItem[c.Item]
+		if !ok {
+			i = len(rows)
+			byItem[c.Item] = i
+			rows = append(rows, Row{Item: c.Item})
+		}
+		rows[i].Expected += float64(n) * float64(c.Weight) / total
+	}
+	for k := 0; k < n; k++ {
+		item, err := chs.PickSource(rs)
+		if err != nil {
+			return 0, nil, err
+		}
+		rows[byItem[item]].Observed++
+	}
+
+	var chi2 float64
+	for _, r := range rows {
+		d := float64(r.Observed) - r.Expected
+		switch {
+		case r.Expected > 0:
+			chi2 += d * d / r.Expected
+		case r.Observed > 0:
+			chi2 = math.Inf(1)
+		}
+	}
+	return chi2, rows, nil
+}
+
+// AssertDistribution makes n picks from chs, drawing from rs, and fails t with
+// a table of expected and observed counts if the chi-squared statistic
+// exceeds maxChiSquare, or if picking fails. A seeded rs makes the outcome the
+// same on every run. It reports whether the assertion passed.
+//
+// Choose maxChiSquare from a table of the chi-squared distribution with one
+// fewer degrees of freedom than there are distinct items of nonzero weight: for
+// instance 16.27 for 4 items, which a fair chooser exceeds one time in a
+// thousand. n should be large enough that every expected count is at least 5.
+func AssertDistribution[T any](t TestingT, chs weightedrand.ChooserT[T], rs *rand.Rand, n int, maxChiSquare float64) bool {
+	t.Helper()
+	chi2, rows, err := ChiSquare(chs, rs, n)
+	if err != nil {
+		t.Errorf("weightedrandtest: picking failed: %v", err)
+		return false
+	}
+	if chi2 <= maxChiSquare {
+		return true
+	}
+	t.Errorf("weightedrandtest: chi-squared %.2f over %d picks exceeds %.2f\n%s", chi2, n, maxChiSquare, Table(rows))
+	return false
+}
+
+// Table formats rows as a table of items with their expected and observed
+// counts and their contribution to the chi-squared statistic.
+func Table(rows []Row) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-20s %12s %12s %10s\n", "item", "expected", "observed", "chi2")
+	for _, r := range rows {
+		d := float64(r.Observed) - r.Expected
+		contrib := math.Inf(1)
+		if r.Expected > 0 {
+			contrib = d * d / r.Expected
+		} else if r.Observed == 0 {
+			contrib = 0
+		}
+		fmt.Fprintf(&b, "%-20v %12.1f %12d %10.2f\n", r.Item, r.Expected, r.Observed, contrib)
+	}
+	return b.String()
+}
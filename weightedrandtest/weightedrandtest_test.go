@@ -0,0 +1,85 @@
+package weightedrandtest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/yrh79/weightedrand"
+)
+
+// recorder is a TestingT that records failures instead of reporting them.
+type recorder struct {
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// stuckSource is a rigged rand.Source that always returns the same value.
+type stuckSource int64
+
+func (s stuckSource) Int63() int64 { return int64(s) }
+func (s stuckSource) Seed(int64)   {}
+
+func fruits() weightedrand.ChooserT[string] {
+	return weightedrand.NewChooserT(
+		weightedrand.ChoiceT[string]{Item: "apple", Weight: 1},
+		weightedrand.ChoiceT[string]{Item: "banana", Weight: 2},
+		weightedrand.ChoiceT[string]{Item: "cherry", Weight: 3},
+		weightedrand.ChoiceT[string]{Item: "date", Weight: 4},
+		weightedrand.ChoiceT[string]{Item: "never", Weight: 0},
+	)
+}
+
+func TestAssertDistribution(t *testing.T) {
+	// A fair chooser passes, and does so on every run with a seeded source.
+	if !AssertDistribution(t, fruits(), rand.New(rand.NewSource(1)), 100000, 16.27) {
+		t.Error("AssertDistribution() failed for a correct chooser")
+	}
+
+	var r recorder
+	if AssertDistribution(&r, fruits(), rand.New(stuckSource(0)), 10000, 16.27) {
+		t.Fatal("AssertDistribution() passed for a rigged source")
+	}
+	if len(r.failures) != 1 {
+		t.Fatalf("got %d failures, want 1", len(r.failures))
+	}
+	for _, want := range []string{"chi-squared", "apple", "banana", "cherry", "date", "expected", "observed"} {
+		if !strings.Contains(r.failures[0], want) {
+			t.Errorf("failure message does not mention %q:\n%s", want, r.failures[0])
+		}
+	}
+
+	r = recorder{}
+	if AssertDistribution(&r, weightedrand.NewChooser(), nil, 10, 1) || len(r.failures) != 1 {
+		t.Errorf("AssertDistribution() on an empty chooser recorded %v, want one failure", r.failures)
+	}
+}
+
+func TestChiSquare(t *testing.T) {
+	chs := weightedrand.NewChooser(
+		weightedrand.Choice{Item: "a", Weight: 1},
+		weightedrand.Choice{Item: "b", Weight: 2},
+		weightedrand.Choice{Item: "a", Weight: 1}, // combined with the first
+	)
+	chi2, rows, err := ChiSquare(chs, rand.New(rand.NewSource(1)), 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %v", len(rows), rows)
+	}
+	for _, r := range rows {
+		if r.Expected != 5000 {
+			t.Errorf("%v expected %v times, want 5000", r.Item, r.Expected)
+		}
+	}
+	if chi2 > 6.63 { // p = 0.01 with one degree of freedom
+		t.Errorf("chi-squared = %v, want at most 6.63\n%s", chi2, Table(rows))
+	}
+}
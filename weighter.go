@@ -0,0 +1,29 @@
+package weightedrand
+
+// A Weighter is an item that knows its own weight.
+type Weighter interface {
+	Weight() uint
+}
+
+// NewChooserTFromWeighters initializes a new ChooserT holding each of items,
+// weighted by its Weight method. The weights are read once, during
+// construction: later changes to what an item reports are not seen by the
+// ChooserT until it is rebuilt. An error is returned for any set of choices
+// NewChooserTErr would reject.
+func NewChooserTFromWeighters[T Weighter](items ...T) (ChooserT[T], error) {
+	cs := make([]ChoiceT[T], len(items))
+	for i, item := range items {
+		cs[i] = ChoiceT[T]{Item: item, Weight: item.Weight()}
+	}
+	return NewChooserTOpts(cs, WithBorrowedInput())
+}
+
+// NewChooserFromWeighters initializes a new Chooser holding each of items,
+// weighted by its Weight method. See NewChooserTFromWeighters.
+func NewChooserFromWeighters(items ...Weighter) (Chooser, error) {
+	cs := make([]Choice, len(items))
+	for i, item := range items {
+		cs[i] = Choice{Item: item, Weight: item.Weight()}
+	}
+	return NewChooserOpts(cs, WithBorrowedInput())
+}
@@ -0,0 +1,88 @@
+package weightedrand
+
+import "testing"
+
+// server is a Weighter whose weight is its capacity.
+type server struct {
+	name     string
+	capacity uint
+}
+
+func (s server) Weight() uint { return s.capacity }
+
+// job is a Weighter implemented on a pointer, whose weight is its priority.
+type job struct {
+	id       int
+	priority uint
+}
+
+func (j *job) Weight() uint { return j.priority }
+
+func TestNewChooserFromWeighters(t *testing.T) {
+	big := &job{id: 1, priority: 3}
+	chooser, err := NewChooserFromWeighters(server{"a", 1}, big, server{"idle", 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := make(map[interface{}]float64)
+	const n = 40000
+	for i := 0; i < n; i++ {
+		shares[chooser.MustPick()] += 1.0 / n
+	}
+	if shares[big] < 0.73 || shares[big] > 0.77 {
+		t.Errorf("job picked %.3f of the time, want 0.75", shares[big])
+	}
+	if shares[server{"a", 1}] < 0.23 || shares[server{"a", 1}] > 0.27 {
+		t.Errorf("server picked %.3f of the time, want 0.25", shares[server{"a", 1}])
+	}
+	if len(shares) != 2 {
+		t.Errorf("picked %v, want only the two nonzero weights", shares)
+	}
+
+	// Picks return the original pointer, and weights are captured once.
+	big.priority = 0
+	for i := 0; i < 100; i++ {
+		if item := chooser.MustPick(); item != big && item != (server{"a", 1}) {
+			t.Fatalf("Pick() = %v, want an original item", item)
+		}
+	}
+	if got := chooser.TotalWeight(); got != 4 {
+		t.Errorf("TotalWeight() = %d after changing an item, want 4", got)
+	}
+}
+
+func TestNewChooserTFromWeighters(t *testing.T) {
+	jobs := []*job{{id: 1, priority: 2}, {id: 2, priority: 0}, {id: 3, priority: 5}}
+	chooser, err := NewChooserTFromWeighters(jobs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if j := chooser.MustPick(); j != jobs[0] && j != jobs[2] {
+			t.Fatalf("Pick() = %+v, want job 1 or 3", j)
+		}
+	}
+	if _, ok := chooser.Probability(jobs[2]); !ok {
+		t.Error("Probability() did not find job 3")
+	}
+
+	servers, err := NewChooserTFromWeighters(server{"a", 2}, server{"b", 2})
+	if err != nil || servers.MustPick().capacity != 2 {
+		t.Errorf("NewChooserTFromWeighters() = %v, %v", servers.Choices(), err)
+	}
+}
+
+func TestNewChooserFromWeightersErrors(t *testing.T) {
+	if _, err := NewChooserFromWeighters(); err != ErrNoChoices {
+		t.Errorf("NewChooserFromWeighters() error = %v, want %v", err, ErrNoChoices)
+	}
+	if _, err := NewChooserTFromWeighters(server{"a", 0}, server{"b", 0}); err != ErrAllZeroWeights {
+		t.Errorf("NewChooserTFromWeighters() error = %v, want %v", err, ErrAllZeroWeights)
+	}
+	if !is64Bit {
+		return
+	}
+	if _, err := NewChooserFromWeighters(server{"a", ^uint(0)}, &job{priority: 1}); err != ErrWeightOverflow {
+		t.Errorf("NewChooserFromWeighters() error = %v, want %v", err, ErrWeightOverflow)
+	}
+}